@@ -0,0 +1,32 @@
+-- Migration 004: Persisted Refresh Tokens with Rotation
+-- Purpose: Store opaque refresh tokens (hashed) so they can be rotated and revoked
+-- Version: 004
+-- Date: 2025-10-20
+
+-- Base table from the reference schema in docs/technical/database-schema.md (created here for databases that never had it)
+CREATE TABLE IF NOT EXISTS auth_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES team_members(id) ON DELETE CASCADE,
+    token_hash VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Rotation tracking: every token issued from one login shares a family_id.
+-- A rotated token points at its successor via replaced_by; presenting it again
+-- is treated as theft and revokes the whole family.
+ALTER TABLE auth_tokens ADD COLUMN IF NOT EXISTS family_id UUID;
+ALTER TABLE auth_tokens ADD COLUMN IF NOT EXISTS replaced_by UUID REFERENCES auth_tokens(id);
+ALTER TABLE auth_tokens ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP;
+
+UPDATE auth_tokens SET family_id = id WHERE family_id IS NULL;
+ALTER TABLE auth_tokens ALTER COLUMN family_id SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_auth_tokens_hash ON auth_tokens(token_hash);
+CREATE INDEX IF NOT EXISTS idx_auth_tokens_family ON auth_tokens(family_id);
+CREATE INDEX IF NOT EXISTS idx_auth_tokens_user ON auth_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_auth_tokens_expires ON auth_tokens(expires_at);
+
+COMMENT ON COLUMN auth_tokens.token_hash IS 'SHA-256 hex digest of the opaque refresh token; the token itself is never stored';
+COMMENT ON COLUMN auth_tokens.family_id IS 'Shared by all tokens rotated from the same login';
+COMMENT ON COLUMN auth_tokens.replaced_by IS 'Successor token after rotation; reuse of a replaced token revokes the family';
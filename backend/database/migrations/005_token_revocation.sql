@@ -0,0 +1,22 @@
+-- Migration 005: Access Token Revocation
+-- Purpose: Let logout invalidate JWTs before they expire (per-token jti list and per-user generation)
+-- Version: 005
+-- Date: 2025-10-20
+
+-- Revoked access tokens, kept only until the token would have expired anyway
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES team_members(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_revoked_at ON revoked_tokens(revoked_at);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires ON revoked_tokens(expires_at);
+
+-- "Log out all sessions": tokens carry the generation they were issued under,
+-- and are rejected once the member's generation moves past it
+ALTER TABLE team_members ADD COLUMN IF NOT EXISTS token_generation INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON TABLE revoked_tokens IS 'JWT ids invalidated by logout; rows are purged after expires_at';
+COMMENT ON COLUMN team_members.token_generation IS 'Incremented by logout-all; access tokens with an older generation are rejected';
@@ -0,0 +1,37 @@
+-- Migration 006: Team Invitations
+-- Purpose: Persist invitation tokens so invited members can accept and create their account
+-- Version: 006
+-- Date: 2025-10-21
+
+CREATE TABLE IF NOT EXISTS team_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    role VARCHAR(50) NOT NULL CHECK (role IN (
+        'customer_success_manager',
+        'support_manager',
+        'account_manager',
+        'sales_manager',
+        'legal_compliance',
+        'operations_manager'
+    )),
+    token_hash VARCHAR(255) NOT NULL,
+    invited_by UUID NOT NULL REFERENCES team_members(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'revoked', 'expired')),
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    accepted_member_id UUID REFERENCES team_members(id) ON DELETE SET NULL,
+    resent_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_team_invitations_token ON team_invitations(token_hash);
+CREATE INDEX IF NOT EXISTS idx_team_invitations_team ON team_invitations(team_id, status);
+
+-- At most one open invitation per email per team; resend rotates the token instead
+CREATE UNIQUE INDEX IF NOT EXISTS idx_team_invitations_pending_email
+    ON team_invitations(team_id, LOWER(email)) WHERE status = 'pending';
+
+COMMENT ON COLUMN team_invitations.token_hash IS 'SHA-256 hex digest of the invitation token; the token is only returned to the inviter';
@@ -0,0 +1,19 @@
+-- Migration 007: Team Ownership
+-- Purpose: Track the team owner so ownership can be transferred and the owner cannot be removed
+-- Version: 007
+-- Date: 2025-10-21
+
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS owner_id UUID REFERENCES team_members(id) ON DELETE SET NULL;
+
+-- Existing teams: the registering member (earliest created) owns the team
+UPDATE teams t SET owner_id = (
+    SELECT tm.id FROM team_members tm
+    WHERE tm.team_id = t.id
+    ORDER BY tm.created_at ASC
+    LIMIT 1
+)
+WHERE t.owner_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_team_members_team_active ON team_members(team_id, is_active);
+
+COMMENT ON COLUMN teams.owner_id IS 'Member who owns the team; changed only via ownership transfer';
@@ -0,0 +1,21 @@
+-- Migration 008: Decision State Machine
+-- Purpose: Record the selected option and every lifecycle transition with its actor
+-- Version: 008
+-- Date: 2025-10-22
+
+ALTER TABLE customer_decisions ADD COLUMN IF NOT EXISTS selected_option_id UUID REFERENCES response_options(id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS decision_transitions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    decision_id UUID NOT NULL REFERENCES customer_decisions(id) ON DELETE CASCADE,
+    from_state VARCHAR(20) NOT NULL,
+    to_state VARCHAR(20) NOT NULL,
+    actor_id UUID NOT NULL REFERENCES team_members(id),
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_decision_transitions_decision ON decision_transitions(decision_id, created_at);
+
+COMMENT ON TABLE decision_transitions IS 'Append-only history of decision lifecycle transitions';
+COMMENT ON COLUMN customer_decisions.selected_option_id IS 'Option chosen by the team; required before drafting and resolution';
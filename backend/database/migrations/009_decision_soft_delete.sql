@@ -0,0 +1,15 @@
+-- Migration 009: Decision Soft Delete
+-- Purpose: Hide deleted decisions until a retention window passes so they can be restored
+-- Version: 009
+-- Date: 2025-10-22
+
+ALTER TABLE customer_decisions ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE customer_decisions ADD COLUMN IF NOT EXISTS deleted_by UUID REFERENCES team_members(id) ON DELETE SET NULL;
+
+-- Live decisions are what almost every query reads
+CREATE INDEX IF NOT EXISTS idx_customer_decisions_team_live
+    ON customer_decisions(team_id, created_at DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_customer_decisions_deleted
+    ON customer_decisions(deleted_at) WHERE deleted_at IS NOT NULL;
+
+COMMENT ON COLUMN customer_decisions.deleted_at IS 'Soft delete time; rows are purged after DECISION_RETENTION_DAYS';
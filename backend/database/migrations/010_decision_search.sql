@@ -0,0 +1,27 @@
+-- Migration 010: Decision List Filtering and Full-Text Search
+-- Purpose: Index the decision list filters, keyset sort orders and full-text search document
+-- Version: 010
+-- Date: 2025-10-23
+
+-- Full-text search over title, customer name, description and relationship history.
+-- The expression must match database.DecisionSearchVector exactly for the planner to use it.
+CREATE INDEX IF NOT EXISTS idx_customer_decisions_search ON customer_decisions USING GIN (
+    (setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+     setweight(to_tsvector('english', coalesce(customer_name, '')), 'A') ||
+     setweight(to_tsvector('english', coalesce(description, '')), 'B') ||
+     setweight(to_tsvector('english', coalesce(relationship_history, '')), 'C'))
+) WHERE deleted_at IS NULL;
+
+-- Keyset pagination: (sort column, id) per team
+CREATE INDEX IF NOT EXISTS idx_customer_decisions_team_created_id
+    ON customer_decisions(team_id, created_at DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_customer_decisions_team_updated_id
+    ON customer_decisions(team_id, updated_at DESC, id DESC) WHERE deleted_at IS NULL;
+
+-- Common equality filters
+CREATE INDEX IF NOT EXISTS idx_customer_decisions_team_type
+    ON customer_decisions(team_id, decision_type) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_customer_decisions_team_tier
+    ON customer_decisions(team_id, customer_tier) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_customer_decisions_created_by
+    ON customer_decisions(created_by) WHERE deleted_at IS NULL;
@@ -0,0 +1,53 @@
+-- Migration 011: Append-Only Audit Trail
+-- Purpose: Record every mutating action with request context in a tamper-evident hash chain
+-- Version: 011
+-- Date: 2025-10-23
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    decision_id UUID,
+    user_id UUID,
+    action VARCHAR(100) NOT NULL,
+    details JSONB,
+    ip_address INET,
+    user_agent TEXT,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Audit history must outlive the decisions and members it mentions, so the original
+-- foreign keys (which would block the soft-delete purge) are dropped
+ALTER TABLE audit_logs DROP CONSTRAINT IF EXISTS audit_logs_decision_id_fkey;
+ALTER TABLE audit_logs DROP CONSTRAINT IF EXISTS audit_logs_user_id_fkey;
+
+-- Each team has its own chain; entries without a team (e.g. failed logins) share the NULL chain
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS team_id UUID;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS sequence BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS request_id VARCHAR(100);
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS prev_hash VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS hash VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_logs_chain
+    ON audit_logs(COALESCE(team_id, '00000000-0000-0000-0000-000000000000'::uuid), sequence);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_decision ON audit_logs(decision_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user ON audit_logs(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_team_action ON audit_logs(team_id, action, created_at);
+
+-- Rows can only be inserted
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_logs_no_modify ON audit_logs;
+CREATE TRIGGER audit_logs_no_modify
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+
+DROP TRIGGER IF EXISTS audit_logs_no_truncate ON audit_logs;
+CREATE TRIGGER audit_logs_no_truncate
+    BEFORE TRUNCATE ON audit_logs
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only();
+
+COMMENT ON TABLE audit_logs IS 'Append-only audit trail; hash = sha256 of the entry and prev_hash, chained per team';
+COMMENT ON COLUMN audit_logs.sequence IS 'Position in the team chain, starting at 1';
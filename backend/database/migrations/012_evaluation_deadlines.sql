@@ -0,0 +1,36 @@
+-- Migration 012: Evaluation Deadlines
+-- Purpose: Close evaluation automatically on a deadline or quorum, freeze the results and remind pending evaluators
+-- Version: 012
+-- Date: 2025-10-24
+
+ALTER TABLE customer_decisions ADD COLUMN IF NOT EXISTS evaluation_deadline TIMESTAMP;
+ALTER TABLE customer_decisions ADD COLUMN IF NOT EXISTS evaluation_closed_at TIMESTAMP;
+
+-- The scheduler only looks at decisions with evaluation open
+CREATE INDEX IF NOT EXISTS idx_customer_decisions_evaluation_open
+    ON customer_decisions(evaluation_deadline)
+    WHERE status = 'evaluating' AND evaluation_closed_at IS NULL AND deleted_at IS NULL;
+
+-- Results as they stood when evaluation closed; later edits to scores do not change them
+CREATE TABLE IF NOT EXISTS evaluation_snapshots (
+    decision_id UUID PRIMARY KEY REFERENCES customer_decisions(id) ON DELETE CASCADE,
+    results JSONB NOT NULL,
+    close_reason VARCHAR(20) NOT NULL CHECK (close_reason IN ('deadline', 'quorum', 'manual')),
+    closed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- One reminder per member per evaluation round
+CREATE TABLE IF NOT EXISTS evaluation_reminders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    decision_id UUID NOT NULL REFERENCES customer_decisions(id) ON DELETE CASCADE,
+    member_id UUID NOT NULL REFERENCES team_members(id) ON DELETE CASCADE,
+    deadline TIMESTAMP NOT NULL,
+    sent_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (decision_id, member_id, deadline)
+);
+
+-- Transitions made by the scheduler have no human actor
+ALTER TABLE decision_transitions ALTER COLUMN actor_id DROP NOT NULL;
+
+COMMENT ON COLUMN customer_decisions.evaluation_deadline IS 'Set on entering evaluation: now + EVALUATION_TIMEOUT_HOURS unless given explicitly';
+COMMENT ON TABLE evaluation_snapshots IS 'Frozen evaluation results, written when evaluation closes';
@@ -0,0 +1,10 @@
+-- Migration 013: Evaluation Upsert
+-- Purpose: Track when a ballot cell was last changed now that evaluations are upserted per criterion
+-- Version: 013
+-- Date: 2025-10-25
+
+-- Ballots are saved cell by cell on (decision_id, evaluator_id, option_id, criteria_id);
+-- the unique constraint from migration 003 is the upsert key
+ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP;
+
+COMMENT ON COLUMN evaluations.updated_at IS 'Set when a saved score is changed; NULL if never changed since first saved';
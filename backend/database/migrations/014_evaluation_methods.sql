@@ -0,0 +1,18 @@
+-- Migration 014: Evaluation Methods
+-- Purpose: Let each decision choose how options are ranked and how team consensus is measured
+-- Version: 014
+-- Date: 2025-10-26
+
+ALTER TABLE customer_decisions ADD COLUMN IF NOT EXISTS aggregation_method VARCHAR(30) NOT NULL DEFAULT 'weighted_mean';
+ALTER TABLE customer_decisions ADD COLUMN IF NOT EXISTS consensus_method VARCHAR(30) NOT NULL DEFAULT 'variance';
+
+ALTER TABLE customer_decisions DROP CONSTRAINT IF EXISTS customer_decisions_aggregation_method_check;
+ALTER TABLE customer_decisions ADD CONSTRAINT customer_decisions_aggregation_method_check
+    CHECK (aggregation_method IN ('weighted_mean', 'confidence_weighted', 'borda', 'median', 'trimmed_mean', 'topsis'));
+
+ALTER TABLE customer_decisions DROP CONSTRAINT IF EXISTS customer_decisions_consensus_method_check;
+ALTER TABLE customer_decisions ADD CONSTRAINT customer_decisions_consensus_method_check
+    CHECK (consensus_method IN ('variance', 'kendall_w', 'krippendorff_alpha'));
+
+COMMENT ON COLUMN customer_decisions.aggregation_method IS 'Ranks options in GET /decisions/:id/results; see internal/evaluation';
+COMMENT ON COLUMN customer_decisions.consensus_method IS 'Measures team_consensus in GET /decisions/:id/results';
@@ -0,0 +1,9 @@
+-- Migration 015: Stakeholder Weighting
+-- Purpose: Optionally weight evaluators by role (seeded from AI stakeholder recommendations) and escalation authority
+-- Version: 015
+-- Date: 2025-10-27
+
+-- NULL means every evaluator counts equally
+ALTER TABLE customer_decisions ADD COLUMN IF NOT EXISTS stakeholder_weighting JSONB;
+
+COMMENT ON COLUMN customer_decisions.stakeholder_weighting IS 'Role weights, default weight and escalation authority flag applied to evaluators in GET /decisions/:id/results';
@@ -0,0 +1,13 @@
+-- Migration 016: Anonymity Threshold
+-- Purpose: Per-team minimum number of evaluators before per-option score distributions and comments are shown
+-- Version: 016
+-- Date: 2025-10-28
+
+-- 1 shows everything; small teams should keep the default so members cannot work out each other's scores
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS min_anonymous_evaluators INTEGER NOT NULL DEFAULT 3;
+
+ALTER TABLE teams DROP CONSTRAINT IF EXISTS teams_min_anonymous_evaluators_check;
+ALTER TABLE teams ADD CONSTRAINT teams_min_anonymous_evaluators_check
+    CHECK (min_anonymous_evaluators BETWEEN 1 AND 20);
+
+COMMENT ON COLUMN teams.min_anonymous_evaluators IS 'k-anonymity threshold: evaluation distributions and comments are withheld until this many members have evaluated';
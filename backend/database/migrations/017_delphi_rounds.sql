@@ -0,0 +1,45 @@
+-- Migration 017: Delphi Rounds
+-- Purpose: Multi-round Delphi evaluation: members re-score after seeing each round's anonymous results,
+--          until team consensus crosses a threshold or the round limit is reached
+-- Version: 017
+-- Date: 2025-10-29
+
+ALTER TABLE customer_decisions ADD COLUMN IF NOT EXISTS evaluation_mode VARCHAR(20) NOT NULL DEFAULT 'single';
+ALTER TABLE customer_decisions ADD COLUMN IF NOT EXISTS delphi_round INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE customer_decisions ADD COLUMN IF NOT EXISTS delphi_max_rounds INTEGER NOT NULL DEFAULT 3;
+ALTER TABLE customer_decisions ADD COLUMN IF NOT EXISTS delphi_consensus_threshold DOUBLE PRECISION NOT NULL DEFAULT 0.8;
+
+ALTER TABLE customer_decisions DROP CONSTRAINT IF EXISTS customer_decisions_evaluation_mode_check;
+ALTER TABLE customer_decisions ADD CONSTRAINT customer_decisions_evaluation_mode_check
+    CHECK (evaluation_mode IN ('single', 'delphi'));
+
+ALTER TABLE customer_decisions DROP CONSTRAINT IF EXISTS customer_decisions_delphi_check;
+ALTER TABLE customer_decisions ADD CONSTRAINT customer_decisions_delphi_check
+    CHECK (delphi_round >= 1 AND delphi_max_rounds BETWEEN 2 AND 10
+        AND delphi_consensus_threshold > 0 AND delphi_consensus_threshold <= 1);
+
+-- The round a ballot cell was last scored in; cells not re-scored carry over to the next round
+ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS round_number INTEGER NOT NULL DEFAULT 1;
+
+-- Results and comments of each closed round, shown to the team as feedback during the next one
+CREATE TABLE IF NOT EXISTS delphi_rounds (
+    decision_id UUID NOT NULL REFERENCES customer_decisions(id) ON DELETE CASCADE,
+    round_number INTEGER NOT NULL,
+    results JSONB NOT NULL,
+    comments JSONB NOT NULL DEFAULT '[]',
+    team_consensus DOUBLE PRECISION NOT NULL,
+    evaluators INTEGER NOT NULL,
+    close_reason VARCHAR(20) NOT NULL CHECK (close_reason IN ('deadline', 'quorum', 'manual')),
+    stop_reason VARCHAR(20) CHECK (stop_reason IN ('consensus', 'max_rounds')),
+    closed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (decision_id, round_number)
+);
+
+-- A Delphi evaluation closes when consensus is reached or the rounds run out
+ALTER TABLE evaluation_snapshots DROP CONSTRAINT IF EXISTS evaluation_snapshots_close_reason_check;
+ALTER TABLE evaluation_snapshots ADD CONSTRAINT evaluation_snapshots_close_reason_check
+    CHECK (close_reason IN ('deadline', 'quorum', 'manual', 'consensus', 'max_rounds'));
+
+COMMENT ON COLUMN customer_decisions.evaluation_mode IS 'single: one ballot per member; delphi: numbered rounds with anonymous feedback in between';
+COMMENT ON COLUMN customer_decisions.delphi_consensus_threshold IS 'Delphi stops once team_consensus (per consensus_method) reaches this value';
+COMMENT ON TABLE delphi_rounds IS 'One row per closed Delphi round; tracks how team consensus converged';
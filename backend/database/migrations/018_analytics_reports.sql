@@ -0,0 +1,31 @@
+-- Migration 018: Analytics Reports
+-- Purpose: Weekly or monthly analytics summaries emailed to a team, kept once generated
+-- Version: 018
+-- Date: 2025-10-30
+
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS report_frequency VARCHAR(10) NOT NULL DEFAULT 'none';
+
+ALTER TABLE teams DROP CONSTRAINT IF EXISTS teams_report_frequency_check;
+ALTER TABLE teams ADD CONSTRAINT teams_report_frequency_check
+    CHECK (report_frequency IN ('none', 'weekly', 'monthly'));
+
+-- One report per team and period; sent_at stays NULL until delivery succeeds, so failed sends are retried.
+-- An instance claims a report (claimed_at) before generating and sending it, so two never send the same one.
+CREATE TABLE IF NOT EXISTS analytics_reports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    frequency VARCHAR(10) NOT NULL CHECK (frequency IN ('weekly', 'monthly')),
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    markdown TEXT NOT NULL DEFAULT '',
+    html TEXT NOT NULL DEFAULT '',
+    recipients INTEGER NOT NULL DEFAULT 0,
+    generated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    claimed_at TIMESTAMP,
+    sent_at TIMESTAMP,
+    UNIQUE (team_id, frequency, period_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_analytics_reports_team ON analytics_reports(team_id, period_start DESC);
+
+COMMENT ON COLUMN teams.report_frequency IS 'none, weekly or monthly analytics report emailed to members who can view analytics';
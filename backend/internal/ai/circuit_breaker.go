@@ -0,0 +1,127 @@
+package ai
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a provider circuit breaker
+type CircuitState string
+
+// Circuit breaker states
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// CircuitBreaker stops calling a provider after repeated failures and probes it again after a cooldown
+type CircuitBreaker struct {
+	mu               sync.Mutex
+	failureThreshold int
+	cooldown         time.Duration
+	state            CircuitState
+	failures         int
+	openedAt         time.Time
+	probeInFlight    bool
+	now              func() time.Time
+}
+
+// NewCircuitBreaker creates a closed circuit breaker that opens after failureThreshold consecutive failures
+func NewCircuitBreaker(failureThreshold int, cooldown time.Duration) *CircuitBreaker {
+	if failureThreshold <= 0 {
+		failureThreshold = 3
+	}
+	if cooldown <= 0 {
+		cooldown = 60 * time.Second
+	}
+
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+		state:            CircuitClosed,
+		now:              time.Now,
+	}
+}
+
+// Allow reports whether a call may be attempted; once the cooldown expires a single half-open probe is let through
+func (cb *CircuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitOpen:
+		if cb.now().Sub(cb.openedAt) < cb.cooldown {
+			return false
+		}
+		cb.state = CircuitHalfOpen
+		cb.probeInFlight = true
+		return true
+	case CircuitHalfOpen:
+		if cb.probeInFlight {
+			return false
+		}
+		cb.probeInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+// RecordSuccess closes the circuit and resets the failure count
+func (cb *CircuitBreaker) RecordSuccess() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.state = CircuitClosed
+	cb.failures = 0
+	cb.probeInFlight = false
+}
+
+// RecordFailure counts a failed call, opening the circuit at the threshold or when a half-open probe fails
+func (cb *CircuitBreaker) RecordFailure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures++
+	cb.probeInFlight = false
+
+	if cb.state == CircuitHalfOpen || cb.failures >= cb.failureThreshold {
+		cb.state = CircuitOpen
+		cb.openedAt = cb.now()
+	}
+}
+
+// Release gives back a half-open probe without recording an outcome (e.g. the caller cancelled)
+func (cb *CircuitBreaker) Release() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.probeInFlight = false
+}
+
+// State returns the current circuit state
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.state
+}
+
+// Stats returns current circuit breaker statistics
+func (cb *CircuitBreaker) Stats() map[string]interface{} {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	stats := map[string]interface{}{
+		"state":             string(cb.state),
+		"failures":          cb.failures,
+		"failure_threshold": cb.failureThreshold,
+		"cooldown_seconds":  cb.cooldown.Seconds(),
+	}
+	if cb.state == CircuitOpen {
+		stats["retry_in_seconds"] = (cb.cooldown - cb.now().Sub(cb.openedAt)).Seconds()
+	}
+
+	return stats
+}
@@ -1,6 +1,7 @@
 package ai
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -51,12 +52,18 @@ func NewDeepSeekClient(config DeepSeekConfig) *DeepSeekClient {
 	}
 }
 
+// Name returns the provider registry name
+func (c *DeepSeekClient) Name() string {
+	return ProviderDeepSeek
+}
+
 // DeepSeekRequest represents a request to DeepSeek API
 type DeepSeekRequest struct {
 	Model       string    `json:"model"`
 	Messages    []Message `json:"messages"`
 	Temperature float64   `json:"temperature,omitempty"`
 	MaxTokens   int       `json:"max_tokens,omitempty"`
+	Stream      bool      `json:"stream,omitempty"`
 }
 
 // Message represents a chat message
@@ -82,6 +89,14 @@ type Choice struct {
 	FinishReason string  `json:"finish_reason"`
 }
 
+// DeepSeekStreamChunk represents one server-sent event of a streamed chat completion
+type DeepSeekStreamChunk struct {
+	Choices []struct {
+		Delta        Message `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+}
+
 // Usage represents token usage
 type Usage struct {
 	PromptTokens     int `json:"prompt_tokens"`
@@ -287,6 +302,79 @@ func (c *DeepSeekClient) chat(ctx context.Context, prompt string, model string,
 	return apiResp.Choices[0].Message.Content, nil
 }
 
+// chatStream sends a streaming chat completion request and calls onChunk with each content delta
+func (c *DeepSeekClient) chatStream(ctx context.Context, prompt string, model string, maxTokens int, onChunk func(string) error) error {
+	reqBody := DeepSeekRequest{
+		Model: model,
+		Messages: []Message{
+			{
+				Role:    "user",
+				Content: prompt,
+			},
+		},
+		Temperature: 0.7,
+		MaxTokens:   maxTokens,
+		Stream:      true,
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	// The client timeout would cut long streams off mid-body; the request context bounds the stream instead
+	streamClient := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue // blank separators and keep-alive comments
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			return nil
+		}
+
+		var chunk DeepSeekStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+		if err := onChunk(chunk.Choices[0].Delta.Content); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	return nil
+}
+
 // Helper functions
 
 func safeFloat(f *float64) float64 {
@@ -335,6 +423,8 @@ type ResponseDraft struct {
 	Tone                        string   `json:"tone"`
 	EstimatedSatisfactionImpact string   `json:"estimated_satisfaction_impact"`
 	FollowUpRecommendations     []string `json:"follow_up_recommendations"`
+	Provider                    string   `json:"provider,omitempty"`
+	Model                       string   `json:"model,omitempty"` // model that wrote the draft, set by the provider
 }
 
 // GenerateResponseDraft creates an AI-powered customer response draft
@@ -441,6 +531,7 @@ Respond in JSON format:
 			return nil, fmt.Errorf("failed to parse draft: %w", err)
 		}
 	}
+	draft.Model = "deepseek-chat"
 
 	return &draft, nil
 }
@@ -514,3 +605,41 @@ func formatSelectedOption(opt *models.ResponseOption) string {
 		opt.ImplementationEffort,
 		opt.RiskLevel)
 }
+
+// SuggestResponseOptions proposes 3-5 response options with cost, effort and risk estimates
+func (c *DeepSeekClient) SuggestResponseOptions(ctx context.Context, decision models.CustomerDecision, responseType *models.CustomerResponseType) ([]models.ResponseOption, error) {
+	// Wait for rate limiter
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit: %w", err)
+	}
+
+	prompt := buildResponseOptionsPrompt(decision, responseType)
+
+	response, err := c.chat(ctx, prompt, "deepseek-chat", 1500)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseResponseOptions(response)
+}
+
+// StreamResponseDraft streams a customer response draft as it is generated
+func (c *DeepSeekClient) StreamResponseDraft(ctx context.Context, req ResponseDraftRequest, onDelta func(string) error) (*ResponseDraft, error) {
+	// Wait for rate limiter
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit: %w", err)
+	}
+
+	parser := newDraftStreamParser(onDelta)
+	if err := c.chatStream(ctx, buildStreamingDraftPrompt(req), "deepseek-chat", 1500, parser.Write); err != nil {
+		return nil, err
+	}
+
+	draft, err := parser.Finish(req.CommunicationPreferences.Tone)
+	if err != nil {
+		return nil, err
+	}
+	draft.Model = "deepseek-chat"
+
+	return draft, nil
+}
@@ -0,0 +1,196 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"choseby-backend/internal/models"
+)
+
+// ErrNoProviderAvailable is returned when every provider in the chain is failing or has an open circuit
+var ErrNoProviderAvailable = errors.New("no AI provider available")
+
+// FailoverConfig configures the circuit breakers of a failover chain
+type FailoverConfig struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+// haltFailover marks a provider failure after which the chain must not be retried
+// (e.g. part of a draft has already been streamed to the client)
+type haltFailover struct {
+	err error
+}
+
+func (h haltFailover) Error() string { return h.err.Error() }
+
+func (h haltFailover) Unwrap() error { return h.err }
+
+type failoverMember struct {
+	provider Provider
+	breaker  *CircuitBreaker
+}
+
+// FailoverProvider tries an ordered chain of providers, skipping any whose circuit is open
+type FailoverProvider struct {
+	members []failoverMember
+}
+
+// NewFailoverProvider creates a failover chain; the first provider is the primary
+func NewFailoverProvider(providers []Provider, config FailoverConfig) *FailoverProvider {
+	members := make([]failoverMember, 0, len(providers))
+	for _, provider := range providers {
+		members = append(members, failoverMember{
+			provider: provider,
+			breaker:  NewCircuitBreaker(config.FailureThreshold, config.Cooldown),
+		})
+	}
+
+	return &FailoverProvider{members: members}
+}
+
+// Name returns the chain as an arrow-separated list of provider names
+func (f *FailoverProvider) Name() string {
+	names := make([]string, 0, len(f.members))
+	for _, member := range f.members {
+		names = append(names, member.provider.Name())
+	}
+	return strings.Join(names, "->")
+}
+
+// Stats returns the circuit breaker statistics of every provider in the chain
+func (f *FailoverProvider) Stats() map[string]interface{} {
+	stats := make(map[string]interface{}, len(f.members))
+	for _, member := range f.members {
+		stats[member.provider.Name()] = member.breaker.Stats()
+	}
+	return stats
+}
+
+// ClassifyCustomerIssue classifies with the first healthy provider and records which one answered
+func (f *FailoverProvider) ClassifyCustomerIssue(ctx context.Context, issue string, description string, availableTypes []models.CustomerResponseType) (*models.AIClassification, error) {
+	var classification *models.AIClassification
+	used, err := f.call(ctx, "classification", func(p Provider) error {
+		var callErr error
+		classification, callErr = p.ClassifyCustomerIssue(ctx, issue, description, availableTypes)
+		return callErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	classification.Provider = used
+	return classification, nil
+}
+
+// RecommendStakeholders recommends stakeholders with the first healthy provider
+func (f *FailoverProvider) RecommendStakeholders(ctx context.Context, decision models.CustomerDecision, responseType *models.CustomerResponseType) (*models.AIRecommendations, error) {
+	var recommendations *models.AIRecommendations
+	_, err := f.call(ctx, "stakeholder recommendation", func(p Provider) error {
+		var callErr error
+		recommendations, callErr = p.RecommendStakeholders(ctx, decision, responseType)
+		return callErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return recommendations, nil
+}
+
+// SuggestResponseOptions suggests options with the first healthy provider
+func (f *FailoverProvider) SuggestResponseOptions(ctx context.Context, decision models.CustomerDecision, responseType *models.CustomerResponseType) ([]models.ResponseOption, error) {
+	var options []models.ResponseOption
+	_, err := f.call(ctx, "response options", func(p Provider) error {
+		var callErr error
+		options, callErr = p.SuggestResponseOptions(ctx, decision, responseType)
+		return callErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
+
+// GenerateResponseDraft writes a draft with the first healthy provider and records which one answered
+func (f *FailoverProvider) GenerateResponseDraft(ctx context.Context, req ResponseDraftRequest) (*ResponseDraft, error) {
+	var draft *ResponseDraft
+	used, err := f.call(ctx, "response draft", func(p Provider) error {
+		var callErr error
+		draft, callErr = p.GenerateResponseDraft(ctx, req)
+		return callErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	draft.Provider = used
+	return draft, nil
+}
+
+// call runs fn against each provider in order until one succeeds, returning the name of that provider
+func (f *FailoverProvider) call(ctx context.Context, operation string, fn func(Provider) error) (string, error) {
+	var failures []string
+
+	for _, member := range f.members {
+		name := member.provider.Name()
+
+		if !member.breaker.Allow() {
+			failures = append(failures, fmt.Sprintf("%s: circuit open", name))
+			continue
+		}
+
+		err := fn(member.provider)
+		if err == nil {
+			member.breaker.RecordSuccess()
+			return name, nil
+		}
+
+		// The caller gave up; this says nothing about the provider's health
+		if ctx.Err() != nil {
+			member.breaker.Release()
+			return "", err
+		}
+
+		member.breaker.RecordFailure()
+
+		var halt haltFailover
+		if errors.As(err, &halt) {
+			return "", halt.err
+		}
+
+		failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+		log.Printf("AI %s failed on provider %s, trying next: %v", operation, name, err)
+	}
+
+	return "", fmt.Errorf("%w for %s: %s", ErrNoProviderAvailable, operation, strings.Join(failures, "; "))
+}
+
+// StreamResponseDraft streams a draft with the first healthy provider. Providers are only
+// switched before any text reaches the caller; a failure mid-stream is returned as is.
+func (f *FailoverProvider) StreamResponseDraft(ctx context.Context, req ResponseDraftRequest, onDelta func(string) error) (*ResponseDraft, error) {
+	var draft *ResponseDraft
+	used, err := f.call(ctx, "response draft stream", func(p Provider) error {
+		streamed := false
+		var callErr error
+		draft, callErr = streamOrGenerate(ctx, p, req, func(delta string) error {
+			streamed = true
+			return onDelta(delta)
+		})
+		if callErr != nil && streamed {
+			return haltFailover{err: callErr}
+		}
+		return callErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	draft.Provider = used
+	return draft, nil
+}
@@ -0,0 +1,143 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"choseby-backend/internal/models"
+)
+
+// fakeProvider is a scripted Provider used to exercise the failover chain
+type fakeProvider struct {
+	name  string
+	err   error
+	calls int
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) ClassifyCustomerIssue(ctx context.Context, issue string, description string, availableTypes []models.CustomerResponseType) (*models.AIClassification, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &models.AIClassification{DecisionType: "refund_request", UrgencyLevel: 3, ConfidenceScore: 0.9}, nil
+}
+
+func (p *fakeProvider) RecommendStakeholders(ctx context.Context, decision models.CustomerDecision, responseType *models.CustomerResponseType) (*models.AIRecommendations, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &models.AIRecommendations{}, nil
+}
+
+func (p *fakeProvider) SuggestResponseOptions(ctx context.Context, decision models.CustomerDecision, responseType *models.CustomerResponseType) ([]models.ResponseOption, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []models.ResponseOption{{Title: "Option A"}, {Title: "Option B"}, {Title: "Option C"}}, nil
+}
+
+func (p *fakeProvider) GenerateResponseDraft(ctx context.Context, req ResponseDraftRequest) (*ResponseDraft, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &ResponseDraft{DraftContent: "draft from " + p.name}, nil
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cb := NewCircuitBreaker(2, time.Minute)
+	cb.now = func() time.Time { return now }
+
+	cb.RecordFailure()
+	if cb.State() != CircuitClosed || !cb.Allow() {
+		t.Fatalf("breaker should stay closed below the threshold, got %s", cb.State())
+	}
+
+	cb.RecordFailure()
+	if cb.State() != CircuitOpen || cb.Allow() {
+		t.Fatalf("breaker should open at the threshold, got %s", cb.State())
+	}
+
+	now = now.Add(time.Minute)
+	if !cb.Allow() || cb.State() != CircuitHalfOpen {
+		t.Fatalf("breaker should let one probe through after cooldown, got %s", cb.State())
+	}
+	if cb.Allow() {
+		t.Error("breaker should allow only one half-open probe at a time")
+	}
+
+	cb.RecordFailure()
+	if cb.State() != CircuitOpen {
+		t.Fatalf("failed probe should reopen the breaker, got %s", cb.State())
+	}
+
+	now = now.Add(time.Minute)
+	cb.Allow()
+	cb.RecordSuccess()
+	if cb.State() != CircuitClosed {
+		t.Fatalf("successful probe should close the breaker, got %s", cb.State())
+	}
+}
+
+func TestFailoverProviderFallsBackAndRecordsProvider(t *testing.T) {
+	primary := &fakeProvider{name: ProviderDeepSeek, err: errors.New("status 503")}
+	secondary := &fakeProvider{name: ProviderModelScope}
+	chain := NewFailoverProvider([]Provider{primary, secondary}, FailoverConfig{FailureThreshold: 2, Cooldown: time.Minute})
+
+	classification, err := chain.ClassifyCustomerIssue(context.Background(), "Refund", "Customer wants a refund", nil)
+	if err != nil {
+		t.Fatalf("expected fallback to succeed: %v", err)
+	}
+	if classification.Provider != ProviderModelScope {
+		t.Errorf("expected provider %q to be recorded, got %q", ProviderModelScope, classification.Provider)
+	}
+
+	// A second failure opens the primary circuit, after which it is skipped entirely
+	if _, err := chain.GenerateResponseDraft(context.Background(), ResponseDraftRequest{}); err != nil {
+		t.Fatalf("expected fallback draft: %v", err)
+	}
+	if _, err := chain.RecommendStakeholders(context.Background(), models.CustomerDecision{}, nil); err != nil {
+		t.Fatalf("expected fallback recommendations: %v", err)
+	}
+	if primary.calls != 2 {
+		t.Errorf("expected primary to be skipped once its circuit opened, got %d calls", primary.calls)
+	}
+}
+
+func TestFailoverProviderAllFailing(t *testing.T) {
+	chain := NewFailoverProvider([]Provider{
+		&fakeProvider{name: ProviderDeepSeek, err: errors.New("timeout")},
+		&fakeProvider{name: ProviderOllama, err: errors.New("connection refused")},
+	}, FailoverConfig{})
+
+	_, err := chain.ClassifyCustomerIssue(context.Background(), "Issue", "", nil)
+	if !errors.Is(err, ErrNoProviderAvailable) {
+		t.Fatalf("expected ErrNoProviderAvailable, got %v", err)
+	}
+}
+
+func TestFailoverProviderStopsOnCallerCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	primary := &fakeProvider{name: ProviderDeepSeek, err: context.Canceled}
+	secondary := &fakeProvider{name: ProviderOllama}
+	chain := NewFailoverProvider([]Provider{primary, secondary}, FailoverConfig{FailureThreshold: 1})
+
+	if _, err := chain.ClassifyCustomerIssue(ctx, "Issue", "", nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if secondary.calls != 0 {
+		t.Error("cancelled requests must not fail over")
+	}
+	if chain.members[0].breaker.State() != CircuitClosed {
+		t.Error("cancellation must not trip the breaker")
+	}
+}
@@ -0,0 +1,453 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"choseby-backend/internal/models"
+)
+
+// Field weights applied when scoring keyword matches
+const (
+	keywordTitleWeight       = 2.0
+	keywordDescriptionWeight = 1.0
+	keywordPartialFactor     = 0.5
+)
+
+// keywordStopwords are dropped from both text and keywords; negations are deliberately kept
+// so phrases like "not working" and "not satisfied" still match.
+var keywordStopwords = map[string]bool{ //nolint:gochecknoglobals // read-only lookup table
+	"a": true, "an": true, "the": true, "to": true, "of": true, "for": true, "and": true,
+	"or": true, "in": true, "on": true, "at": true, "is": true, "are": true, "be": true,
+	"it": true, "this": true, "that": true, "with": true, "my": true, "our": true,
+	"we": true, "i": true, "you": true, "your": true, "me": true, "us": true,
+}
+
+// urgencySignals raise the urgency level when present in the issue text
+var urgencySignals = []string{ //nolint:gochecknoglobals // read-only lookup table
+	"urgent", "asap", "immediately", "emergency", "critical", "right now",
+	"unacceptable", "escalate", "cannot access", "can't access", "down",
+	"outage", "lawsuit", "legal action", "attorney", "ceo",
+}
+
+// calmSignals lower the urgency level when present in the issue text
+var calmSignals = []string{"no rush", "when you get a chance", "just curious", "how do i", "wondering"} //nolint:gochecknoglobals // read-only lookup table
+
+// riskSignals map phrases in the issue text to risk factors reported in the classification
+var riskSignals = map[string][]string{ //nolint:gochecknoglobals // read-only lookup table
+	"Legal or regulatory exposure":            {"gdpr", "legal", "lawsuit", "attorney", "compliance", "regulation", "sla"},
+	"Churn risk - competitor or cancellation": {"competitor", "cancel", "leaving", "switch to", "move to"},
+	"Repeated contact without resolution":     {"weeks", "again", "still", "no resolution", "third time", "multiple times"},
+	"Executive visibility":                    {"ceo", "executive", "board", "vp"},
+	"Customer-facing business impact":         {"entire team", "client", "production", "revenue", "deadline"},
+}
+
+// KeywordClassifier is a deterministic, offline provider driven by customer_response_types keywords.
+// It needs no API key and is used whenever no LLM provider is configured.
+type KeywordClassifier struct{}
+
+// NewKeywordClassifier creates a new offline keyword classifier
+func NewKeywordClassifier() *KeywordClassifier {
+	return &KeywordClassifier{}
+}
+
+// Name returns the provider registry name
+func (k *KeywordClassifier) Name() string {
+	return ProviderKeyword
+}
+
+// typeScore is the keyword score of a single response type
+type typeScore struct {
+	responseType *models.CustomerResponseType
+	score        float64
+}
+
+// ClassifyCustomerIssue scores the issue against every response type's keywords
+func (k *KeywordClassifier) ClassifyCustomerIssue(ctx context.Context, issue string, description string, availableTypes []models.CustomerResponseType) (*models.AIClassification, error) {
+	if len(availableTypes) == 0 {
+		return nil, fmt.Errorf("no response types available for classification")
+	}
+
+	titleTokens := tokenizeForMatching(issue)
+	descriptionTokens := tokenizeForMatching(description)
+
+	scores := make([]typeScore, 0, len(availableTypes))
+	for i := range availableTypes {
+		ts := typeScore{responseType: &availableTypes[i]}
+		for _, keyword := range availableTypes[i].AIClassificationKeywords {
+			keywordTokens := tokenizeForMatching(keyword)
+			if len(keywordTokens) == 0 {
+				continue
+			}
+
+			score := scoreKeyword(titleTokens, keywordTokens)*keywordTitleWeight +
+				scoreKeyword(descriptionTokens, keywordTokens)*keywordDescriptionWeight
+			ts.score += score
+		}
+		scores = append(scores, ts)
+	}
+
+	// Stable sort keeps the seeded type order as the tie breaker
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i].score > scores[j].score
+	})
+
+	best := scores[0]
+	if best.score == 0 {
+		best = typeScore{responseType: fallbackResponseType(availableTypes)}
+	}
+
+	second := 0.0
+	if len(scores) > 1 {
+		second = scores[1].score
+	}
+
+	text := strings.ToLower(issue + " " + description)
+
+	return &models.AIClassification{
+		DecisionType:    best.responseType.TypeCode,
+		UrgencyLevel:    estimateUrgency(text, best.responseType),
+		ConfidenceScore: keywordConfidence(best.score, second),
+		RiskFactors:     detectRiskFactors(text, best.responseType),
+	}, nil
+}
+
+// RecommendStakeholders derives stakeholders from the type defaults and the customer context
+func (k *KeywordClassifier) RecommendStakeholders(ctx context.Context, decision models.CustomerDecision, responseType *models.CustomerResponseType) (*models.AIRecommendations, error) {
+	var stakeholders []models.RecommendedStakeholder
+	seen := make(map[string]bool)
+	add := func(role string, weight float64, reasoning string) {
+		if seen[role] {
+			return
+		}
+		seen[role] = true
+		stakeholders = append(stakeholders, models.RecommendedStakeholder{Role: role, Weight: weight, Reasoning: reasoning})
+	}
+
+	if responseType != nil {
+		for i, role := range responseType.DefaultStakeholders {
+			weight := 0.8
+			if i == 0 {
+				weight = 1.0
+			}
+			add(role, weight, fmt.Sprintf("Default stakeholder for %s", responseType.TypeName))
+		}
+	}
+
+	if decision.FinancialImpact != nil && *decision.FinancialImpact >= 10000 {
+		add("account_manager", 0.9, "High financial impact requires account management input")
+	}
+	if decision.CustomerTier == "enterprise" || decision.CustomerTier == "strategic" {
+		add("customer_success_manager", 0.9, "Enterprise relationship owner")
+	}
+	text := strings.ToLower(decision.Title + " " + decision.Description)
+	for _, signal := range []string{"legal", "lawsuit", "gdpr", "compliance", "contract"} {
+		if containsPhrase(text, signal) {
+			add("legal_compliance", 0.7, "Issue mentions legal or contractual exposure")
+			break
+		}
+	}
+	if len(stakeholders) == 0 {
+		add("customer_success_manager", 1.0, "Primary owner of customer responses")
+	}
+
+	return &models.AIRecommendations{
+		RecommendedStakeholders: stakeholders,
+		SuggestedCriteria: []models.SuggestedCriterion{
+			{Name: "Customer Satisfaction Impact", Description: "Effect of the response on the customer relationship", Weight: 2.0},
+			{Name: "Financial Cost", Description: "Direct cost to the company", Weight: 1.5},
+			{Name: "Policy Consistency", Description: "Alignment with existing policies and precedent", Weight: 1.0},
+			{Name: "Implementation Speed", Description: "How quickly the response can be delivered", Weight: 1.0},
+		},
+	}, nil
+}
+
+// SuggestResponseOptions proposes tiered options scaled to the financial impact
+func (k *KeywordClassifier) SuggestResponseOptions(ctx context.Context, decision models.CustomerDecision, responseType *models.CustomerResponseType) ([]models.ResponseOption, error) {
+	impact := safeFloat(decision.FinancialImpact)
+	typeName := "the issue"
+	if responseType != nil {
+		typeName = strings.ToLower(responseType.TypeName)
+	}
+
+	return []models.ResponseOption{
+		{
+			Title:                "Full resolution",
+			Description:          fmt.Sprintf("Resolve %s completely in the customer's favour", typeName),
+			FinancialCost:        impact,
+			ImplementationEffort: "medium",
+			RiskLevel:            "low",
+			AIGenerated:          true,
+		},
+		{
+			Title:                "Partial compensation",
+			Description:          "Offer partial compensation together with a corrective action plan",
+			FinancialCost:        math.Round(impact*0.5*100) / 100,
+			ImplementationEffort: "medium",
+			RiskLevel:            "medium",
+			AIGenerated:          true,
+		},
+		{
+			Title:                "Goodwill service credit",
+			Description:          "Apply a service credit on the next invoice as a goodwill gesture",
+			FinancialCost:        math.Round(impact*0.2*100) / 100,
+			ImplementationEffort: "low",
+			RiskLevel:            "medium",
+			AIGenerated:          true,
+		},
+		{
+			Title:                "Standard policy response",
+			Description:          "Explain the applicable policy and offer guidance without compensation",
+			FinancialCost:        0,
+			ImplementationEffort: "low",
+			RiskLevel:            "high",
+			AIGenerated:          true,
+		},
+	}, nil
+}
+
+// GenerateResponseDraft fills a tone-specific template with the team's decision
+func (k *KeywordClassifier) GenerateResponseDraft(ctx context.Context, req ResponseDraftRequest) (*ResponseDraft, error) {
+	customer := req.CustomerContext.CustomerName
+	resolution := req.DecisionOutcome.SelectedOptionTitle
+	if req.SelectedOption != nil && req.SelectedOption.Description != "" {
+		resolution = fmt.Sprintf("%s: %s", req.SelectedOption.Title, req.SelectedOption.Description)
+	}
+
+	var opening, closing string
+	switch req.CommunicationPreferences.Tone {
+	case "formal_corporate":
+		opening = fmt.Sprintf("Dear %s,\n\nWe acknowledge receipt of your request regarding \"%s\". The matter has been reviewed by our team.", customer, req.CustomerContext.Title)
+		closing = "Should you require further information, please do not hesitate to contact us.\n\nKind regards"
+	case "friendly_apologetic":
+		opening = fmt.Sprintf("Hi %s,\n\nWe're really sorry about \"%s\" and the trouble it has caused you.", customer, req.CustomerContext.Title)
+		closing = "Thanks so much for your patience - we truly appreciate you.\n\nWarm regards"
+	case "concise_factual":
+		opening = fmt.Sprintf("Hello %s,\n\nRegarding \"%s\":", customer, req.CustomerContext.Title)
+		closing = "Reply to this message if you have any questions.\n\nRegards"
+	default:
+		opening = fmt.Sprintf("Dear %s,\n\nThank you for bringing \"%s\" to our attention. We understand the impact this has had on you.", customer, req.CustomerContext.Title)
+		closing = "We value your partnership and appreciate your patience.\n\nBest regards"
+	}
+
+	content := fmt.Sprintf("%s\n\nAfter reviewing the situation, our team has decided on the following resolution: %s.\n\nWe will keep you informed as this is put in place.\n\n%s",
+		opening, resolution, closing)
+
+	impact := "positive"
+	if req.SelectedOption != nil && req.SelectedOption.RiskLevel == "high" {
+		impact = "neutral"
+	}
+
+	return &ResponseDraft{
+		DraftContent: content,
+		KeyPoints: []string{
+			"Acknowledge the customer's issue",
+			fmt.Sprintf("Resolution: %s", req.DecisionOutcome.SelectedOptionTitle),
+			"Commit to keeping the customer informed",
+		},
+		Tone:                        req.CommunicationPreferences.Tone,
+		EstimatedSatisfactionImpact: impact,
+		FollowUpRecommendations:     []string{"Confirm the resolution with the customer within 48 hours"},
+		Model:                       ProviderKeyword,
+	}, nil
+}
+
+// scoreKeyword scores one keyword against a token stream: full phrase matches count per
+// occurrence (longer phrases score higher), otherwise a fraction of the keyword tokens scores partially
+func scoreKeyword(text []string, keyword []string) float64 {
+	occurrences := 0
+	for i := 0; i+len(keyword) <= len(text); i++ {
+		match := true
+		for j := range keyword {
+			if text[i+j] != keyword[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			occurrences++
+		}
+	}
+	if occurrences > 0 {
+		return float64(occurrences) * (1 + 0.5*float64(len(keyword)-1))
+	}
+
+	if len(keyword) == 1 {
+		return 0
+	}
+
+	present := make(map[string]bool, len(text))
+	for _, token := range text {
+		present[token] = true
+	}
+	matched := 0
+	for _, token := range keyword {
+		if present[token] {
+			matched++
+		}
+	}
+
+	return keywordPartialFactor * float64(matched) / float64(len(keyword))
+}
+
+// tokenizeForMatching lowercases, splits, drops stopwords and stems text; question marks become
+// a "question" token so inquiries match the general inquiry keywords
+func tokenizeForMatching(text string) []string {
+	text = strings.ReplaceAll(strings.ToLower(text), "?", " question ")
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	stopwords := keywordStopwords
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		if stopwords[word] {
+			continue
+		}
+		tokens = append(tokens, stemWord(word))
+	}
+
+	return tokens
+}
+
+// stemWord is a light suffix-stripping stemmer so "charged", "charges" and "charge" collide
+func stemWord(word string) string {
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		word = word[:len(word)-3] + "y"
+	case len(word) > 5 && strings.HasSuffix(word, "ing"):
+		word = undouble(word[:len(word)-3])
+	case len(word) > 4 && strings.HasSuffix(word, "ed"):
+		word = undouble(word[:len(word)-2])
+	case len(word) > 4 && strings.HasSuffix(word, "ly"):
+		word = word[:len(word)-2]
+	case len(word) > 3 && strings.HasSuffix(word, "s") &&
+		!strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is"):
+		word = word[:len(word)-1]
+	}
+
+	if len(word) > 3 && strings.HasSuffix(word, "e") {
+		word = word[:len(word)-1]
+	}
+
+	return word
+}
+
+// undouble removes a doubled final consonant ("cancell" -> "cancel")
+func undouble(word string) string {
+	n := len(word)
+	if n < 3 || word[n-1] != word[n-2] || word[n-1] == 's' || strings.ContainsRune("aeiou", rune(word[n-1])) {
+		return word
+	}
+	return word[:n-1]
+}
+
+// estimateUrgency combines the response type's SLA with urgency and calm language in the text
+func estimateUrgency(text string, responseType *models.CustomerResponseType) int {
+	urgency := 2
+	if responseType != nil {
+		if responseType.RequiresEscalation {
+			urgency++
+		}
+		if responseType.TypicalResolutionTimeHours != nil && *responseType.TypicalResolutionTimeHours <= 4 {
+			urgency++
+		}
+	}
+
+	signals := 0
+	for _, signal := range urgencySignals {
+		if containsPhrase(text, signal) {
+			signals++
+		}
+	}
+	if signals > 3 {
+		signals = 3
+	}
+	urgency += signals
+
+	for _, signal := range calmSignals {
+		if strings.Contains(text, signal) {
+			urgency--
+			break
+		}
+	}
+
+	if urgency < 1 {
+		return 1
+	}
+	if urgency > 5 {
+		return 5
+	}
+	return urgency
+}
+
+// detectRiskFactors lists risk factors whose signal phrases appear in the text
+func detectRiskFactors(text string, responseType *models.CustomerResponseType) []string {
+	factors := []string{}
+	for factor, signals := range riskSignals {
+		for _, signal := range signals {
+			if containsPhrase(text, signal) {
+				factors = append(factors, factor)
+				break
+			}
+		}
+	}
+	if responseType != nil && responseType.RequiresEscalation {
+		factors = append(factors, "Response type requires escalation")
+	}
+
+	sort.Strings(factors)
+	return factors
+}
+
+// containsPhrase matches a phrase on word boundaries
+func containsPhrase(text string, phrase string) bool {
+	for start := 0; ; {
+		idx := strings.Index(text[start:], phrase)
+		if idx < 0 {
+			return false
+		}
+		idx += start
+		end := idx + len(phrase)
+		before := idx == 0 || !isWordByte(text[idx-1])
+		after := end == len(text) || !isWordByte(text[end])
+		if before && after {
+			return true
+		}
+		start = idx + 1
+	}
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9')
+}
+
+// keywordConfidence turns the winning margin and absolute score into a 0.2-0.95 confidence
+func keywordConfidence(best, second float64) float64 {
+	if best <= 0 {
+		return 0.2
+	}
+
+	margin := (best - second) / best
+	strength := best / (best + 2)
+	confidence := 0.3 + 0.4*margin + 0.3*strength
+	if confidence > 0.95 {
+		confidence = 0.95
+	}
+
+	return math.Round(confidence*100) / 100
+}
+
+// fallbackResponseType picks general_inquiry (or the first type) when nothing matches
+func fallbackResponseType(types []models.CustomerResponseType) *models.CustomerResponseType {
+	for i := range types {
+		if types[i].TypeCode == "general_inquiry" {
+			return &types[i]
+		}
+	}
+	return &types[0]
+}
@@ -0,0 +1,91 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestKeywordClassifierAccuracy runs the offline classifier over the shared test scenarios
+func TestKeywordClassifierAccuracy(t *testing.T) {
+	scenarios, err := loadTestScenarios()
+	assert.NoError(t, err, "Should load test scenarios")
+
+	classifier := NewKeywordClassifier()
+	responseTypes := getMockResponseTypes()
+
+	correct := 0
+	urgencyWithinOne := 0
+	for _, scenario := range scenarios {
+		classification, err := classifier.ClassifyCustomerIssue(context.Background(), scenario.Title, scenario.Description, responseTypes)
+		assert.NoError(t, err)
+
+		if classification.DecisionType == scenario.ExpectedClassification {
+			correct++
+		} else {
+			t.Logf("Scenario %d: expected %s, got %s (confidence %.2f)",
+				scenario.ID, scenario.ExpectedClassification, classification.DecisionType, classification.ConfidenceScore)
+		}
+		if abs(classification.UrgencyLevel-scenario.ExpectedUrgency) <= 1 {
+			urgencyWithinOne++
+		}
+
+		assert.GreaterOrEqual(t, classification.ConfidenceScore, 0.2)
+		assert.LessOrEqual(t, classification.ConfidenceScore, 0.95)
+		assert.GreaterOrEqual(t, classification.UrgencyLevel, 1)
+		assert.LessOrEqual(t, classification.UrgencyLevel, 5)
+	}
+
+	accuracy := float64(correct) / float64(len(scenarios)) * 100
+	t.Logf("Keyword classification accuracy: %.1f%%, urgency within one level: %d/%d", accuracy, urgencyWithinOne, len(scenarios))
+	assert.GreaterOrEqual(t, accuracy, 80.0, "Offline classifier should classify at least 80%% of scenarios")
+}
+
+func TestKeywordClassifierIsDeterministic(t *testing.T) {
+	classifier := NewKeywordClassifier()
+	responseTypes := getMockResponseTypes()
+
+	first, err := classifier.ClassifyCustomerIssue(context.Background(), "Service outage", "System down since the morning", responseTypes)
+	assert.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		again, err := classifier.ClassifyCustomerIssue(context.Background(), "Service outage", "System down since the morning", responseTypes)
+		assert.NoError(t, err)
+		assert.Equal(t, first, again)
+	}
+}
+
+func TestKeywordClassifierFallsBackToGeneralInquiry(t *testing.T) {
+	classification, err := NewKeywordClassifier().ClassifyCustomerIssue(context.Background(), "Hello", "Thanks for the quick reply", getMockResponseTypes())
+	assert.NoError(t, err)
+	assert.Equal(t, "general_inquiry", classification.DecisionType)
+	assert.Equal(t, 0.2, classification.ConfidenceScore)
+}
+
+func TestStemWord(t *testing.T) {
+	cases := map[string]string{
+		"charged":    "charg",
+		"charges":    "charg",
+		"charge":     "charg",
+		"cancelling": "cancel",
+		"cancelled":  "cancel",
+		"cancel":     "cancel",
+		"features":   "featur",
+		"feature":    "featur",
+		"access":     "access",
+		"policies":   "policy",
+	}
+	for word, want := range cases {
+		assert.Equal(t, want, stemWord(word), "stemWord(%q)", word)
+	}
+}
+
+func TestScoreKeywordPhraseMatching(t *testing.T) {
+	text := tokenizeForMatching("I want a full refund, not a partial one")
+
+	assert.Equal(t, 1.5, scoreKeyword(text, tokenizeForMatching("full refund")))
+	assert.Equal(t, 1.0, scoreKeyword(text, tokenizeForMatching("refund")))
+	assert.Equal(t, keywordPartialFactor, scoreKeyword(text, tokenizeForMatching("partial refund")), "partial matches score a fraction")
+	assert.Equal(t, 0.0, scoreKeyword(text, tokenizeForMatching("outage")))
+}
@@ -59,6 +59,11 @@ func NewModelScopeClient(config ModelScopeConfig) *ModelScopeClient {
 	}
 }
 
+// Name returns the provider registry name
+func (c *ModelScopeClient) Name() string {
+	return ProviderModelScope
+}
+
 // ClassifyCustomerIssue analyzes customer issue using ModelScope Qwen models
 func (c *ModelScopeClient) ClassifyCustomerIssue(ctx context.Context, issue string, description string, availableTypes []models.CustomerResponseType) (*models.AIClassification, error) {
 	// Wait for rate limiter
@@ -306,10 +311,28 @@ Respond ONLY with valid JSON (no markdown, no code blocks):
 			return nil, fmt.Errorf("failed to parse draft: %w (response: %s)", err, cleaned)
 		}
 	}
+	draft.Model = c.model
 
 	return &draft, nil
 }
 
+// SuggestResponseOptions proposes 3-5 response options with cost, effort and risk estimates
+func (c *ModelScopeClient) SuggestResponseOptions(ctx context.Context, decision models.CustomerDecision, responseType *models.CustomerResponseType) ([]models.ResponseOption, error) {
+	// Wait for rate limiter
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit: %w", err)
+	}
+
+	prompt := buildResponseOptionsPrompt(decision, responseType)
+
+	response, err := c.chat(ctx, prompt, 1500)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseResponseOptions(response)
+}
+
 // chat sends a chat completion request to ModelScope API (OpenAI-compatible)
 func (c *ModelScopeClient) chat(ctx context.Context, prompt string, maxTokens int) (string, error) {
 	// ModelScope uses OpenAI-compatible format
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"choseby-backend/internal/models"
 )
@@ -18,19 +19,41 @@ type OllamaClient struct {
 	httpClient *http.Client
 }
 
+// OllamaConfig holds configuration for a local Ollama instance
+type OllamaConfig struct {
+	BaseURL string
+	Model   string
+	Timeout time.Duration // Zero means no client-side timeout (local models can be slow)
+}
+
 // NewOllamaClient creates a new Ollama client for local inference
 func NewOllamaClient(model string) *OllamaClient {
-	if model == "" {
-		model = "deepseek-r1:1.5b"
+	return NewOllamaClientWithConfig(OllamaConfig{Model: model})
+}
+
+// NewOllamaClientWithConfig creates a new Ollama client with explicit configuration
+func NewOllamaClientWithConfig(config OllamaConfig) *OllamaClient {
+	if config.BaseURL == "" {
+		config.BaseURL = "http://localhost:11434"
+	}
+	if config.Model == "" {
+		config.Model = "deepseek-r1:1.5b"
 	}
 
 	return &OllamaClient{
-		baseURL:    "http://localhost:11434",
-		model:      model,
-		httpClient: &http.Client{},
+		baseURL: config.BaseURL,
+		model:   config.Model,
+		httpClient: &http.Client{
+			Timeout: config.Timeout,
+		},
 	}
 }
 
+// Name returns the provider registry name
+func (c *OllamaClient) Name() string {
+	return ProviderOllama
+}
+
 // OllamaRequest represents a request to Ollama API
 type OllamaRequest struct {
 	Model  string `json:"model"`
@@ -89,6 +112,60 @@ func (c *OllamaClient) generate(ctx context.Context, prompt string) (string, err
 	return apiResp.Response, nil
 }
 
+// generateStream sends a prompt with stream enabled and calls onChunk with each response fragment
+func (c *OllamaClient) generateStream(ctx context.Context, prompt string, onChunk func(string) error) error {
+	reqBody := OllamaRequest{
+		Model:  c.model,
+		Prompt: prompt,
+		Stream: true,
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/generate", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// The client timeout would cut long streams off mid-body; the request context bounds the stream instead
+	streamClient := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	// Ollama streams newline-delimited JSON objects
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var chunk OllamaResponse
+		if err := decoder.Decode(&chunk); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+		if chunk.Response != "" {
+			if err := onChunk(chunk.Response); err != nil {
+				return err
+			}
+		}
+		if chunk.Done {
+			return nil
+		}
+	}
+}
+
 // ClassifyCustomerIssue analyzes customer issue using local Ollama model
 func (c *OllamaClient) ClassifyCustomerIssue(ctx context.Context, issue string, description string, availableTypes []models.CustomerResponseType) (*models.AIClassification, error) {
 	// Build classification prompt (same as DeepSeek client)
@@ -234,6 +311,95 @@ Respond ONLY with valid JSON (no markdown, no explanations):
 	return &recommendations, nil
 }
 
+// GenerateResponseDraft creates a customer response draft using local model
+func (c *OllamaClient) GenerateResponseDraft(ctx context.Context, req ResponseDraftRequest) (*ResponseDraft, error) {
+	// Build response draft prompt (simplified from DeepSeek version)
+	customerEmail := ""
+	if req.CustomerContext.CustomerEmail != nil {
+		customerEmail = *req.CustomerContext.CustomerEmail
+	}
+
+	prompt := fmt.Sprintf(`Write a customer response draft based on the team's decision.
+
+CUSTOMER:
+Name: %s (%s)
+Tier: %s
+
+ISSUE:
+Title: %s
+Description: %s
+
+TEAM DECISION:
+Selected Response: %s
+Reasoning: %s
+
+SELECTED OPTION:
+%s
+
+TONE: %s
+%s
+
+OUTPUT FORMAT (JSON only, no other text):
+{"draft_content":"Full response text (150-300 words)","key_points":["point1","point2","point3"],"tone":"%s","estimated_satisfaction_impact":"positive|neutral|negative","follow_up_recommendations":["rec1","rec2"]}`,
+		req.CustomerContext.CustomerName,
+		customerEmail,
+		req.CustomerContext.CustomerTier,
+		req.CustomerContext.Title,
+		req.CustomerContext.Description,
+		req.DecisionOutcome.SelectedOptionTitle,
+		req.DecisionOutcome.Reasoning,
+		formatSelectedOption(req.SelectedOption),
+		req.CommunicationPreferences.Tone,
+		getToneInstructions(req.CommunicationPreferences.Tone),
+		req.CommunicationPreferences.Tone)
+
+	response, err := c.generate(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	// DeepSeek R1 reasoning model outputs <think> tags - extract JSON only
+	cleaned := extractReasoningJSON(response)
+
+	// Parse JSON response
+	var draft ResponseDraft
+	if err := json.Unmarshal([]byte(cleaned), &draft); err != nil {
+		return nil, fmt.Errorf("failed to parse draft: %w (response: %s)", err, cleaned)
+	}
+	draft.Model = c.model
+
+	return &draft, nil
+}
+
+// SuggestResponseOptions proposes 3-5 response options with cost, effort and risk estimates
+func (c *OllamaClient) SuggestResponseOptions(ctx context.Context, decision models.CustomerDecision, responseType *models.CustomerResponseType) ([]models.ResponseOption, error) {
+	prompt := buildResponseOptionsPrompt(decision, responseType)
+
+	response, err := c.generate(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	// DeepSeek R1 reasoning model outputs <think> tags - extract JSON only
+	return parseResponseOptions(extractReasoningJSON(response))
+}
+
+// StreamResponseDraft streams a customer response draft as it is generated
+func (c *OllamaClient) StreamResponseDraft(ctx context.Context, req ResponseDraftRequest, onDelta func(string) error) (*ResponseDraft, error) {
+	parser := newDraftStreamParser(onDelta)
+	if err := c.generateStream(ctx, buildStreamingDraftPrompt(req), parser.Write); err != nil {
+		return nil, err
+	}
+
+	draft, err := parser.Finish(req.CommunicationPreferences.Tone)
+	if err != nil {
+		return nil, err
+	}
+	draft.Model = c.model
+
+	return draft, nil
+}
+
 // extractReasoningJSON removes <think> tags and extracts JSON from DeepSeek R1 reasoning output
 func extractReasoningJSON(response string) string {
 	// DeepSeek R1 outputs: <think>reasoning...</think>\n{json}
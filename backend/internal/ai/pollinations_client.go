@@ -53,6 +53,11 @@ func NewPollinationsClient(config PollinationsConfig) *PollinationsClient {
 	}
 }
 
+// Name returns the provider registry name
+func (c *PollinationsClient) Name() string {
+	return ProviderPollinations
+}
+
 // PollinationsRequest represents a request to Pollinations API
 type PollinationsRequest struct {
 	Messages         []Message              `json:"messages"`
@@ -220,10 +225,28 @@ Respond ONLY with JSON (no markdown):
 			return nil, fmt.Errorf("failed to parse draft: %w (response: %s)", err, cleaned)
 		}
 	}
+	draft.Model = "openai"
 
 	return &draft, nil
 }
 
+// SuggestResponseOptions proposes 3-5 response options with cost, effort and risk estimates
+func (c *PollinationsClient) SuggestResponseOptions(ctx context.Context, decision models.CustomerDecision, responseType *models.CustomerResponseType) ([]models.ResponseOption, error) {
+	// Wait for rate limiter
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit: %w", err)
+	}
+
+	prompt := buildResponseOptionsPrompt(decision, responseType)
+
+	response, err := c.chat(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseResponseOptions(response)
+}
+
 // chat sends a chat completion request to Pollinations API
 func (c *PollinationsClient) chat(ctx context.Context, prompt string) (string, error) {
 	reqBody := PollinationsRequest{
@@ -0,0 +1,143 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"choseby-backend/internal/models"
+)
+
+// Supported AI provider names (AI_PROVIDER values)
+const (
+	ProviderDeepSeek     = "deepseek"
+	ProviderModelScope   = "modelscope"
+	ProviderPollinations = "pollinations"
+	ProviderOllama       = "ollama"
+	ProviderKeyword      = "keyword"
+)
+
+// Provider is implemented by every AI backend that can power the customer response workflow
+type Provider interface {
+	// Name returns the registry name of the provider (e.g. "deepseek")
+	Name() string
+
+	// ClassifyCustomerIssue classifies a customer issue into one of the available response types
+	ClassifyCustomerIssue(ctx context.Context, issue string, description string, availableTypes []models.CustomerResponseType) (*models.AIClassification, error)
+
+	// RecommendStakeholders suggests which roles should be involved in the decision
+	RecommendStakeholders(ctx context.Context, decision models.CustomerDecision, responseType *models.CustomerResponseType) (*models.AIRecommendations, error)
+
+	// SuggestResponseOptions proposes response options for the team to evaluate
+	SuggestResponseOptions(ctx context.Context, decision models.CustomerDecision, responseType *models.CustomerResponseType) ([]models.ResponseOption, error)
+
+	// GenerateResponseDraft writes a customer-facing response for the team's decision
+	GenerateResponseDraft(ctx context.Context, req ResponseDraftRequest) (*ResponseDraft, error)
+}
+
+// ProviderConfig holds the settings used to construct a provider
+type ProviderConfig struct {
+	APIKey            string
+	BaseURL           string
+	Model             string
+	Timeout           time.Duration
+	MaxRequestsPerMin int
+}
+
+// ProviderFactory builds a provider from its configuration
+type ProviderFactory func(config ProviderConfig) (Provider, error)
+
+// Registry maps provider names to factories
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]ProviderFactory
+}
+
+// NewRegistry creates a registry with all built-in providers registered
+func NewRegistry() *Registry {
+	r := &Registry{factories: make(map[string]ProviderFactory)}
+
+	r.Register(ProviderDeepSeek, func(config ProviderConfig) (Provider, error) {
+		return NewDeepSeekClient(DeepSeekConfig{
+			APIKey:            config.APIKey,
+			BaseURL:           config.BaseURL,
+			Timeout:           config.Timeout,
+			MaxRequestsPerMin: config.MaxRequestsPerMin,
+		}), nil
+	})
+
+	r.Register(ProviderModelScope, func(config ProviderConfig) (Provider, error) {
+		return NewModelScopeClient(ModelScopeConfig{
+			APIKey:            config.APIKey,
+			Model:             config.Model,
+			BaseURL:           config.BaseURL,
+			Timeout:           config.Timeout,
+			MaxRequestsPerMin: config.MaxRequestsPerMin,
+		}), nil
+	})
+
+	r.Register(ProviderPollinations, func(config ProviderConfig) (Provider, error) {
+		return NewPollinationsClient(PollinationsConfig{
+			APIToken:          config.APIKey,
+			BaseURL:           config.BaseURL,
+			Timeout:           config.Timeout,
+			MaxRequestsPerMin: config.MaxRequestsPerMin,
+		}), nil
+	})
+
+	r.Register(ProviderOllama, func(config ProviderConfig) (Provider, error) {
+		return NewOllamaClientWithConfig(OllamaConfig{
+			BaseURL: config.BaseURL,
+			Model:   config.Model,
+			Timeout: config.Timeout,
+		}), nil
+	})
+
+	r.Register(ProviderKeyword, func(config ProviderConfig) (Provider, error) {
+		return NewKeywordClassifier(), nil
+	})
+
+	return r
+}
+
+// Register adds or replaces the factory for a provider name
+func (r *Registry) Register(name string, factory ProviderFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.factories[name] = factory
+}
+
+// Build constructs the named provider
+func (r *Registry) Build(name string, config ProviderConfig) (Provider, error) {
+	r.mu.RLock()
+	factory, ok := r.factories[name]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown AI provider %q (available: %v)", name, r.Names())
+	}
+
+	provider, err := factory(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build AI provider %q: %w", name, err)
+	}
+
+	return provider, nil
+}
+
+// Names returns the registered provider names in sorted order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
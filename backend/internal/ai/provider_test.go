@@ -0,0 +1,42 @@
+package ai
+
+import (
+	"testing"
+)
+
+func TestRegistryBuildsBuiltInProviders(t *testing.T) {
+	registry := NewRegistry()
+
+	for _, name := range []string{ProviderDeepSeek, ProviderModelScope, ProviderPollinations, ProviderOllama, ProviderKeyword} {
+		provider, err := registry.Build(name, ProviderConfig{APIKey: "test-key"})
+		if err != nil {
+			t.Fatalf("Build(%q) failed: %v", name, err)
+		}
+		if provider.Name() != name {
+			t.Errorf("Build(%q) returned provider named %q", name, provider.Name())
+		}
+	}
+}
+
+func TestRegistryRejectsUnknownProvider(t *testing.T) {
+	registry := NewRegistry()
+
+	if _, err := registry.Build("unknown", ProviderConfig{}); err == nil {
+		t.Error("expected error for unknown provider")
+	}
+}
+
+func TestRegistryRegisterCustomProvider(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("custom", func(config ProviderConfig) (Provider, error) {
+		return NewOllamaClientWithConfig(OllamaConfig{BaseURL: config.BaseURL}), nil
+	})
+
+	names := registry.Names()
+	if len(names) != 6 {
+		t.Fatalf("expected 6 registered providers, got %v", names)
+	}
+	if _, err := registry.Build("custom", ProviderConfig{BaseURL: "http://localhost:11434"}); err != nil {
+		t.Errorf("Build(custom) failed: %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"choseby-backend/internal/models"
+)
+
+// Bounds on the number of AI-suggested response options
+const (
+	MinResponseOptions = 3
+	MaxResponseOptions = 5
+)
+
+// suggestedOption is the JSON shape the models are asked to return for each option
+type suggestedOption struct {
+	Title                string  `json:"title"`
+	Description          string  `json:"description"`
+	FinancialCost        float64 `json:"financial_cost"`
+	ImplementationEffort string  `json:"implementation_effort"`
+	RiskLevel            string  `json:"risk_level"`
+}
+
+// buildResponseOptionsPrompt builds the option generation prompt shared by all providers
+func buildResponseOptionsPrompt(decision models.CustomerDecision, responseType *models.CustomerResponseType) string {
+	typeContext := "Not yet classified"
+	if responseType != nil {
+		typeContext = fmt.Sprintf("%s (%s)", responseType.TypeName, responseType.TypeCode)
+		if responseType.Description != nil {
+			typeContext += ": " + *responseType.Description
+		}
+		if responseType.RequiresEscalation {
+			typeContext += "\n- This response type normally requires escalation"
+		}
+		if responseType.TypicalResolutionTimeHours != nil {
+			typeContext += fmt.Sprintf("\n- Typical resolution time: %d hours", *responseType.TypicalResolutionTimeHours)
+		}
+	}
+
+	return fmt.Sprintf(`You are a customer service AI assistant. Suggest response options for the team to evaluate.
+
+Customer Context:
+- Name: %s
+- Tier: %s (detailed: %s)
+- Value: $%.2f
+- Relationship: %d months
+- Previous Issues: %d
+- Urgency: %d (%s)
+- Impact Scope: %s
+
+Issue Type: %s
+
+Decision:
+- Title: %s
+- Description: %s
+- Financial Impact: $%.2f
+
+Task: Suggest between %d and %d distinct response options, ranging from conservative to generous.
+For each option estimate the financial cost to the company in dollars, the implementation effort and the risk level.
+Higher-tier customers and larger financial impact justify more generous options.
+
+Respond ONLY with valid JSON (no markdown, no code blocks):
+{
+  "options": [
+    {
+      "title": "Short option title",
+      "description": "What the team would do and why",
+      "financial_cost": 500.00,
+      "implementation_effort": "low|medium|high",
+      "risk_level": "low|medium|high"
+    }
+  ]
+}`,
+		decision.CustomerName,
+		decision.CustomerTier,
+		decision.CustomerTierDetailed,
+		safeFloat(decision.CustomerValue),
+		decision.RelationshipDurationMonths,
+		decision.PreviousIssuesCount,
+		decision.UrgencyLevel,
+		decision.UrgencyLevelDetailed,
+		decision.CustomerImpactScope,
+		typeContext,
+		decision.Title,
+		decision.Description,
+		safeFloat(decision.FinancialImpact),
+		MinResponseOptions,
+		MaxResponseOptions)
+}
+
+// parseResponseOptions parses and normalizes the options returned by a model
+func parseResponseOptions(response string) ([]models.ResponseOption, error) {
+	var payload struct {
+		Options []suggestedOption `json:"options"`
+	}
+	if err := json.Unmarshal([]byte(response), &payload); err != nil {
+		cleaned := extractJSON(response)
+		if err := json.Unmarshal([]byte(cleaned), &payload); err != nil {
+			return nil, fmt.Errorf("failed to parse response options: %w (response: %s)", err, cleaned)
+		}
+	}
+
+	options := make([]models.ResponseOption, 0, len(payload.Options))
+	for _, suggested := range payload.Options {
+		title := strings.TrimSpace(suggested.Title)
+		if title == "" {
+			continue
+		}
+
+		cost := suggested.FinancialCost
+		if cost < 0 {
+			cost = 0
+		}
+
+		options = append(options, models.ResponseOption{
+			Title:                title,
+			Description:          strings.TrimSpace(suggested.Description),
+			FinancialCost:        cost,
+			ImplementationEffort: normalizeLevel(suggested.ImplementationEffort),
+			RiskLevel:            normalizeLevel(suggested.RiskLevel),
+			AIGenerated:          true,
+		})
+	}
+
+	if len(options) < MinResponseOptions {
+		return nil, fmt.Errorf("expected at least %d response options, got %d", MinResponseOptions, len(options))
+	}
+	if len(options) > MaxResponseOptions {
+		options = options[:MaxResponseOptions]
+	}
+
+	return options, nil
+}
+
+// normalizeLevel maps free-form effort/risk values onto low, medium or high
+func normalizeLevel(level string) string {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "low", "minimal", "minor":
+		return "low"
+	case "high", "significant", "major", "critical":
+		return "high"
+	default:
+		return "medium"
+	}
+}
@@ -0,0 +1,73 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"choseby-backend/internal/models"
+)
+
+func TestParseResponseOptions(t *testing.T) {
+	response := "```json\n" + `{
+  "options": [
+    {"title": "Full refund", "description": "Refund the annual plan", "financial_cost": 12000, "implementation_effort": "Low", "risk_level": "medium"},
+    {"title": "Partial credit", "description": "50% credit on next invoice", "financial_cost": 6000, "implementation_effort": "low", "risk_level": "low"},
+    {"title": "Service upgrade", "description": "Free upgrade for 3 months", "financial_cost": 1500, "implementation_effort": "significant", "risk_level": "unknown"},
+    {"title": "  ", "description": "Untitled options are dropped"}
+  ]
+}` + "\n```"
+
+	options, err := parseResponseOptions(response)
+	if err != nil {
+		t.Fatalf("parseResponseOptions failed: %v", err)
+	}
+	if len(options) != 3 {
+		t.Fatalf("expected 3 options, got %d", len(options))
+	}
+
+	if options[0].ImplementationEffort != "low" {
+		t.Errorf("expected effort to be normalized to low, got %q", options[0].ImplementationEffort)
+	}
+	if options[2].ImplementationEffort != "high" || options[2].RiskLevel != "medium" {
+		t.Errorf("unexpected normalization: effort=%q risk=%q", options[2].ImplementationEffort, options[2].RiskLevel)
+	}
+	for _, option := range options {
+		if !option.AIGenerated {
+			t.Errorf("option %q should be flagged as AI generated", option.Title)
+		}
+	}
+}
+
+func TestParseResponseOptionsBounds(t *testing.T) {
+	if _, err := parseResponseOptions(`{"options": [{"title": "Only one"}]}`); err == nil {
+		t.Error("expected an error when fewer than 3 options are returned")
+	}
+
+	many := `{"options": [` + strings.Repeat(`{"title": "Option"},`, 6) + `{"title": "Last"}]}`
+	options, err := parseResponseOptions(many)
+	if err != nil {
+		t.Fatalf("parseResponseOptions failed: %v", err)
+	}
+	if len(options) != MaxResponseOptions {
+		t.Errorf("expected options to be capped at %d, got %d", MaxResponseOptions, len(options))
+	}
+}
+
+func TestBuildResponseOptionsPromptIncludesContext(t *testing.T) {
+	impact := 25000.0
+	description := "Customer requesting complete refund"
+	decision := models.CustomerDecision{
+		CustomerName:    "Acme Corp",
+		CustomerTier:    "enterprise",
+		Title:           "Refund for outage",
+		FinancialImpact: &impact,
+	}
+	responseType := &models.CustomerResponseType{TypeCode: "refund_full", TypeName: "Full Refund Request", Description: &description}
+
+	prompt := buildResponseOptionsPrompt(decision, responseType)
+	for _, want := range []string{"Acme Corp", "enterprise", "refund_full", "$25000.00"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
@@ -2,31 +2,36 @@ package ai
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	"choseby-backend/internal/database"
 	"choseby-backend/internal/models"
+
+	"github.com/google/uuid"
 )
 
 // Service provides AI-powered customer response intelligence
 type Service struct {
-	deepseek *DeepSeekClient
+	provider Provider
 	db       *database.DB
 }
 
-// NewAIService creates a new AI service
-func NewAIService(apiKey string, db *database.DB) *Service {
-	config := DeepSeekConfig{
-		APIKey:            apiKey,
-		MaxRequestsPerMin: 60,
-	}
-
+// NewAIService creates a new AI service backed by the given provider
+func NewAIService(provider Provider, db *database.DB) *Service {
 	return &Service{
-		deepseek: NewDeepSeekClient(config),
+		provider: provider,
 		db:       db,
 	}
 }
 
+// ProviderName returns the name of the provider backing this service
+func (s *Service) ProviderName() string {
+	return s.provider.Name()
+}
+
 // ClassifyDecision analyzes a customer decision and provides AI classification
 func (s *Service) ClassifyDecision(ctx context.Context, decision *models.CustomerDecision) error {
 	// Get available response types from database
@@ -41,11 +46,14 @@ func (s *Service) ClassifyDecision(ctx context.Context, decision *models.Custome
 		return fmt.Errorf("failed to fetch response types: %w", err)
 	}
 
-	// Classify the issue using DeepSeek AI
-	classification, err := s.deepseek.ClassifyCustomerIssue(ctx, decision.Title, decision.Description, responseTypes)
+	// Classify the issue using the configured AI provider
+	classification, err := s.provider.ClassifyCustomerIssue(ctx, decision.Title, decision.Description, responseTypes)
 	if err != nil {
 		return fmt.Errorf("AI classification failed: %w", err)
 	}
+	if classification.Provider == "" {
+		classification.Provider = s.provider.Name()
+	}
 
 	// Find matching response type
 	var matchedType *models.CustomerResponseType
@@ -57,7 +65,7 @@ func (s *Service) ClassifyDecision(ctx context.Context, decision *models.Custome
 	}
 
 	// Get stakeholder recommendations
-	recommendations, err := s.deepseek.RecommendStakeholders(ctx, *decision, matchedType)
+	recommendations, err := s.provider.RecommendStakeholders(ctx, *decision, matchedType)
 	if err != nil {
 		return fmt.Errorf("stakeholder recommendation failed: %w", err)
 	}
@@ -105,14 +113,94 @@ func (s *Service) EnhanceDecisionWithAI(ctx context.Context, decisionID string)
 
 // SuggestResponseOptions generates AI-suggested response options
 func (s *Service) SuggestResponseOptions(ctx context.Context, decision models.CustomerDecision) ([]models.ResponseOption, error) {
-	// This is a placeholder for future AI-generated response options
-	// For now, return empty array to indicate no AI-generated options
-	return []models.ResponseOption{}, nil
+	// Prefer the AI classification, falling back to the type chosen at creation
+	typeCode := decision.DecisionType
+	if decision.AIClassification != nil && decision.AIClassification.DecisionType != "" {
+		typeCode = decision.AIClassification.DecisionType
+	}
+
+	var responseType *models.CustomerResponseType
+	var matchedType models.CustomerResponseType
+	err := s.db.GetContext(ctx, &matchedType, `
+		SELECT id, type_code, type_name, description, typical_resolution_time_hours,
+		       requires_escalation, default_stakeholders, ai_classification_keywords
+		FROM customer_response_types
+		WHERE type_code = $1
+	`, typeCode)
+	if err == nil {
+		responseType = &matchedType
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to fetch response type: %w", err)
+	}
+
+	options, err := s.provider.SuggestResponseOptions(ctx, decision, responseType)
+	if err != nil {
+		return nil, fmt.Errorf("AI option generation failed: %w", err)
+	}
+	if len(options) < MinResponseOptions {
+		return nil, fmt.Errorf("AI returned %d response options, need at least %d", len(options), MinResponseOptions)
+	}
+	if len(options) > MaxResponseOptions {
+		options = options[:MaxResponseOptions]
+	}
+
+	now := time.Now()
+	for i := range options {
+		options[i].ID = uuid.New()
+		options[i].DecisionID = decision.ID
+		options[i].AIGenerated = true
+		options[i].CreatedAt = now
+	}
+
+	return options, nil
+}
+
+// SaveResponseOptions persists AI-suggested options alongside the decision's existing options
+func (s *Service) SaveResponseOptions(ctx context.Context, options []models.ResponseOption, createdBy uuid.UUID) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i := range options {
+		options[i].CreatedBy = &createdBy
+		_, err := tx.NamedExecContext(ctx, `
+			INSERT INTO response_options (id, decision_id, title, description, financial_cost, implementation_effort, risk_level, ai_generated, created_by, created_at)
+			VALUES (:id, :decision_id, :title, :description, :financial_cost, :implementation_effort, :risk_level, :ai_generated, :created_by, :created_at)
+		`, options[i])
+		if err != nil {
+			return fmt.Errorf("failed to save response option: %w", err)
+		}
+	}
+
+	return tx.Commit()
 }
 
 // GenerateResponseDraft creates an AI-powered customer response draft
 func (s *Service) GenerateResponseDraft(ctx context.Context, req ResponseDraftRequest) (*ResponseDraft, error) {
-	return s.deepseek.GenerateResponseDraft(ctx, req)
+	draft, err := s.provider.GenerateResponseDraft(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Provider == "" {
+		draft.Provider = s.provider.Name()
+	}
+
+	return draft, nil
+}
+
+// StreamResponseDraft creates a response draft, calling onDelta as text is generated
+func (s *Service) StreamResponseDraft(ctx context.Context, req ResponseDraftRequest, onDelta func(string) error) (*ResponseDraft, error) {
+	draft, err := streamOrGenerate(ctx, s.provider, req, onDelta)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Provider == "" {
+		draft.Provider = s.provider.Name()
+	}
+
+	return draft, nil
 }
 
 // ValidateClassificationAccuracy checks if AI classification matches actual outcome
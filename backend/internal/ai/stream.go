@@ -0,0 +1,230 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// draftMetadataDelimiter separates the streamed letter from its trailing JSON metadata
+const draftMetadataDelimiter = "---METADATA---"
+
+// StreamingProvider is implemented by providers that can stream response drafts token by token
+type StreamingProvider interface {
+	Provider
+
+	// StreamResponseDraft calls onDelta with each new piece of draft text and returns the complete draft
+	StreamResponseDraft(ctx context.Context, req ResponseDraftRequest, onDelta func(string) error) (*ResponseDraft, error)
+}
+
+// streamOrGenerate streams with providers that support it and otherwise emits the whole draft as one delta
+func streamOrGenerate(ctx context.Context, provider Provider, req ResponseDraftRequest, onDelta func(string) error) (*ResponseDraft, error) {
+	if streaming, ok := provider.(StreamingProvider); ok {
+		return streaming.StreamResponseDraft(ctx, req, onDelta)
+	}
+
+	draft, err := provider.GenerateResponseDraft(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := onDelta(draft.DraftContent); err != nil {
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+// buildStreamingDraftPrompt asks for the letter as plain text followed by JSON metadata,
+// so the text can be forwarded to the UI as it is generated
+func buildStreamingDraftPrompt(req ResponseDraftRequest) string {
+	customerEmail := ""
+	if req.CustomerContext.CustomerEmail != nil {
+		customerEmail = *req.CustomerContext.CustomerEmail
+	}
+
+	return fmt.Sprintf(`You are a professional customer service communication assistant. Generate a customer response draft based on the team's decision.
+
+Customer Context:
+- Name: %s
+- Email: %s
+- Tier: %s (%s)
+- Relationship: %d months
+- NPS Score: %s
+
+Issue Details:
+- Title: %s
+- Description: %s
+- Urgency: %d (%s)
+- Financial Impact: $%.2f
+
+Team Decision:
+- Selected Response: %s
+- Reasoning: %s
+- Team Consensus: %.2f (0.0-1.0 scale)
+
+Selected Option Details:
+%s
+
+Communication Preferences:
+- Tone: %s
+- Channel: %s
+- Urgency: %s
+
+%s
+
+Output format:
+1. First write ONLY the full customer response text (150-300 words), as plain text without markdown.
+2. Then output a line containing exactly %s
+3. Then output JSON:
+{
+  "key_points": ["Key point 1", "Key point 2", "Key point 3"],
+  "tone": "%s",
+  "estimated_satisfaction_impact": "positive|neutral|negative",
+  "follow_up_recommendations": ["Recommendation 1", "Recommendation 2"]
+}`,
+		req.CustomerContext.CustomerName,
+		customerEmail,
+		req.CustomerContext.CustomerTier,
+		req.CustomerContext.CustomerTierDetailed,
+		req.CustomerContext.RelationshipDurationMonths,
+		formatNPSScore(req.CustomerContext.NPSScore),
+		req.CustomerContext.Title,
+		req.CustomerContext.Description,
+		req.CustomerContext.UrgencyLevel,
+		req.CustomerContext.UrgencyLevelDetailed,
+		safeFloat(req.CustomerContext.FinancialImpact),
+		req.DecisionOutcome.SelectedOptionTitle,
+		req.DecisionOutcome.Reasoning,
+		req.DecisionOutcome.TeamConsensus,
+		formatSelectedOption(req.SelectedOption),
+		req.CommunicationPreferences.Tone,
+		req.CommunicationPreferences.Channel,
+		req.CommunicationPreferences.Urgency,
+		getToneInstructions(req.CommunicationPreferences.Tone),
+		draftMetadataDelimiter,
+		req.CommunicationPreferences.Tone)
+}
+
+// draftStreamParser turns raw model output chunks into draft text deltas and a final draft.
+// It hides <think> reasoning blocks and everything after the metadata delimiter.
+type draftStreamParser struct {
+	onDelta  func(string) error
+	pending  strings.Builder // text not yet forwarded
+	content  strings.Builder // text forwarded so far
+	metadata strings.Builder // raw text after the delimiter
+	started  bool            // leading whitespace/think block has been skipped
+	inMeta   bool
+}
+
+func newDraftStreamParser(onDelta func(string) error) *draftStreamParser {
+	return &draftStreamParser{onDelta: onDelta}
+}
+
+// Write consumes a chunk of model output
+func (p *draftStreamParser) Write(chunk string) error {
+	if p.inMeta {
+		p.metadata.WriteString(chunk)
+		return nil
+	}
+
+	p.pending.WriteString(chunk)
+	buffered := p.pending.String()
+
+	if !p.started {
+		trimmed := strings.TrimLeft(buffered, " \t\r\n")
+		if trimmed == "" || strings.HasPrefix("<think>", trimmed) {
+			return nil // not enough output yet to know whether a think block follows
+		}
+		if strings.HasPrefix(trimmed, "<think>") {
+			end := strings.Index(trimmed, "</think>")
+			if end < 0 {
+				return nil
+			}
+			trimmed = strings.TrimLeft(trimmed[end+len("</think>"):], " \t\r\n")
+			if trimmed == "" {
+				p.pending.Reset()
+				return nil
+			}
+		}
+		p.started = true
+		buffered = trimmed
+	}
+
+	if idx := strings.Index(buffered, draftMetadataDelimiter); idx >= 0 {
+		p.inMeta = true
+		p.metadata.WriteString(buffered[idx+len(draftMetadataDelimiter):])
+		p.pending.Reset()
+		return p.emit(strings.TrimRight(buffered[:idx], " \t\r\n"))
+	}
+
+	// Hold back a tail that could be the start of the delimiter
+	hold := 0
+	for n := len(draftMetadataDelimiter) - 1; n > 0; n-- {
+		if n <= len(buffered) && strings.HasSuffix(buffered, draftMetadataDelimiter[:n]) {
+			hold = n
+			break
+		}
+	}
+
+	// Trailing whitespace is held too, so the text before the delimiter ends cleanly
+	ready := strings.TrimRight(buffered[:len(buffered)-hold], " \t\r\n")
+
+	p.pending.Reset()
+	p.pending.WriteString(buffered[len(ready):])
+	return p.emit(ready)
+}
+
+func (p *draftStreamParser) emit(text string) error {
+	if text == "" {
+		return nil
+	}
+	p.content.WriteString(text)
+	return p.onDelta(text)
+}
+
+// Finish flushes remaining text and parses the metadata into the final draft
+func (p *draftStreamParser) Finish(tone string) (*ResponseDraft, error) {
+	if !p.inMeta {
+		rest := p.pending.String()
+		if !p.started && strings.HasPrefix(strings.TrimLeft(rest, " \t\r\n"), "<think>") {
+			rest = "" // unterminated reasoning block, no draft text
+		}
+		if err := p.emit(rest); err != nil {
+			return nil, err
+		}
+		p.pending.Reset()
+	}
+
+	content := strings.TrimSpace(p.content.String())
+	if content == "" {
+		return nil, fmt.Errorf("model returned an empty draft")
+	}
+
+	draft := &ResponseDraft{
+		DraftContent:                content,
+		Tone:                        tone,
+		EstimatedSatisfactionImpact: "neutral",
+	}
+
+	if raw := strings.TrimSpace(p.metadata.String()); raw != "" {
+		var meta ResponseDraft
+		if err := json.Unmarshal([]byte(extractJSON(raw)), &meta); err == nil {
+			draft.KeyPoints = meta.KeyPoints
+			draft.FollowUpRecommendations = meta.FollowUpRecommendations
+			draft.EstimatedSatisfactionImpact = normalizeSatisfactionImpact(meta.EstimatedSatisfactionImpact)
+		}
+	}
+
+	return draft, nil
+}
+
+// normalizeSatisfactionImpact maps model output onto the values allowed by response_drafts
+func normalizeSatisfactionImpact(impact string) string {
+	switch impact = strings.ToLower(strings.TrimSpace(impact)); impact {
+	case "very_positive", "positive", "neutral", "negative", "very_negative":
+		return impact
+	default:
+		return "neutral"
+	}
+}
@@ -0,0 +1,142 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// collectDeltas feeds chunks through a parser and returns the forwarded deltas
+func collectDeltas(t *testing.T, chunks []string) ([]string, *ResponseDraft) {
+	t.Helper()
+
+	var deltas []string
+	parser := newDraftStreamParser(func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	for _, chunk := range chunks {
+		assert.NoError(t, parser.Write(chunk))
+	}
+
+	draft, err := parser.Finish("professional_empathetic")
+	assert.NoError(t, err)
+	return deltas, draft
+}
+
+func TestDraftStreamParserSplitsMetadata(t *testing.T) {
+	chunks := []string{"Dear Acme,\n\nWe are ", "sorry for the outage.", "\n---META", "DATA---\n", `{"key_points": ["Apology"], `, `"estimated_satisfaction_impact": "positive"}`}
+
+	deltas, draft := collectDeltas(t, chunks)
+
+	assert.Equal(t, "Dear Acme,\n\nWe are sorry for the outage.", strings.Join(deltas, ""))
+	assert.NotContains(t, strings.Join(deltas, ""), "---")
+	assert.Equal(t, "Dear Acme,\n\nWe are sorry for the outage.", draft.DraftContent)
+	assert.Equal(t, []string{"Apology"}, draft.KeyPoints)
+	assert.Equal(t, "positive", draft.EstimatedSatisfactionImpact)
+	assert.Equal(t, "professional_empathetic", draft.Tone)
+}
+
+func TestDraftStreamParserSkipsThinkBlock(t *testing.T) {
+	chunks := []string{"<thi", "nk>reasoning about the customer", "</think>\n\n", "Hello Acme, thanks for your patience."}
+
+	deltas, draft := collectDeltas(t, chunks)
+
+	assert.Equal(t, "Hello Acme, thanks for your patience.", strings.Join(deltas, ""))
+	assert.Equal(t, "neutral", draft.EstimatedSatisfactionImpact, "missing metadata falls back to neutral")
+}
+
+func TestDraftStreamParserRejectsEmptyDraft(t *testing.T) {
+	parser := newDraftStreamParser(func(string) error { return nil })
+	assert.NoError(t, parser.Write("<think>never finished"))
+
+	_, err := parser.Finish("concise_factual")
+	assert.Error(t, err)
+}
+
+func TestDeepSeekStreamResponseDraft(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req DeepSeekRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.True(t, req.Stream, "request should ask for a stream")
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, piece := range []string{"Dear customer, ", "your refund is approved.", "\n---METADATA---\n", `{"key_points": ["Refund approved"]}`} {
+			payload, _ := json.Marshal(map[string]interface{}{
+				"choices": []map[string]interface{}{{"delta": map[string]string{"content": piece}}},
+			})
+			fmt.Fprintf(w, "data: %s\n\n", payload)
+			w.(http.Flusher).Flush()
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := NewDeepSeekClient(DeepSeekConfig{APIKey: "test", BaseURL: server.URL})
+
+	var streamed strings.Builder
+	draft, err := client.StreamResponseDraft(context.Background(), ResponseDraftRequest{
+		CommunicationPreferences: CommunicationPreferences{Tone: "concise_factual"},
+	}, func(delta string) error {
+		streamed.WriteString(delta)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Dear customer, your refund is approved.", streamed.String())
+	assert.Equal(t, []string{"Refund approved"}, draft.KeyPoints)
+}
+
+func TestOllamaStreamResponseDraft(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.True(t, req.Stream, "request should ask for a stream")
+
+		encoder := json.NewEncoder(w)
+		for _, piece := range []string{"<think>plan</think>", "Hi there, ", "we have credited your account."} {
+			_ = encoder.Encode(OllamaResponse{Response: piece})
+		}
+		_ = encoder.Encode(OllamaResponse{Done: true})
+	}))
+	defer server.Close()
+
+	client := NewOllamaClientWithConfig(OllamaConfig{BaseURL: server.URL})
+
+	var streamed strings.Builder
+	draft, err := client.StreamResponseDraft(context.Background(), ResponseDraftRequest{}, func(delta string) error {
+		streamed.WriteString(delta)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Hi there, we have credited your account.", streamed.String())
+	assert.Equal(t, streamed.String(), draft.DraftContent)
+}
+
+func TestFailoverStreamDoesNotSwitchAfterFirstDelta(t *testing.T) {
+	secondary := &fakeProvider{name: ProviderOllama}
+	chain := NewFailoverProvider([]Provider{&failingStreamProvider{fakeProvider{name: ProviderDeepSeek}}, secondary}, FailoverConfig{})
+
+	_, err := chain.StreamResponseDraft(context.Background(), ResponseDraftRequest{}, func(string) error { return nil })
+	assert.Error(t, err)
+	assert.Equal(t, 0, secondary.calls, "must not fail over once text has been streamed")
+}
+
+// failingStreamProvider streams one delta and then fails
+type failingStreamProvider struct {
+	fakeProvider
+}
+
+func (p *failingStreamProvider) StreamResponseDraft(ctx context.Context, req ResponseDraftRequest, onDelta func(string) error) (*ResponseDraft, error) {
+	if err := onDelta("Dear"); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("connection reset")
+}
@@ -0,0 +1,78 @@
+package analytics
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"choseby-backend/internal/csvutil"
+)
+
+// Export formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// ErrUnknownFormat is returned for an export format that does not exist
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// ExportFormats lists the supported export formats with their content types
+func ExportFormats() map[string]string {
+	return map[string]string{
+		FormatCSV:  "text/csv; charset=utf-8",
+		FormatJSON: "application/json; charset=utf-8",
+	}
+}
+
+// Write encodes the report in the given format
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatJSON:
+		return json.NewEncoder(w).Encode(r)
+	case FormatCSV:
+		return r.writeCSV(w)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+// writeCSV writes one table per section, each headed by a "# Name" row
+func (r *Report) writeCSV(w io.Writer) error {
+	s := r.Summary
+	summary := [][]interface{}{
+		{"metric", "value"},
+		{"team", r.TeamName},
+		{"from", r.Range.From.Format(time.RFC3339)},
+		{"to", r.Range.To.Format(time.RFC3339)},
+		{"generated_at", r.GeneratedAt.Format(time.RFC3339)},
+		{"total_decisions", s.TotalDecisions},
+		{"resolved_decisions", s.ResolvedDecisions},
+		{"avg_resolution_hours", s.AvgResolutionHours},
+		{"avg_satisfaction", s.AvgSatisfaction},
+		{"avg_evaluation_hours", s.AvgEvaluationHours},
+		{"participation_rate", s.ParticipationRate},
+	}
+
+	members := [][]interface{}{{"member_id", "name", "role", "evaluations_count", "decisions_evaluated", "avg_response_time", "participation_rate"}}
+	for _, m := range r.Members {
+		members = append(members, []interface{}{m.MemberID.String(), m.Name, m.Role, m.EvaluationsCount, m.DecisionsEvaluated, m.AvgResponseTime, m.ParticipationRate})
+	}
+
+	breakdown := func(key string, rows []Breakdown) [][]interface{} {
+		table := [][]interface{}{{key, "count"}}
+		for _, b := range rows {
+			table = append(table, []interface{}{b.Key, b.Count})
+		}
+		return table
+	}
+
+	return csvutil.WriteTables(w, []csvutil.Table{
+		{Name: "Summary", Rows: summary},
+		{Name: "Team Performance", Rows: members},
+		{Name: "Decision Types", Rows: breakdown("decision_type", r.DecisionTypes)},
+		{Name: "Urgency", Rows: breakdown("urgency_level", r.Urgency)},
+		{Name: "Customer Tiers", Rows: breakdown("customer_tier", r.CustomerTiers)},
+	}, 2)
+}
@@ -0,0 +1,92 @@
+package analytics
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleReport() *Report {
+	return &Report{
+		TeamID:      uuid.New(),
+		TeamName:    "Support",
+		Range:       Range{From: time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC), To: time.Date(2025, 10, 27, 0, 0, 0, 0, time.UTC)},
+		GeneratedAt: time.Date(2025, 10, 27, 1, 0, 0, 0, time.UTC),
+		Summary:     Summary{TotalDecisions: 4, ResolvedDecisions: 3, AvgResolutionHours: 12.5, AvgSatisfaction: 4.25, ParticipationRate: 62.5},
+		DecisionTypes: []Breakdown{
+			{Key: "refund_request", Count: 3},
+			{Key: "=HYPERLINK(\"http://evil\")", Count: 1},
+		},
+		Urgency:       []Breakdown{},
+		CustomerTiers: []Breakdown{{Key: "enterprise", Count: 4}},
+		Members: []MemberPerformance{
+			{MemberID: uuid.New(), Name: "Sam | Lee", Role: "csm", EvaluationsCount: 6, DecisionsEvaluated: 3, AvgResponseTime: 5.5, ParticipationRate: 75},
+			{MemberID: uuid.New(), Name: "<script>alert(1)</script>", Role: "support_agent"},
+		},
+	}
+}
+
+func TestWriteCSVSections(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, sampleReport().Write(&buf, FormatCSV))
+
+	reader := csv.NewReader(&buf)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	require.NoError(t, err)
+
+	sections := map[string][][]string{}
+	var current string
+	for _, record := range records {
+		if strings.HasPrefix(record[0], "# ") {
+			current = strings.TrimPrefix(record[0], "# ")
+			continue
+		}
+		sections[current] = append(sections[current], record)
+	}
+
+	assert.Contains(t, sections["Summary"], []string{"avg_resolution_hours", "12.50"})
+	assert.Contains(t, sections["Summary"], []string{"from", "2025-10-20T00:00:00Z"})
+	require.Len(t, sections["Team Performance"], 3)
+	assert.Equal(t, []string{"Sam | Lee", "csm", "6", "3", "5.50", "75.00"}, sections["Team Performance"][1][1:])
+	// Values a spreadsheet would evaluate are kept as text
+	assert.Equal(t, []string{"'=HYPERLINK(\"http://evil\")", "1"}, sections["Decision Types"][2])
+	assert.Equal(t, [][]string{{"urgency_level", "count"}}, sections["Urgency"])
+	assert.Equal(t, [][]string{{"customer_tier", "count"}, {"enterprise", "4"}}, sections["Customer Tiers"])
+}
+
+func TestWriteJSONAndUnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, sampleReport().Write(&buf, FormatJSON))
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "Support", decoded["team_name"])
+	assert.Len(t, decoded["team_performance"], 2)
+
+	assert.ErrorIs(t, sampleReport().Write(&buf, "pdf"), ErrUnknownFormat)
+}
+
+func TestRenderEscapesValues(t *testing.T) {
+	report := sampleReport()
+
+	markdown, err := report.Markdown()
+	require.NoError(t, err)
+	assert.Contains(t, markdown, "2025-10-20 to 2025-10-26")
+	assert.Contains(t, markdown, "| Average resolution time | 12.5 h |")
+	assert.Contains(t, markdown, `| Sam \| Lee | csm | 6 | 3 | 5.5 h | 75.0% |`)
+	assert.Contains(t, markdown, "## Urgency\n\nNo decisions.")
+
+	html, err := report.HTML()
+	require.NoError(t, err)
+	assert.NotContains(t, html, "<script>")
+	assert.Contains(t, html, "&lt;script&gt;alert(1)&lt;/script&gt;")
+	assert.Contains(t, html, "<td>refund_request</td><td>3</td>")
+}
@@ -0,0 +1,122 @@
+package analytics
+
+import (
+	htmltemplate "html/template"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const markdownReport = `# {{.TeamName}} analytics report
+
+{{.Range}}
+
+## Summary
+
+| Metric | Value |
+| --- | --- |
+| Decisions | {{.Summary.TotalDecisions}} |
+| Resolved | {{.Summary.ResolvedDecisions}} |
+| Average resolution time | {{hours .Summary.AvgResolutionHours}} |
+| Average customer satisfaction | {{number .Summary.AvgSatisfaction}} |
+| Average time to evaluate | {{hours .Summary.AvgEvaluationHours}} |
+| Evaluation participation | {{number .Summary.ParticipationRate}}% |
+
+## Team performance
+
+| Member | Role | Evaluations | Decisions evaluated | Avg. response | Participation |
+| --- | --- | --- | --- | --- | --- |
+{{range .Members}}| {{cell .Name}} | {{cell .Role}} | {{.EvaluationsCount}} | {{.DecisionsEvaluated}} | {{hours .AvgResponseTime}} | {{number .ParticipationRate}}% |
+{{end}}
+## Decision types
+{{template "breakdown" .DecisionTypes}}
+## Urgency
+{{template "breakdown" .Urgency}}
+## Customer tiers
+{{template "breakdown" .CustomerTiers}}
+{{define "breakdown"}}
+{{if .}}| Value | Decisions |
+| --- | --- |
+{{range .}}| {{cell .Key}} | {{.Count}} |
+{{end}}{{else}}No decisions.
+{{end}}{{end}}`
+
+const htmlReport = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.TeamName}} analytics report</title></head>
+<body style="font-family: sans-serif">
+<h1>{{.TeamName}} analytics report</h1>
+<p>{{.Range}}</p>
+<h2>Summary</h2>
+<table border="1" cellpadding="4" cellspacing="0">
+<tr><td>Decisions</td><td>{{.Summary.TotalDecisions}}</td></tr>
+<tr><td>Resolved</td><td>{{.Summary.ResolvedDecisions}}</td></tr>
+<tr><td>Average resolution time</td><td>{{hours .Summary.AvgResolutionHours}}</td></tr>
+<tr><td>Average customer satisfaction</td><td>{{number .Summary.AvgSatisfaction}}</td></tr>
+<tr><td>Average time to evaluate</td><td>{{hours .Summary.AvgEvaluationHours}}</td></tr>
+<tr><td>Evaluation participation</td><td>{{number .Summary.ParticipationRate}}%</td></tr>
+</table>
+<h2>Team performance</h2>
+<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Member</th><th>Role</th><th>Evaluations</th><th>Decisions evaluated</th><th>Avg. response</th><th>Participation</th></tr>
+{{range .Members}}<tr><td>{{.Name}}</td><td>{{.Role}}</td><td>{{.EvaluationsCount}}</td><td>{{.DecisionsEvaluated}}</td><td>{{hours .AvgResponseTime}}</td><td>{{number .ParticipationRate}}%</td></tr>
+{{end}}</table>
+<h2>Decision types</h2>
+{{template "breakdown" .DecisionTypes}}
+<h2>Urgency</h2>
+{{template "breakdown" .Urgency}}
+<h2>Customer tiers</h2>
+{{template "breakdown" .CustomerTiers}}
+</body>
+</html>
+{{define "breakdown"}}{{if .}}<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Value</th><th>Decisions</th></tr>
+{{range .}}<tr><td>{{.Key}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>{{else}}<p>No decisions.</p>{{end}}{{end}}`
+
+// String describes the range by day when it falls on day boundaries, e.g. "2025-10-20 to 2025-10-26"
+func (r Range) String() string {
+	midnight := func(t time.Time) bool { return t.Equal(t.Truncate(24 * time.Hour)) }
+	if midnight(r.From) && midnight(r.To) {
+		return r.From.Format("2006-01-02") + " to " + r.To.AddDate(0, 0, -1).Format("2006-01-02")
+	}
+	return r.From.Format(time.RFC3339) + " to " + r.To.Format(time.RFC3339)
+}
+
+func renderFuncs() map[string]interface{} {
+	return map[string]interface{}{
+		"hours":  func(h float64) string { return strconv.FormatFloat(h, 'f', 1, 64) + " h" },
+		"number": func(v float64) string { return strconv.FormatFloat(v, 'f', 1, 64) },
+		// Pipes and line breaks would split a Markdown table cell
+		"cell": func(s string) string {
+			return strings.NewReplacer("|", `\|`, "\n", " ", "\r", " ").Replace(s)
+		},
+	}
+}
+
+// Markdown renders the report as a Markdown summary, also used as the plain-text part of report emails
+func (r *Report) Markdown() (string, error) {
+	tmpl, err := template.New("report").Funcs(renderFuncs()).Parse(markdownReport)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, r); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// HTML renders the report as a standalone HTML page; member names and other values are escaped
+func (r *Report) HTML() (string, error) {
+	tmpl, err := htmltemplate.New("report").Funcs(renderFuncs()).Parse(htmlReport)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, r); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,235 @@
+// Package analytics builds a team's decision analytics over a date range, for export and scheduled reports.
+package analytics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// How often a team is sent a report
+const (
+	FrequencyNone    = "none"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+// ErrInvalidRange is returned for a date range that cannot be parsed or ends before it starts
+var ErrInvalidRange = errors.New("invalid date range")
+
+// Frequencies lists the valid report frequencies
+func Frequencies() []string {
+	return []string{FrequencyNone, FrequencyWeekly, FrequencyMonthly}
+}
+
+// Range is the half-open interval [From, To) of decision creation times a report covers
+type Range struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// ParseRange reads a range from from/to dates (YYYY-MM-DD or RFC 3339), falling back to the dashboard's
+// 7d/30d/90d periods counted back from now. A date-only to includes that whole day.
+func ParseRange(period, from, to string, now time.Time) (Range, error) {
+	r := Range{To: now}
+	if to != "" {
+		t, dateOnly, err := parseTime(to)
+		if err != nil {
+			return Range{}, ErrInvalidRange
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		r.To = t
+	}
+
+	if from != "" {
+		t, _, err := parseTime(from)
+		if err != nil {
+			return Range{}, ErrInvalidRange
+		}
+		r.From = t
+	} else {
+		days := map[string]int{"7d": 7, "30d": 30, "90d": 90}[period]
+		if days == 0 {
+			days = 30
+		}
+		r.From = r.To.AddDate(0, 0, -days)
+	}
+
+	if !r.From.Before(r.To) {
+		return Range{}, ErrInvalidRange
+	}
+	return r, nil
+}
+
+func parseTime(value string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+// ReportPeriod is the last full period before now: the previous Monday-to-Monday week, or the previous
+// calendar month, in UTC
+func ReportPeriod(frequency string, now time.Time) Range {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if frequency == FrequencyMonthly {
+		end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return Range{From: end.AddDate(0, -1, 0), To: end}
+	}
+	// Weekday counts from Sunday; weeks here start on Monday
+	end := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	return Range{From: end.AddDate(0, 0, -7), To: end}
+}
+
+// Report is a team's analytics over a range
+type Report struct {
+	TeamID        uuid.UUID           `json:"team_id"`
+	TeamName      string              `json:"team_name"`
+	Range         Range               `json:"range"`
+	GeneratedAt   time.Time           `json:"generated_at"`
+	Summary       Summary             `json:"summary"`
+	DecisionTypes []Breakdown         `json:"decision_types"`
+	Urgency       []Breakdown         `json:"urgency"`
+	CustomerTiers []Breakdown         `json:"customer_tiers"`
+	Members       []MemberPerformance `json:"team_performance"`
+}
+
+// Summary holds the dashboard's headline numbers
+type Summary struct {
+	TotalDecisions     int     `json:"total_decisions" db:"total_decisions"`
+	ResolvedDecisions  int     `json:"resolved_decisions" db:"resolved_decisions"`
+	AvgResolutionHours float64 `json:"avg_resolution_hours" db:"avg_resolution_hours"`
+	AvgSatisfaction    float64 `json:"avg_satisfaction" db:"avg_satisfaction"`
+	AvgEvaluationHours float64 `json:"avg_evaluation_hours" db:"avg_evaluation_hours"` // from decision creation to each evaluation
+	ParticipationRate  float64 `json:"participation_rate"`                             // percent of member × decision pairs evaluated
+}
+
+// Breakdown counts decisions sharing a value, such as a decision type or urgency level
+type Breakdown struct {
+	Key   string `json:"key" db:"key"`
+	Count int    `json:"count" db:"count"`
+}
+
+// MemberPerformance is one active member's evaluation activity on the range's decisions
+type MemberPerformance struct {
+	MemberID           uuid.UUID `json:"member_id" db:"member_id"`
+	Name               string    `json:"name" db:"name"`
+	Role               string    `json:"role" db:"role"`
+	EvaluationsCount   int       `json:"evaluations_count" db:"evaluations_count"`
+	DecisionsEvaluated int       `json:"decisions_evaluated" db:"decisions_evaluated"`
+	AvgResponseTime    float64   `json:"avg_response_time" db:"avg_response_time"` // hours
+	ParticipationRate  float64   `json:"participation_rate"`                       // percent of the range's decisions
+}
+
+// Load builds the team's report over the range from decisions created within it
+func Load(ctx context.Context, db sqlx.QueryerContext, teamID uuid.UUID, r Range) (*Report, error) {
+	report := &Report{TeamID: teamID, Range: r, GeneratedAt: time.Now().UTC()}
+
+	if err := sqlx.GetContext(ctx, db, &report.TeamName, `SELECT name FROM teams WHERE id = $1`, teamID); err != nil {
+		return nil, err
+	}
+
+	var pairs struct {
+		Members   int `db:"members"`
+		Evaluated int `db:"evaluated"`
+	}
+	if err := sqlx.GetContext(ctx, db, &report.Summary, `
+		SELECT
+			COUNT(DISTINCT cd.id) AS total_decisions,
+			COUNT(DISTINCT cd.id) FILTER (WHERE cd.status = 'resolved') AS resolved_decisions,
+			COALESCE(AVG(ot.time_to_resolution_hours), 0) AS avg_resolution_hours,
+			COALESCE(AVG(ot.customer_satisfaction_score::float), 0) AS avg_satisfaction,
+			COALESCE((
+				SELECT AVG(EXTRACT(EPOCH FROM (e.created_at - d.created_at)) / 3600)
+				FROM evaluations e JOIN customer_decisions d ON d.id = e.decision_id
+				WHERE d.team_id = $1 AND d.created_at >= $2 AND d.created_at < $3 AND d.deleted_at IS NULL
+			), 0) AS avg_evaluation_hours
+		FROM customer_decisions cd
+		LEFT JOIN outcome_tracking ot ON ot.decision_id = cd.id
+		WHERE cd.team_id = $1 AND cd.created_at >= $2 AND cd.created_at < $3 AND cd.deleted_at IS NULL
+	`, teamID, r.From, r.To); err != nil {
+		return nil, err
+	}
+	if err := sqlx.GetContext(ctx, db, &pairs, `
+		SELECT
+			(SELECT COUNT(*) FROM team_members WHERE team_id = $1 AND is_active = true) AS members,
+			(SELECT COUNT(DISTINCT (e.evaluator_id, e.decision_id))
+				FROM evaluations e JOIN customer_decisions cd ON cd.id = e.decision_id
+				WHERE cd.team_id = $1 AND cd.created_at >= $2 AND cd.created_at < $3 AND cd.deleted_at IS NULL
+			) AS evaluated
+	`, teamID, r.From, r.To); err != nil {
+		return nil, err
+	}
+	report.Summary.ParticipationRate = percent(pairs.Evaluated, pairs.Members*report.Summary.TotalDecisions)
+
+	breakdowns := []struct {
+		dst    *[]Breakdown
+		column string
+		order  string
+	}{
+		{&report.DecisionTypes, "decision_type", "count DESC, key"},
+		{&report.Urgency, "urgency_level::text", "key"},
+		{&report.CustomerTiers, "customer_tier", "count DESC, key"},
+	}
+	for _, b := range breakdowns {
+		*b.dst = []Breakdown{}
+		// Column and order come from the list above, never from input
+		if err := sqlx.SelectContext(ctx, db, b.dst, `
+			SELECT COALESCE(`+b.column+`, '') AS key, COUNT(*) AS count
+			FROM customer_decisions
+			WHERE team_id = $1 AND created_at >= $2 AND created_at < $3 AND deleted_at IS NULL
+			GROUP BY 1
+			ORDER BY `+b.order, teamID, r.From, r.To); err != nil {
+			return nil, err
+		}
+	}
+
+	members, err := LoadMemberPerformance(ctx, db, teamID, r, report.Summary.TotalDecisions)
+	if err != nil {
+		return nil, err
+	}
+	report.Members = members
+	return report, nil
+}
+
+// LoadMemberPerformance reads each active member's evaluations of the decisions created in the range;
+// decisions is how many there were, for the participation rate
+func LoadMemberPerformance(ctx context.Context, db sqlx.QueryerContext, teamID uuid.UUID, r Range, decisions int) ([]MemberPerformance, error) {
+	members := []MemberPerformance{}
+	if err := sqlx.SelectContext(ctx, db, &members, `
+		SELECT
+			tm.id AS member_id,
+			tm.name,
+			tm.role,
+			COUNT(cd.id) AS evaluations_count,
+			COUNT(DISTINCT cd.id) AS decisions_evaluated,
+			COALESCE(AVG(EXTRACT(EPOCH FROM (e.created_at - cd.created_at)) / 3600), 0) AS avg_response_time
+		FROM team_members tm
+		LEFT JOIN evaluations e ON e.evaluator_id = tm.id
+		LEFT JOIN customer_decisions cd ON cd.id = e.decision_id AND cd.team_id = tm.team_id
+			AND cd.created_at >= $2 AND cd.created_at < $3 AND cd.deleted_at IS NULL
+		WHERE tm.team_id = $1 AND tm.is_active = true
+		GROUP BY tm.id, tm.name, tm.role
+		ORDER BY evaluations_count DESC, tm.name
+	`, teamID, r.From, r.To); err != nil {
+		return nil, err
+	}
+	for i := range members {
+		members[i].ParticipationRate = percent(members[i].DecisionsEvaluated, decisions)
+	}
+	return members, nil
+}
+
+func percent(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole) * 100
+}
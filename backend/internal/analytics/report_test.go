@@ -0,0 +1,57 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRange(t *testing.T) {
+	now := time.Date(2025, 10, 30, 15, 0, 0, 0, time.UTC)
+
+	r, err := ParseRange("", "", "", now)
+	require.NoError(t, err)
+	assert.Equal(t, Range{From: now.AddDate(0, 0, -30), To: now}, r)
+
+	r, err = ParseRange("7d", "", "", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -7), r.From)
+
+	// A date-only end includes that whole day
+	r, err = ParseRange("7d", "2025-10-01", "2025-10-15", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), r.From)
+	assert.Equal(t, time.Date(2025, 10, 16, 0, 0, 0, 0, time.UTC), r.To)
+	assert.Equal(t, "2025-10-01 to 2025-10-15", r.String())
+
+	r, err = ParseRange("", "2025-10-01T08:00:00Z", "2025-10-01T20:00:00Z", now)
+	require.NoError(t, err)
+	assert.Equal(t, 12*time.Hour, r.To.Sub(r.From))
+
+	for _, bad := range [][2]string{{"yesterday", ""}, {"2025-10-15", "2025-10-01"}, {"2025-10-15T00:00:00Z", "2025-10-14"}} {
+		_, err = ParseRange("", bad[0], bad[1], now)
+		assert.ErrorIs(t, err, ErrInvalidRange, "from=%s to=%s", bad[0], bad[1])
+	}
+}
+
+func TestReportPeriod(t *testing.T) {
+	day := func(month time.Month, d int) time.Time { return time.Date(2025, month, d, 0, 0, 0, 0, time.UTC) }
+
+	// Thursday 30 October reports on the week of Monday 20 October
+	thursday := time.Date(2025, 10, 30, 9, 30, 0, 0, time.UTC)
+	assert.Equal(t, Range{From: day(10, 20), To: day(10, 27)}, ReportPeriod(FrequencyWeekly, thursday))
+
+	// On a Monday the week that just ended is reported; on Sunday it is still running
+	assert.Equal(t, Range{From: day(10, 20), To: day(10, 27)}, ReportPeriod(FrequencyWeekly, day(10, 27)))
+	assert.Equal(t, Range{From: day(10, 13), To: day(10, 20)}, ReportPeriod(FrequencyWeekly, day(10, 26)))
+
+	assert.Equal(t, Range{From: day(9, 1), To: day(10, 1)}, ReportPeriod(FrequencyMonthly, thursday))
+	january := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, Range{From: day(12, 1), To: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}, ReportPeriod(FrequencyMonthly, january))
+
+	// Periods are in UTC whatever the caller's zone
+	tokyo := time.FixedZone("JST", 9*60*60)
+	assert.Equal(t, Range{From: day(10, 13), To: day(10, 20)}, ReportPeriod(FrequencyWeekly, time.Date(2025, 10, 27, 8, 0, 0, 0, tokyo)))
+}
@@ -1,11 +1,20 @@
 package api
 
 import (
+	"context"
+	"log"
+	"time"
+
+	"choseby-backend/internal/ai"
+	"choseby-backend/internal/audit"
 	"choseby-backend/internal/auth"
 	"choseby-backend/internal/config"
 	"choseby-backend/internal/database"
 	"choseby-backend/internal/handlers"
+	"choseby-backend/internal/jobs"
+	"choseby-backend/internal/mailer"
 	"choseby-backend/internal/middleware"
+	"choseby-backend/internal/realtime"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
@@ -14,15 +23,15 @@ func SetupRouter(db *database.DB, cfg *config.Config) *gin.Engine {
 	router := gin.New()
 
 	// Middleware
-	router.Use(gin.Logger())
+	router.Use(middleware.Logger())
 	router.Use(gin.Recovery())
 
 	// CORS for customer response platform frontend
 	corsConfig := cors.DefaultConfig()
 	corsConfig.AllowOrigins = cfg.CORSOrigins
-	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID"}
-	corsConfig.ExposeHeaders = []string{"X-Request-ID", "X-Processing-Time"}
+	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID", "If-Match"}
+	corsConfig.ExposeHeaders = []string{"X-Request-ID", "X-Processing-Time", "ETag"}
 	router.Use(cors.New(corsConfig))
 
 	// Security middleware
@@ -30,6 +39,10 @@ func SetupRouter(db *database.DB, cfg *config.Config) *gin.Engine {
 	router.Use(middleware.RequestID())
 	router.Use(middleware.RateLimit(cfg.APIRateLimit, cfg.APIRateWindow))
 
+	// Append-only audit trail of every mutating request, hash-chained per team
+	auditLogger := audit.NewLogger(db)
+	router.Use(middleware.Audit(auditLogger))
+
 	// Initialize services
 	authService := auth.NewAuthService(
 		cfg.JWTSecret,
@@ -37,16 +50,41 @@ func SetupRouter(db *database.DB, cfg *config.Config) *gin.Engine {
 		cfg.RefreshTokenExpiration,
 	)
 
+	// Revoked access tokens, cached in memory and purged once the tokens expire
+	revocations := auth.NewRevocationStore(db, 0)
+
+	// Per-team WebSocket fan-out for live collaboration events
+	hub := realtime.NewHub(realtime.Config{
+		MaxConnections:    cfg.WSMaxConnections,
+		HeartbeatInterval: time.Duration(cfg.WSHeartbeatInterval) * time.Millisecond,
+	})
+
+	// Background jobs all work on the database; a tick without one would panic outside any request recovery
+	if db != nil {
+		startJobs(context.Background(), db, cfg, revocations, hub, auditLogger)
+	} else {
+		log.Println("Background jobs disabled: no database connection")
+	}
+
+	// AI provider selected by AI_PROVIDER (plus AI_FALLBACK_PROVIDERS), shared by all AI-backed handlers
+	aiProvider, err := buildAIProvider(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize AI provider: %v", err)
+	}
+	aiService := ai.NewAIService(aiProvider, db)
+
 	// Initialize handlers for customer response workflows
-	authHandler := handlers.NewAuthHandler(db, authService)
-	decisionsHandler := handlers.NewDecisionsHandler(db, authService)
-	evaluationsHandler := handlers.NewEvaluationsHandler(db, authService)
-	aiHandler := handlers.NewAIHandler(db, authService, cfg.DeepSeekAPIKey)
-	responseDraftHandler := handlers.NewResponseDraftHandler(db, authService, cfg.DeepSeekAPIKey)
-	outcomeHandler := handlers.NewOutcomeHandler(db, authService)
-	teamHandler := handlers.NewTeamHandler(db, authService)
+	authHandler := handlers.NewAuthHandler(db, authService, revocations)
+	decisionsHandler := handlers.NewDecisionsHandler(db, authService, cfg.DecisionRetentionDays, cfg.EvaluationTimeoutHours, hub)
+	evaluationsHandler := handlers.NewEvaluationsHandler(db, authService, hub)
+	aiHandler := handlers.NewAIHandler(db, authService, aiService)
+	responseDraftHandler := handlers.NewResponseDraftHandler(db, authService, aiService, hub)
+	outcomeHandler := handlers.NewOutcomeHandler(db, authService, hub)
+	teamHandler := handlers.NewTeamHandler(db, authService, cfg.MaxTeamMembers)
 	analyticsHandler := handlers.NewAnalyticsHandler(db, authService)
 	healthHandler := handlers.NewHealthHandler(db)
+	auditHandler := handlers.NewAuditHandler(db, auditLogger)
+	realtimeHandler := handlers.NewRealtimeHandler(hub, cfg.CORSOrigins)
 
 	// Public routes
 	public := router.Group("/api/v1")
@@ -58,73 +96,199 @@ func SetupRouter(db *database.DB, cfg *config.Config) *gin.Engine {
 		// Authentication endpoints for customer response teams
 		public.POST("/auth/register", authHandler.Register)
 		public.POST("/auth/login", authHandler.Login)
+		public.POST("/auth/refresh", authHandler.RefreshToken)
+
+		// Invitation acceptance (the path carries the invitation token)
+		public.POST("/team/invitations/:id/accept", teamHandler.AcceptInvitation)
 	}
 
-	// Auth routes (requires authentication)
+	// Auth routes (requires authentication; self-service, no team permission needed)
 	authRoutes := router.Group("/api/v1/auth")
-	authRoutes.Use(middleware.AuthRequired(authService))
+	authRoutes.Use(middleware.AuthRequired(authService, revocations))
 	{
 		authRoutes.GET("/me", authHandler.GetProfile)
 		authRoutes.PUT("/profile", authHandler.UpdateProfile)
 		authRoutes.POST("/logout", authHandler.Logout)
+		authRoutes.POST("/logout-all", authHandler.LogoutAll)
 	}
 
-	// Protected routes - customer response platform
+	// Real-time team events over WebSocket (token passed as ?token=)
+	router.GET("/api/v1/ws",
+		middleware.WebSocketAuth(authService, revocations),
+		middleware.TeamMember(db),
+		middleware.Permission(auth.PermDecisionView),
+		realtimeHandler.Connect)
+
+	// Protected routes - customer response platform.
+	// Every route names the permission it requires (see auth.RolePermissions).
 	protected := router.Group("/api/v1")
-	protected.Use(middleware.AuthRequired(authService))
+	protected.Use(middleware.AuthRequired(authService, revocations), middleware.TeamMember(db))
 	{
 		// Customer Decision Endpoints
 		decisions := protected.Group("/decisions")
 		{
-			decisions.GET("", decisionsHandler.GetTeamDecisions)
-			decisions.POST("", decisionsHandler.CreateDecision)
-			decisions.GET("/:id", decisionsHandler.GetDecision)
-			decisions.PUT("/:id", decisionsHandler.UpdateDecision)
-			decisions.DELETE("/:id", decisionsHandler.DeleteDecision)
+			decisions.GET("", middleware.Permission(auth.PermDecisionView), decisionsHandler.GetTeamDecisions)
+			decisions.POST("", middleware.Permission(auth.PermDecisionCreate), decisionsHandler.CreateDecision)
+			decisions.GET("/:id", middleware.Permission(auth.PermDecisionView), decisionsHandler.GetDecision)
+			decisions.PUT("/:id", middleware.Permission(auth.PermDecisionUpdate), decisionsHandler.UpdateDecision)
+			decisions.PATCH("/:id", middleware.Permission(auth.PermDecisionUpdate), decisionsHandler.UpdateDecision)
+			decisions.DELETE("/:id", middleware.Permission(auth.PermDecisionDelete), decisionsHandler.DeleteDecision)
+			decisions.POST("/:id/restore", middleware.Permission(auth.PermDecisionDelete), decisionsHandler.RestoreDecision)
+
+			// Lifecycle state machine
+			decisions.POST("/:id/transition", middleware.Permission(auth.PermDecisionUpdate), decisionsHandler.TransitionDecision)
+			decisions.GET("/:id/transitions", middleware.Permission(auth.PermDecisionView), decisionsHandler.GetTransitions)
 
 			// Decision criteria management
-			decisions.PUT("/:id/criteria", decisionsHandler.UpdateCriteria)
-			decisions.GET("/:id/criteria", decisionsHandler.GetCriteria)
+			decisions.PUT("/:id/criteria", middleware.Permission(auth.PermDecisionUpdate), decisionsHandler.UpdateCriteria)
+			decisions.GET("/:id/criteria", middleware.Permission(auth.PermDecisionView), decisionsHandler.GetCriteria)
 
 			// Response options management
-			decisions.PUT("/:id/options", decisionsHandler.UpdateOptions)
-			decisions.GET("/:id/options", decisionsHandler.GetOptions)
+			decisions.PUT("/:id/options", middleware.Permission(auth.PermDecisionUpdate), decisionsHandler.UpdateOptions)
+			decisions.GET("/:id/options", middleware.Permission(auth.PermDecisionView), decisionsHandler.GetOptions)
+
+			// Role-weighted evaluation (weights seeded from AI stakeholder recommendations or set manually)
+			decisions.GET("/:id/stakeholder-weighting", middleware.Permission(auth.PermDecisionView), decisionsHandler.GetStakeholderWeighting)
+			decisions.PUT("/:id/stakeholder-weighting", middleware.Permission(auth.PermDecisionUpdate), decisionsHandler.UpdateStakeholderWeighting)
+			decisions.DELETE("/:id/stakeholder-weighting", middleware.Permission(auth.PermDecisionUpdate), decisionsHandler.DeleteStakeholderWeighting)
+
+			// Delphi evaluation rounds (evaluation_mode "delphi")
+			decisions.GET("/:id/delphi", middleware.Permission(auth.PermEvaluationResults), decisionsHandler.GetDelphiRounds)
+			decisions.POST("/:id/delphi/rounds", middleware.Permission(auth.PermDecisionUpdate), decisionsHandler.CloseDelphiRound)
 
 			// Evaluation endpoints for anonymous team input
-			decisions.POST("/:id/evaluate", evaluationsHandler.SubmitEvaluation)
-			decisions.GET("/:id/results", evaluationsHandler.GetResults)
+			decisions.POST("/:id/evaluate", middleware.Permission(auth.PermEvaluationSubmit), evaluationsHandler.SubmitEvaluation)
+			decisions.GET("/:id/results", middleware.Permission(auth.PermEvaluationResults), evaluationsHandler.GetResults)
+			decisions.GET("/:id/results/sensitivity", middleware.Permission(auth.PermEvaluationResults), evaluationsHandler.GetSensitivity)
+			decisions.GET("/:id/evaluation-status", middleware.Permission(auth.PermDecisionView), evaluationsHandler.GetEvaluationStatus)
+			decisions.GET("/:id/summary", middleware.Permission(auth.PermEvaluationResults), evaluationsHandler.GetEvaluationSummary)
+			decisions.GET("/:id/export", middleware.Permission(auth.PermEvaluationExport), evaluationsHandler.ExportEvaluations)
 		}
 
 		// AI Integration Endpoints for customer issue classification
 		ai := protected.Group("/ai")
 		{
-			ai.POST("/classify", aiHandler.ClassifyIssue)
-			ai.POST("/generate-options", aiHandler.GenerateOptions)
+			ai.POST("/classify", middleware.Permission(auth.PermAIUse), aiHandler.ClassifyIssue)
+			ai.POST("/generate-options", middleware.Permission(auth.PermAIUse), aiHandler.GenerateOptions)
 		}
 
 		// Response Draft Endpoints for AI-generated customer responses
-		decisions.POST("/:id/generate-response-draft", responseDraftHandler.GenerateResponseDraft)
-		decisions.GET("/:id/drafts", responseDraftHandler.GetDrafts)
+		decisions.POST("/:id/generate-response-draft", middleware.Permission(auth.PermDraftGenerate), responseDraftHandler.GenerateResponseDraft)
+		decisions.GET("/:id/generate-response-draft/stream", middleware.Permission(auth.PermDraftGenerate), responseDraftHandler.StreamResponseDraft)
+		decisions.POST("/:id/generate-response-draft/stream", middleware.Permission(auth.PermDraftGenerate), responseDraftHandler.StreamResponseDraft)
+		decisions.GET("/:id/drafts", middleware.Permission(auth.PermDraftView), responseDraftHandler.GetDrafts)
 
 		// Outcome Tracking Endpoints for AI learning and continuous improvement
-		decisions.POST("/:id/outcome", outcomeHandler.RecordOutcome)
-		decisions.GET("/:id/outcome", outcomeHandler.GetOutcome)
-		decisions.POST("/:id/ai-feedback", outcomeHandler.RecordAIFeedback)
-		decisions.GET("/:id/ai-feedback", outcomeHandler.GetAIFeedback)
+		decisions.POST("/:id/outcome", middleware.Permission(auth.PermOutcomeRecord), outcomeHandler.RecordOutcome)
+		decisions.GET("/:id/outcome", middleware.Permission(auth.PermOutcomeView), outcomeHandler.GetOutcome)
+		decisions.POST("/:id/ai-feedback", middleware.Permission(auth.PermOutcomeRecord), outcomeHandler.RecordAIFeedback)
+		decisions.GET("/:id/ai-feedback", middleware.Permission(auth.PermOutcomeView), outcomeHandler.GetAIFeedback)
 
 		// Team Management Endpoints
 		team := protected.Group("/team")
 		{
-			team.GET("/members", teamHandler.GetMembers)
-			team.POST("/invite", teamHandler.InviteMember)
+			team.GET("", middleware.Permission(auth.PermTeamView), teamHandler.GetTeam)
+			team.PUT("", middleware.Permission(auth.PermTeamManage), teamHandler.UpdateTeam)
+			team.GET("/members", middleware.Permission(auth.PermTeamView), teamHandler.GetMembers)
+			team.PUT("/members/:id", middleware.Permission(auth.PermTeamManage), teamHandler.UpdateTeamMember)
+			team.DELETE("/members/:id", middleware.Permission(auth.PermTeamManage), teamHandler.RemoveTeamMember)
+			team.POST("/transfer-ownership", middleware.Permission(auth.PermTeamManage), teamHandler.TransferOwnership)
+			team.POST("/invite", middleware.Permission(auth.PermTeamInvite), teamHandler.InviteMember)
+			team.GET("/invitations", middleware.Permission(auth.PermTeamInvite), teamHandler.ListInvitations)
+			team.POST("/invitations/:id/resend", middleware.Permission(auth.PermTeamInvite), teamHandler.ResendInvitation)
+			team.DELETE("/invitations/:id", middleware.Permission(auth.PermTeamInvite), teamHandler.RevokeInvitation)
 		}
 
 		// Analytics Dashboard Endpoints
 		analytics := protected.Group("/analytics")
 		{
-			analytics.GET("/dashboard", analyticsHandler.GetDashboard)
+			analytics.GET("/dashboard", middleware.Permission(auth.PermAnalyticsView), analyticsHandler.GetDashboard)
+			analytics.GET("/team-performance", middleware.Permission(auth.PermAnalyticsView), analyticsHandler.GetTeamPerformance)
+			analytics.GET("/export", middleware.Permission(auth.PermAnalyticsView), analyticsHandler.ExportAnalytics)
 		}
+
+		// Audit trail
+		protected.GET("/audit", middleware.Permission(auth.PermAuditView), auditHandler.ListAuditLogs)
+		protected.GET("/audit/verify", middleware.Permission(auth.PermAuditView), auditHandler.VerifyAuditChain)
 	}
 
 	return router
 }
+
+// startJobs runs the periodic maintenance jobs until ctx is cancelled
+func startJobs(ctx context.Context, db *database.DB, cfg *config.Config, revocations *auth.RevocationStore, hub *realtime.Hub, auditLogger *audit.Logger) {
+	jobs.RunEvery(ctx, time.Hour, "Revoked token cleanup", revocations.RunCleanup)
+
+	// Soft-deleted decisions are hard-deleted once DECISION_RETENTION_DAYS has passed
+	purger := jobs.NewDecisionPurger(db, time.Duration(cfg.DecisionRetentionDays)*24*time.Hour)
+	jobs.RunEvery(ctx, time.Hour, "Decision purge", purger.Run)
+
+	// Evaluation closes on its deadline or once EVALUATION_QUORUM_PERCENT of the team has evaluated;
+	// Delphi rounds close the same way and each further round runs for EVALUATION_TIMEOUT_HOURS
+	evaluations := jobs.NewEvaluationScheduler(db, hub, auditLogger, jobs.EvaluationSchedulerConfig{
+		Quorum:         float64(cfg.EvaluationQuorumPercent) / 100,
+		ReminderBefore: time.Duration(cfg.EvaluationReminderHours) * time.Hour,
+		RoundDuration:  time.Duration(cfg.EvaluationTimeoutHours) * time.Hour,
+	})
+	jobs.RunEvery(ctx, time.Minute, "Evaluation scheduler", evaluations.Run)
+
+	// Weekly or monthly analytics reports for teams that turned them on, emailed via SMTP_HOST when set
+	var reportSender mailer.Sender
+	if cfg.SMTPHost != "" {
+		reportSender = mailer.NewSMTPSender(mailer.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		})
+	}
+	jobs.RunEvery(ctx, time.Hour, "Analytics report check", jobs.NewReportScheduler(db, reportSender).Run)
+}
+
+// buildAIProvider builds the configured provider, wrapping it in a failover chain when fallbacks are set
+func buildAIProvider(cfg *config.Config) (ai.Provider, error) {
+	registry := ai.NewRegistry()
+	names := append([]string{cfg.AIProvider}, cfg.AIFallbackProviders...)
+
+	providers := make([]ai.Provider, 0, len(names))
+	for _, name := range names {
+		provider, err := registry.Build(name, aiProviderConfig(cfg, name))
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+
+	if len(providers) == 1 {
+		return providers[0], nil
+	}
+
+	return ai.NewFailoverProvider(providers, ai.FailoverConfig{
+		FailureThreshold: cfg.AIBreakerThreshold,
+		Cooldown:         time.Duration(cfg.AIBreakerCooldown) * time.Second,
+	}), nil
+}
+
+// aiProviderConfig maps the flat application config onto the settings of the named AI provider
+func aiProviderConfig(cfg *config.Config, name string) ai.ProviderConfig {
+	providerConfig := ai.ProviderConfig{
+		Timeout: time.Duration(cfg.AIRequestTimeout) * time.Second,
+	}
+
+	switch name {
+	case ai.ProviderDeepSeek:
+		providerConfig.APIKey = cfg.DeepSeekAPIKey
+		providerConfig.BaseURL = cfg.DeepSeekAPIURL
+	case ai.ProviderModelScope:
+		providerConfig.APIKey = cfg.ModelScopeAPIKey
+		providerConfig.Model = cfg.ModelScopeModel
+	case ai.ProviderPollinations:
+		providerConfig.APIKey = cfg.PollinationsAPIToken
+	case ai.ProviderOllama:
+		providerConfig.BaseURL = cfg.OllamaURL
+		providerConfig.Model = cfg.OllamaModel
+	}
+
+	return providerConfig
+}
@@ -0,0 +1,241 @@
+// Package audit writes the append-only audit trail and verifies its hash chain.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"choseby-backend/internal/database"
+	"choseby-backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// Audited actions recorded by handler hooks
+const (
+	ActionLogin              = "auth.login"
+	ActionDecisionCreated    = "decision.created"
+	ActionDecisionUpdated    = "decision.updated"
+	ActionDecisionDeleted    = "decision.deleted"
+	ActionDecisionRestored   = "decision.restored"
+	ActionDecisionTransition = "decision.transitioned"
+	ActionCriteriaUpdated    = "criteria.updated"
+	ActionOptionsUpdated     = "options.updated"
+	ActionWeightingUpdated   = "decision.stakeholder_weighting_updated"
+	ActionEvaluationSubmit   = "evaluation.submitted"
+	ActionEvaluationClosed   = "evaluation.closed"
+	ActionDelphiRoundClosed  = "evaluation.delphi_round_closed"
+	ActionEvaluationExported = "evaluation.exported"
+	ActionAnalyticsExported  = "analytics.exported"
+	ActionDraftGenerated     = "draft.generated"
+	ActionOutcomeRecorded    = "outcome.recorded"
+	ActionMemberInvited      = "team.member_invited"
+	ActionInvitationAccepted = "team.invitation_accepted"
+	ActionRequest            = "http.request" // mutating request without a more specific hook
+)
+
+// ErrNoDatabase is returned when the server is running without a database
+var ErrNoDatabase = errors.New("audit trail has no database")
+
+// Entry is an audit event before it is placed on the chain
+type Entry struct {
+	TeamID     *uuid.UUID
+	DecisionID *uuid.UUID
+	UserID     *uuid.UUID
+	Action     string
+	Details    models.AuditDetails
+	IPAddress  string
+	UserAgent  string
+	RequestID  string
+}
+
+// VerifyResult reports whether a team's chain is intact and, if not, where it first breaks
+type VerifyResult struct {
+	Valid    bool   `json:"valid"`
+	Checked  int    `json:"checked"`
+	BrokenAt *int64 `json:"broken_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Logger appends entries to the audit_logs hash chain
+type Logger struct {
+	db  *database.DB
+	now func() time.Time
+}
+
+// NewLogger creates an audit logger backed by the audit_logs table
+func NewLogger(db *database.DB) *Logger {
+	return &Logger{db: db, now: time.Now}
+}
+
+// Record appends an entry to its team's chain. Writers of one chain are serialised with an advisory lock.
+func (l *Logger) Record(ctx context.Context, entry Entry) (*models.AuditLog, error) {
+	if entry.Action == "" {
+		return nil, errors.New("audit entry has no action")
+	}
+	if l.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	details, err := canonicalDetails(entry.Details)
+	if err != nil {
+		return nil, fmt.Errorf("audit details: %w", err)
+	}
+
+	tx, err := l.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "audit:"+chainKey(entry.TeamID)); err != nil {
+		return nil, err
+	}
+
+	var last struct {
+		Sequence int64  `db:"sequence"`
+		Hash     string `db:"hash"`
+	}
+	err = tx.GetContext(ctx, &last, `
+		SELECT sequence, hash FROM audit_logs
+		WHERE team_id IS NOT DISTINCT FROM $1
+		ORDER BY sequence DESC LIMIT 1
+	`, entry.TeamID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	log := models.AuditLog{
+		ID:         uuid.New(),
+		TeamID:     entry.TeamID,
+		Sequence:   last.Sequence + 1,
+		DecisionID: entry.DecisionID,
+		UserID:     entry.UserID,
+		Action:     entry.Action,
+		Details:    details,
+		IPAddress:  optional(entry.IPAddress),
+		UserAgent:  optional(entry.UserAgent),
+		RequestID:  optional(entry.RequestID),
+		PrevHash:   last.Hash,
+		// Postgres keeps microseconds; truncating first keeps the hash reproducible from the stored row
+		CreatedAt: l.now().UTC().Truncate(time.Microsecond),
+	}
+	log.Hash = ComputeHash(log)
+
+	_, err = tx.NamedExecContext(ctx, `
+		INSERT INTO audit_logs (
+			id, team_id, sequence, decision_id, user_id, action, details,
+			ip_address, user_agent, request_id, prev_hash, hash, created_at
+		) VALUES (
+			:id, :team_id, :sequence, :decision_id, :user_id, :action, :details,
+			:ip_address, :user_agent, :request_id, :prev_hash, :hash, :created_at
+		)
+	`, log)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+// Verify recomputes a team's chain from the first entry and reports the first entry that does not match
+func (l *Logger) Verify(ctx context.Context, teamID *uuid.UUID) (VerifyResult, error) {
+	rows, err := l.db.QueryxContext(ctx, `
+		SELECT id, team_id, sequence, decision_id, user_id, action, details,
+			   ip_address, user_agent, request_id, prev_hash, hash, created_at
+		FROM audit_logs
+		WHERE team_id IS NOT DISTINCT FROM $1
+		ORDER BY sequence
+	`, teamID)
+	if err != nil {
+		return VerifyResult{}, err
+	}
+	defer rows.Close()
+
+	result := VerifyResult{Valid: true}
+	prevHash := ""
+	expected := int64(1)
+	for rows.Next() {
+		var log models.AuditLog
+		if err := rows.StructScan(&log); err != nil {
+			return VerifyResult{}, err
+		}
+		result.Checked++
+
+		reason := ""
+		switch {
+		case log.Sequence != expected:
+			reason = fmt.Sprintf("expected sequence %d", expected)
+		case log.PrevHash != prevHash:
+			reason = "prev_hash does not match the previous entry"
+		case log.Hash != ComputeHash(log):
+			reason = "entry hash does not match its contents"
+		}
+		if reason != "" {
+			sequence := log.Sequence
+			return VerifyResult{Valid: false, Checked: result.Checked, BrokenAt: &sequence, Reason: reason}, nil
+		}
+
+		prevHash = log.Hash
+		expected++
+	}
+	return result, rows.Err()
+}
+
+// ComputeHash returns the sha256 chain hash of an entry; it covers every stored field and the previous hash
+func ComputeHash(log models.AuditLog) string {
+	payload, _ := json.Marshal([]interface{}{
+		log.PrevHash,
+		log.ID,
+		log.TeamID,
+		log.Sequence,
+		log.DecisionID,
+		log.UserID,
+		log.Action,
+		log.Details, // map keys marshal in sorted order
+		log.IPAddress,
+		log.UserAgent,
+		log.RequestID,
+		log.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalDetails round-trips details through JSON so the hash matches what is read back from JSONB
+func canonicalDetails(details models.AuditDetails) (models.AuditDetails, error) {
+	if details == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
+	var canonical models.AuditDetails
+	if err := json.Unmarshal(raw, &canonical); err != nil {
+		return nil, err
+	}
+	return canonical, nil
+}
+
+func chainKey(teamID *uuid.UUID) string {
+	if teamID == nil {
+		return uuid.Nil.String()
+	}
+	return teamID.String()
+}
+
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,104 @@
+package audit
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"choseby-backend/internal/database"
+	"choseby-backend/internal/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockLogger(t *testing.T) (*Logger, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { mockDB.Close() })
+
+	logger := NewLogger(&database.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+	logger.now = func() time.Time { return time.Date(2025, 10, 23, 9, 30, 0, 123456789, time.UTC) }
+	return logger, mock
+}
+
+func auditColumns() []string {
+	return []string{"id", "team_id", "sequence", "decision_id", "user_id", "action", "details",
+		"ip_address", "user_agent", "request_id", "prev_hash", "hash", "created_at"}
+}
+
+func TestRecordChainsOntoPreviousEntry(t *testing.T) {
+	logger, mock := newMockLogger(t)
+	teamID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("pg_advisory_xact_lock").WithArgs("audit:" + teamID.String()).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT sequence, hash FROM audit_logs").
+		WithArgs(&teamID).
+		WillReturnRows(sqlmock.NewRows([]string{"sequence", "hash"}).AddRow(41, "previous-hash"))
+	mock.ExpectExec("INSERT INTO audit_logs").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	entry, err := logger.Record(context.Background(), Entry{
+		TeamID:    &teamID,
+		Action:    ActionDecisionCreated,
+		Details:   models.AuditDetails{"urgency_level": 4},
+		IPAddress: "10.0.0.1",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(42), entry.Sequence)
+	assert.Equal(t, "previous-hash", entry.PrevHash)
+	assert.Equal(t, ComputeHash(*entry), entry.Hash)
+	assert.Equal(t, 123456000, entry.CreatedAt.Nanosecond(), "timestamps are truncated to Postgres precision")
+	assert.Equal(t, float64(4), entry.Details["urgency_level"], "details are hashed in their JSONB form")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	logger, mock := newMockLogger(t)
+	teamID := uuid.New()
+	createdAt := time.Date(2025, 10, 23, 9, 0, 0, 0, time.UTC)
+
+	first := models.AuditLog{ID: uuid.New(), TeamID: &teamID, Sequence: 1, Action: ActionLogin, CreatedAt: createdAt}
+	first.Hash = ComputeHash(first)
+	second := models.AuditLog{ID: uuid.New(), TeamID: &teamID, Sequence: 2, Action: ActionDecisionDeleted, PrevHash: first.Hash, CreatedAt: createdAt}
+	second.Hash = ComputeHash(second)
+
+	row := func(log models.AuditLog, action string) []driver.Value {
+		return []driver.Value{log.ID.String(), log.TeamID.String(), log.Sequence, nil, nil, action, nil,
+			nil, nil, nil, log.PrevHash, log.Hash, log.CreatedAt}
+	}
+
+	mock.ExpectQuery("FROM audit_logs").WithArgs(&teamID).
+		WillReturnRows(sqlmock.NewRows(auditColumns()).AddRow(row(first, first.Action)...).AddRow(row(second, second.Action)...))
+	result, err := logger.Verify(context.Background(), &teamID)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, 2, result.Checked)
+
+	// Rewriting the action of the second entry breaks its hash
+	mock.ExpectQuery("FROM audit_logs").WithArgs(&teamID).
+		WillReturnRows(sqlmock.NewRows(auditColumns()).AddRow(row(first, first.Action)...).AddRow(row(second, ActionDecisionRestored)...))
+	result, err = logger.Verify(context.Background(), &teamID)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.NotNil(t, result.BrokenAt)
+	assert.Equal(t, int64(2), *result.BrokenAt)
+
+	// Deleting the first entry breaks the sequence
+	mock.ExpectQuery("FROM audit_logs").WithArgs(&teamID).
+		WillReturnRows(sqlmock.NewRows(auditColumns()).AddRow(row(second, second.Action)...))
+	result, err = logger.Verify(context.Background(), &teamID)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordWithoutDatabase(t *testing.T) {
+	_, err := NewLogger(nil).Record(context.Background(), Entry{Action: ActionRequest})
+	assert.ErrorIs(t, err, ErrNoDatabase)
+}
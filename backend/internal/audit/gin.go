@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"context"
+	"log"
+
+	"choseby-backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Gin context keys used by the audit middleware and handler hooks
+const (
+	loggerKey   = "audit_logger"
+	recordedKey = "audit_recorded"
+)
+
+// Attach makes the logger available to handler hooks for the rest of the request
+func Attach(c *gin.Context, logger *Logger) {
+	c.Set(loggerKey, logger)
+}
+
+// Recorded reports whether a handler hook already audited this request
+func Recorded(c *gin.Context) bool {
+	return c.GetBool(recordedKey)
+}
+
+// Record audits an action for the current request. Fields left empty in entry are filled from the
+// request: the authenticated user and team, client IP, user agent and request ID.
+// Failures are logged rather than returned so auditing never fails a completed action.
+func Record(c *gin.Context, entry Entry) {
+	value, exists := c.Get(loggerKey)
+	if !exists {
+		return
+	}
+	logger := value.(*Logger)
+	if logger.db == nil {
+		return // running without a database; there is no trail to append to
+	}
+
+	if entry.UserID == nil {
+		entry.UserID = contextUUID(c, "user_id")
+	}
+	if entry.TeamID == nil {
+		entry.TeamID = contextUUID(c, "team_id")
+	}
+	if entry.IPAddress == "" {
+		entry.IPAddress = c.ClientIP()
+	}
+	if entry.UserAgent == "" {
+		entry.UserAgent = c.Request.UserAgent()
+	}
+	if entry.RequestID == "" {
+		entry.RequestID = c.GetString("request_id")
+	}
+
+	c.Set(recordedKey, true)
+
+	// The write must not be abandoned when the client disconnects after the action succeeded
+	ctx := context.WithoutCancel(c.Request.Context())
+	if _, err := logger.Record(ctx, entry); err != nil {
+		log.Printf("Failed to write audit entry %s (request %s): %v", entry.Action, entry.RequestID, err)
+	}
+}
+
+// RecordDecision audits an action on a decision
+func RecordDecision(c *gin.Context, action string, decisionID uuid.UUID, details models.AuditDetails) {
+	Record(c, Entry{Action: action, DecisionID: &decisionID, Details: details})
+}
+
+func contextUUID(c *gin.Context, key string) *uuid.UUID {
+	value, exists := c.Get(key)
+	if !exists {
+		return nil
+	}
+	id, ok := value.(uuid.UUID)
+	if !ok {
+		return nil
+	}
+	return &id
+}
@@ -1,6 +1,10 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -14,6 +18,8 @@ type Claims struct {
 	Email  string    `json:"email"`
 	Role   string    `json:"role"`
 	TeamID string    `json:"team_id"`
+	// Generation must match the member's token_generation; logout-all bumps it
+	Generation int `json:"gen"`
 	jwt.RegisteredClaims
 }
 
@@ -31,15 +37,17 @@ func NewAuthService(jwtSecret string, jwtExpiration, refreshTokenExpiration int)
 	}
 }
 
-// GenerateToken creates a JWT token for authenticated team member
-func (a *Service) GenerateToken(userID, role string) (string, time.Time, error) {
+// GenerateToken creates a JWT token for authenticated team member, stamped with a unique jti
+func (a *Service) GenerateToken(userID, role string, generation int) (string, time.Time, error) {
 	now := time.Now()
 	expiresAt := now.Add(a.jwtExpiration)
 
 	claims := &Claims{
-		UserID: uuid.MustParse(userID),
-		Role:   role,
+		UserID:     uuid.MustParse(userID),
+		Role:       role,
+		Generation: generation,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			Subject:   userID,
@@ -75,32 +83,31 @@ func (a *Service) ValidateToken(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
-// GenerateRefreshToken creates a refresh token (placeholder implementation)
-func (a *Service) GenerateRefreshToken(userID string) (string, time.Time, error) {
-	// In a complete implementation, this would:
-	// 1. Generate a unique refresh token
-	// 2. Store it in the database with expiration
-	// 3. Return the token and expiration time
-
-	now := time.Now()
-	expiresAt := now.Add(a.refreshTokenExpiration)
-
-	claims := &Claims{
-		UserID: uuid.MustParse(userID),
-		RegisteredClaims: jwt.RegisteredClaims{
-			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(expiresAt),
-			Subject:   userID,
-		},
+// GenerateRefreshToken creates an opaque refresh token and the hash to persist for it.
+// Only the hash is stored, so a database leak does not expose usable tokens.
+func (a *Service) GenerateRefreshToken() (string, string, time.Time, error) {
+	token, tokenHash, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", time.Time{}, err
 	}
+	return token, tokenHash, time.Now().Add(a.refreshTokenExpiration), nil
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(a.jwtSecret)
-	if err != nil {
-		return "", time.Time{}, err
+// GenerateOpaqueToken creates a random URL-safe token and its storage hash
+func GenerateOpaqueToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
 	}
 
-	return tokenString, expiresAt, nil
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the SHA-256 hex digest used to look up an opaque token
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 // HashPassword hashes a password using bcrypt
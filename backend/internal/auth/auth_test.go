@@ -2,6 +2,7 @@ package auth
 
 import (
 	"testing"
+	"time"
 )
 
 func TestPasswordHashingAndVerification(t *testing.T) {
@@ -58,3 +59,30 @@ func TestPasswordHashingAndVerification(t *testing.T) {
 		t.Logf("Generated hash: %s", hash)
 	})
 }
+
+func TestGenerateRefreshToken(t *testing.T) {
+	authService := NewAuthService("test-secret", 3600, 604800)
+
+	token, tokenHash, expiresAt, err := authService.GenerateRefreshToken()
+	if err != nil {
+		t.Fatalf("Failed to generate refresh token: %v", err)
+	}
+
+	if tokenHash == token {
+		t.Error("Refresh token must not be stored in plain text")
+	}
+	if tokenHash != HashToken(token) {
+		t.Error("Returned hash does not match HashToken")
+	}
+	if time.Until(expiresAt) < 604000*time.Second {
+		t.Errorf("Unexpected refresh expiry: %v", expiresAt)
+	}
+
+	other, _, _, err := authService.GenerateRefreshToken()
+	if err != nil {
+		t.Fatalf("Failed to generate refresh token: %v", err)
+	}
+	if other == token {
+		t.Error("Refresh tokens must be unique")
+	}
+}
@@ -0,0 +1,89 @@
+package auth
+
+// Permission names an action a team member may perform
+type Permission string
+
+// Permissions checked by route middleware
+const (
+	PermDecisionView               Permission = "decision.view"
+	PermDecisionCreate             Permission = "decision.create"
+	PermDecisionUpdate             Permission = "decision.update"
+	PermDecisionDelete             Permission = "decision.delete"
+	PermEvaluationSubmit           Permission = "evaluation.submit"
+	PermEvaluationResults          Permission = "evaluation.results"
+	PermEvaluationExport           Permission = "evaluation.export"
+	PermEvaluationExportIdentified Permission = "evaluation.export_identified"
+	PermAIUse                      Permission = "ai.use"
+	PermDraftGenerate              Permission = "draft.generate"
+	PermDraftView                  Permission = "draft.view"
+	PermOutcomeRecord              Permission = "outcome.record"
+	PermOutcomeView                Permission = "outcome.view"
+	PermTeamView                   Permission = "team.view"
+	PermTeamInvite                 Permission = "team.invite"
+	PermTeamManage                 Permission = "team.manage"
+	PermAnalyticsView              Permission = "analytics.view"
+	PermAuditView                  Permission = "audit.view"
+)
+
+// rolePermissions is built once; HasPermission runs on every request
+var rolePermissions = buildRolePermissions() //nolint:gochecknoglobals // read-only after init
+
+// RolePermissions returns a copy of the role→permission matrix for the six team roles
+func RolePermissions() map[string][]Permission {
+	matrix := make(map[string][]Permission, len(rolePermissions))
+	for role, permissions := range rolePermissions {
+		matrix[role] = append([]Permission(nil), permissions...)
+	}
+	return matrix
+}
+
+func buildRolePermissions() map[string][]Permission {
+	all := []Permission{
+		PermDecisionView, PermDecisionCreate, PermDecisionUpdate, PermDecisionDelete,
+		PermEvaluationSubmit, PermEvaluationResults, PermEvaluationExport,
+		PermAIUse, PermDraftGenerate, PermDraftView,
+		PermOutcomeRecord, PermOutcomeView,
+		PermTeamView, PermTeamInvite, PermTeamManage,
+		PermAnalyticsView, PermAuditView,
+	}
+
+	// Front-line managers run decisions end to end but cannot delete, export or manage the team
+	frontLine := []Permission{
+		PermDecisionView, PermDecisionCreate, PermDecisionUpdate,
+		PermEvaluationSubmit, PermEvaluationResults,
+		PermAIUse, PermDraftGenerate, PermDraftView,
+		PermOutcomeRecord, PermOutcomeView,
+		PermTeamView, PermAnalyticsView,
+	}
+
+	return map[string][]Permission{
+		"customer_success_manager": all,
+		"operations_manager":       all,
+		"support_manager":          frontLine,
+		"account_manager":          frontLine,
+		"sales_manager": {
+			PermDecisionView, PermDecisionCreate,
+			PermEvaluationSubmit, PermEvaluationResults,
+			PermAIUse, PermDraftView, PermOutcomeView,
+			PermTeamView, PermAnalyticsView,
+		},
+		// Legal reviews, exports and audits for compliance but does not author decisions or drafts.
+		// Exports that name evaluators undo their anonymity, so only legal may pull them.
+		"legal_compliance": {
+			PermDecisionView,
+			PermEvaluationSubmit, PermEvaluationResults, PermEvaluationExport, PermEvaluationExportIdentified,
+			PermDraftView, PermOutcomeView,
+			PermTeamView, PermAnalyticsView, PermAuditView,
+		},
+	}
+}
+
+// HasPermission reports whether the role grants the permission
+func HasPermission(role string, permission Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"choseby-backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// defaultRevocationSyncInterval bounds how long another instance's revocation can go unseen
+const defaultRevocationSyncInterval = 15 * time.Second
+
+// RevocationStore tracks revoked access tokens (by jti) and per-user token generations.
+// Postgres is the source of truth; an in-memory cache keeps lookups off the hot path
+// and is resynchronised from the database every sync interval.
+type RevocationStore struct {
+	db           *database.DB
+	syncInterval time.Duration
+	now          func() time.Time
+
+	mu          sync.RWMutex
+	revoked     map[string]time.Time // jti -> token expiry
+	generations map[uuid.UUID]cachedGeneration
+	lastSync    time.Time
+}
+
+type cachedGeneration struct {
+	generation int
+	loadedAt   time.Time
+}
+
+// NewRevocationStore creates a revocation store backed by the revoked_tokens table
+func NewRevocationStore(db *database.DB, syncInterval time.Duration) *RevocationStore {
+	if syncInterval <= 0 {
+		syncInterval = defaultRevocationSyncInterval
+	}
+
+	return &RevocationStore{
+		db:           db,
+		syncInterval: syncInterval,
+		now:          time.Now,
+		revoked:      make(map[string]time.Time),
+		generations:  make(map[uuid.UUID]cachedGeneration),
+	}
+}
+
+// Revoke records a token's jti as revoked until the token would have expired anyway
+func (s *RevocationStore) Revoke(ctx context.Context, jti string, userID uuid.UUID, expiresAt time.Time) error {
+	if jti == "" {
+		return errors.New("token has no jti")
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at, revoked_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (jti) DO NOTHING
+	`, jti, userID, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.revoked[jti] = expiresAt
+	s.mu.Unlock()
+
+	return nil
+}
+
+// BumpGeneration invalidates every access token issued to the user so far and returns the new generation
+func (s *RevocationStore) BumpGeneration(ctx context.Context, userID uuid.UUID) (int, error) {
+	var generation int
+	err := s.db.GetContext(ctx, &generation, `
+		UPDATE team_members SET token_generation = token_generation + 1, updated_at = NOW()
+		WHERE id = $1
+		RETURNING token_generation
+	`, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	s.generations[userID] = cachedGeneration{generation: generation, loadedAt: s.now()}
+	s.mu.Unlock()
+
+	return generation, nil
+}
+
+// IsRevoked reports whether the token was logged out or predates the user's current generation
+func (s *RevocationStore) IsRevoked(ctx context.Context, claims *Claims) (bool, error) {
+	if err := s.syncIfStale(ctx); err != nil {
+		return false, err
+	}
+
+	if claims.ID != "" {
+		s.mu.RLock()
+		_, revoked := s.revoked[claims.ID]
+		s.mu.RUnlock()
+		if revoked {
+			return true, nil
+		}
+	}
+
+	generation, err := s.currentGeneration(ctx, claims.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	return claims.Generation < generation, nil
+}
+
+// currentGeneration returns the user's token generation, cached for one sync interval
+func (s *RevocationStore) currentGeneration(ctx context.Context, userID uuid.UUID) (int, error) {
+	s.mu.RLock()
+	cached, ok := s.generations[userID]
+	s.mu.RUnlock()
+	if ok && s.now().Sub(cached.loadedAt) < s.syncInterval {
+		return cached.generation, nil
+	}
+
+	var generation int
+	err := s.db.GetContext(ctx, &generation, `
+		SELECT token_generation FROM team_members WHERE id = $1
+	`, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	s.generations[userID] = cachedGeneration{generation: generation, loadedAt: s.now()}
+	s.mu.Unlock()
+
+	return generation, nil
+}
+
+// syncIfStale pulls revocations recorded by other instances since the last sync
+func (s *RevocationStore) syncIfStale(ctx context.Context) error {
+	s.mu.RLock()
+	lastSync := s.lastSync
+	s.mu.RUnlock()
+
+	now := s.now()
+	if now.Sub(lastSync) < s.syncInterval {
+		return nil
+	}
+
+	// Overlap the window slightly so rows committed during the previous sync are not missed
+	since := lastSync.Add(-s.syncInterval)
+	var rows []struct {
+		JTI       string    `db:"jti"`
+		ExpiresAt time.Time `db:"expires_at"`
+	}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT jti, expires_at FROM revoked_tokens
+		WHERE revoked_at >= $1 AND expires_at > NOW()
+	`, since)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	for _, row := range rows {
+		s.revoked[row.JTI] = row.ExpiresAt
+	}
+	s.lastSync = now
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Cleanup drops revocations for tokens that have expired on their own
+func (s *RevocationStore) Cleanup(ctx context.Context) (int64, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	for jti, expiresAt := range s.revoked {
+		if now.After(expiresAt) {
+			delete(s.revoked, jti)
+		}
+	}
+	for userID, cached := range s.generations {
+		if now.Sub(cached.loadedAt) >= s.syncInterval {
+			delete(s.generations, userID)
+		}
+	}
+	s.mu.Unlock()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// RunCleanup runs Cleanup once and logs how many revocations went, for jobs.RunEvery
+func (s *RevocationStore) RunCleanup(ctx context.Context) error {
+	removed, err := s.Cleanup(ctx)
+	if removed > 0 {
+		log.Printf("Removed %d expired token revocations", removed)
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"choseby-backend/internal/database"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockRevocationStore(t *testing.T) (*RevocationStore, sqlmock.Sqlmock) {
+	t.Helper()
+
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { mockDB.Close() })
+
+	db := &database.DB{DB: sqlx.NewDb(mockDB, "sqlmock")}
+	return NewRevocationStore(db, time.Minute), mock
+}
+
+func TestRevocationStoreRejectsRevokedJTI(t *testing.T) {
+	store, mock := newMockRevocationStore(t)
+	userID := uuid.New()
+	claims := &Claims{UserID: userID, RegisteredClaims: jwt.RegisteredClaims{ID: "token-1"}}
+
+	mock.ExpectExec("INSERT INTO revoked_tokens").
+		WithArgs("token-1", userID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, store.Revoke(context.Background(), "token-1", userID, time.Now().Add(time.Hour)))
+
+	mock.ExpectQuery("SELECT jti, expires_at FROM revoked_tokens").
+		WillReturnRows(sqlmock.NewRows([]string{"jti", "expires_at"}))
+
+	revoked, err := store.IsRevoked(context.Background(), claims)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevocationStoreSyncsRevocationsFromDatabase(t *testing.T) {
+	store, mock := newMockRevocationStore(t)
+	userID := uuid.New()
+
+	mock.ExpectQuery("SELECT jti, expires_at FROM revoked_tokens").
+		WillReturnRows(sqlmock.NewRows([]string{"jti", "expires_at"}).AddRow("other-instance", time.Now().Add(time.Hour)))
+	mock.ExpectQuery("SELECT token_generation FROM team_members").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"token_generation"}).AddRow(0))
+
+	revoked, err := store.IsRevoked(context.Background(), &Claims{UserID: userID, RegisteredClaims: jwt.RegisteredClaims{ID: "mine"}})
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	// Second lookup is served from the cache without touching the database
+	revoked, err = store.IsRevoked(context.Background(), &Claims{UserID: userID, RegisteredClaims: jwt.RegisteredClaims{ID: "other-instance"}})
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevocationStoreRejectsOlderGeneration(t *testing.T) {
+	store, mock := newMockRevocationStore(t)
+	userID := uuid.New()
+
+	mock.ExpectQuery("UPDATE team_members SET token_generation").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"token_generation"}).AddRow(3))
+	generation, err := store.BumpGeneration(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, generation)
+
+	mock.ExpectQuery("SELECT jti, expires_at FROM revoked_tokens").
+		WillReturnRows(sqlmock.NewRows([]string{"jti", "expires_at"}))
+
+	revoked, err := store.IsRevoked(context.Background(), &Claims{UserID: userID, Generation: 2})
+	require.NoError(t, err)
+	assert.True(t, revoked, "tokens issued before logout-all must be rejected")
+
+	revoked, err = store.IsRevoked(context.Background(), &Claims{UserID: userID, Generation: 3})
+	require.NoError(t, err)
+	assert.False(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevocationStoreCleanupDropsExpiredEntries(t *testing.T) {
+	store, mock := newMockRevocationStore(t)
+	store.revoked["expired"] = time.Now().Add(-time.Minute)
+	store.revoked["live"] = time.Now().Add(time.Hour)
+
+	mock.ExpectExec("DELETE FROM revoked_tokens").WillReturnResult(sqlmock.NewResult(0, 4))
+
+	removed, err := store.Cleanup(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), removed)
+	assert.NotContains(t, store.revoked, "expired")
+	assert.Contains(t, store.revoked, "live")
+}
+
+func TestGenerateTokenSetsJTIAndGeneration(t *testing.T) {
+	authService := NewAuthService("test-secret", 3600, 604800)
+
+	token, _, err := authService.GenerateToken(uuid.New().String(), "support_manager", 7)
+	require.NoError(t, err)
+
+	claims, err := authService.ValidateToken(token)
+	require.NoError(t, err)
+	assert.NotEmpty(t, claims.ID)
+	assert.Equal(t, 7, claims.Generation)
+}
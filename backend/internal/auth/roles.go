@@ -0,0 +1,39 @@
+package auth
+
+// AdminRoles returns the team roles allowed to manage the team
+func AdminRoles() []string {
+	var roles []string
+	for _, role := range ValidRoles() {
+		if IsAdminRole(role) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// IsAdminRole reports whether the role can manage the team
+func IsAdminRole(role string) bool {
+	return HasPermission(role, PermTeamManage)
+}
+
+// ValidRoles returns every customer response team role
+func ValidRoles() []string {
+	return []string{
+		"customer_success_manager",
+		"support_manager",
+		"account_manager",
+		"sales_manager",
+		"legal_compliance",
+		"operations_manager",
+	}
+}
+
+// IsValidRole reports whether role is a known team role
+func IsValidRole(role string) bool {
+	for _, validRole := range ValidRoles() {
+		if role == validRole {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminRolesAreValidRoles(t *testing.T) {
+	for _, role := range AdminRoles() {
+		assert.True(t, IsValidRole(role), role)
+		assert.True(t, IsAdminRole(role), role)
+	}
+
+	assert.False(t, IsAdminRole("support_manager"))
+	assert.False(t, IsValidRole("owner"))
+}
+
+func TestRolePermissionsCoverEveryRole(t *testing.T) {
+	matrix := RolePermissions()
+	for _, role := range ValidRoles() {
+		assert.NotEmpty(t, matrix[role], role)
+		assert.True(t, HasPermission(role, PermDecisionView), role)
+	}
+	assert.Len(t, matrix, len(ValidRoles()))
+
+	assert.True(t, HasPermission("legal_compliance", PermEvaluationExport))
+	assert.True(t, HasPermission("legal_compliance", PermAuditView))
+	assert.True(t, HasPermission("legal_compliance", PermEvaluationExportIdentified))
+	assert.False(t, HasPermission("customer_success_manager", PermEvaluationExportIdentified))
+	assert.False(t, HasPermission("account_manager", PermAuditView))
+	assert.False(t, HasPermission("support_manager", PermDecisionDelete))
+	assert.False(t, HasPermission("sales_manager", PermTeamInvite))
+	assert.False(t, HasPermission("unknown_role", PermDecisionView))
+}
+
+func TestRolePermissionsReturnsCopy(t *testing.T) {
+	matrix := RolePermissions()
+	matrix["support_manager"][0] = PermDecisionDelete
+	delete(matrix, "sales_manager")
+
+	assert.False(t, HasPermission("support_manager", PermDecisionDelete))
+	assert.True(t, HasPermission("sales_manager", PermDecisionView))
+}
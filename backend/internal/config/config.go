@@ -20,9 +20,18 @@ type Config struct {
 	RefreshTokenExpiration int
 
 	// AI Integration
-	DeepSeekAPIKey   string
-	DeepSeekAPIURL   string
-	AIRequestTimeout int
+	AIProvider           string
+	AIFallbackProviders  []string
+	AIBreakerThreshold   int
+	AIBreakerCooldown    int
+	DeepSeekAPIKey       string
+	DeepSeekAPIURL       string
+	ModelScopeAPIKey     string
+	ModelScopeModel      string
+	PollinationsAPIToken string
+	OllamaURL            string
+	OllamaModel          string
+	AIRequestTimeout     int
 
 	// API Configuration
 	APIRateLimit  int
@@ -30,9 +39,19 @@ type Config struct {
 	CORSOrigins   []string
 
 	// Customer Response Platform
-	MaxTeamMembers         int
-	MaxDecisionsPerTeam    int
-	EvaluationTimeoutHours int
+	MaxTeamMembers          int
+	MaxDecisionsPerTeam     int
+	EvaluationTimeoutHours  int
+	EvaluationQuorumPercent int
+	EvaluationReminderHours int
+	DecisionRetentionDays   int
+
+	// Email (analytics reports); without SMTP_HOST reports are only logged
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
 
 	// WebSocket
 	WSMaxConnections    int
@@ -59,9 +78,37 @@ func Load() *Config {
 		log.Fatal("FATAL: DATABASE_URL environment variable is required")
 	}
 
+	aiProvider := strings.ToLower(getEnv("AI_PROVIDER", "deepseek"))
+	if !isKnownAIProvider(aiProvider) {
+		log.Fatalf("FATAL: AI_PROVIDER must be one of deepseek, modelscope, pollinations, ollama, keyword (got %q)", aiProvider)
+	}
+
+	// Without an API key the hosted providers cannot work; fall back to the offline keyword classifier
 	deepSeekAPIKey := getEnv("DEEPSEEK_API_KEY", "")
-	if deepSeekAPIKey == "" {
-		log.Println("WARNING: DEEPSEEK_API_KEY not set - AI features will be disabled")
+	if aiProvider == "deepseek" && deepSeekAPIKey == "" {
+		log.Println("WARNING: DEEPSEEK_API_KEY not set - using offline keyword classifier")
+		aiProvider = "keyword"
+	}
+
+	modelScopeAPIKey := getEnv("MODELSCOPE_API_TOKEN", "")
+	if aiProvider == "modelscope" && modelScopeAPIKey == "" {
+		log.Println("WARNING: MODELSCOPE_API_TOKEN not set - using offline keyword classifier")
+		aiProvider = "keyword"
+	}
+
+	// Deduplicated after the keyword substitution above, so the chain never repeats a provider
+	var aiFallbackProviders []string
+	seenProviders := map[string]bool{aiProvider: true}
+	for _, name := range strings.Split(getEnv("AI_FALLBACK_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seenProviders[name] {
+			continue
+		}
+		if !isKnownAIProvider(name) {
+			log.Fatalf("FATAL: AI_FALLBACK_PROVIDERS contains unknown provider %q", name)
+		}
+		seenProviders[name] = true
+		aiFallbackProviders = append(aiFallbackProviders, name)
 	}
 
 	return &Config{
@@ -75,9 +122,18 @@ func Load() *Config {
 		RefreshTokenExpiration: getEnvInt("REFRESH_TOKEN_EXPIRATION", 604800),
 
 		// AI Integration
-		DeepSeekAPIKey:   deepSeekAPIKey,
-		DeepSeekAPIURL:   getEnv("DEEPSEEK_API_URL", "https://api.deepseek.com/v1"),
-		AIRequestTimeout: getEnvInt("AI_REQUEST_TIMEOUT", 30),
+		AIProvider:           aiProvider,
+		AIFallbackProviders:  aiFallbackProviders,
+		AIBreakerThreshold:   getEnvInt("AI_BREAKER_FAILURE_THRESHOLD", 3),
+		AIBreakerCooldown:    getEnvInt("AI_BREAKER_COOLDOWN", 60),
+		DeepSeekAPIKey:       deepSeekAPIKey,
+		DeepSeekAPIURL:       getEnv("DEEPSEEK_API_URL", "https://api.deepseek.com/v1"),
+		ModelScopeAPIKey:     modelScopeAPIKey,
+		ModelScopeModel:      getEnv("MODELSCOPE_MODEL", "qwen-max"),
+		PollinationsAPIToken: getEnv("POLLINATIONS_API_TOKEN", ""),
+		OllamaURL:            getEnv("OLLAMA_URL", "http://localhost:11434"),
+		OllamaModel:          getEnv("OLLAMA_MODEL", "deepseek-r1:1.5b"),
+		AIRequestTimeout:     getEnvInt("AI_REQUEST_TIMEOUT", 30),
 
 		// API Configuration
 		APIRateLimit:  getEnvInt("API_RATE_LIMIT", 1000),
@@ -85,9 +141,19 @@ func Load() *Config {
 		CORSOrigins:   strings.Split(getEnv("CORS_ORIGINS", "http://localhost:3000,https://choseby.vercel.app"), ","),
 
 		// Customer Response Platform
-		MaxTeamMembers:         getEnvInt("MAX_TEAM_MEMBERS", 25),
-		MaxDecisionsPerTeam:    getEnvInt("MAX_DECISIONS_PER_TEAM", 100),
-		EvaluationTimeoutHours: getEnvInt("EVALUATION_TIMEOUT_HOURS", 72),
+		MaxTeamMembers:          getEnvInt("MAX_TEAM_MEMBERS", 25),
+		MaxDecisionsPerTeam:     getEnvInt("MAX_DECISIONS_PER_TEAM", 100),
+		EvaluationTimeoutHours:  getEnvInt("EVALUATION_TIMEOUT_HOURS", 72),
+		EvaluationQuorumPercent: getEnvInt("EVALUATION_QUORUM_PERCENT", 100),
+		EvaluationReminderHours: getEnvInt("EVALUATION_REMINDER_HOURS", 24),
+		DecisionRetentionDays:   getEnvInt("DECISION_RETENTION_DAYS", 30),
+
+		// Email
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnvInt("SMTP_PORT", 587),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("SMTP_FROM", "Choseby <reports@choseby.app>"),
 
 		// WebSocket
 		WSMaxConnections:    getEnvInt("WS_MAX_CONNECTIONS", 1000),
@@ -99,6 +165,14 @@ func Load() *Config {
 	}
 }
 
+func isKnownAIProvider(name string) bool {
+	switch name {
+	case "deepseek", "modelscope", "pollinations", "ollama", "keyword":
+		return true
+	}
+	return false
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
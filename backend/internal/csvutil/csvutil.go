@@ -0,0 +1,64 @@
+// Package csvutil writes exports as CSV that is safe to open in a spreadsheet.
+package csvutil
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Table is one named table of an export
+type Table struct {
+	Name string
+	Rows [][]interface{}
+}
+
+// WriteTables writes the tables one after another, each headed by a "# Name" row and separated by a
+// blank row. Floats are formatted with the given precision, as in strconv.FormatFloat.
+func WriteTables(w io.Writer, tables []Table, precision int) error {
+	out := csv.NewWriter(w)
+	for i, table := range tables {
+		if i > 0 {
+			if err := out.Write([]string{}); err != nil {
+				return err
+			}
+		}
+		if err := out.Write([]string{"# " + table.Name}); err != nil {
+			return err
+		}
+		for _, row := range table.Rows {
+			record := make([]string, len(row))
+			for j, value := range row {
+				record[j] = Value(value, precision)
+			}
+			if err := out.Write(record); err != nil {
+				return err
+			}
+		}
+		// Flush per table so large exports reach the client as they are written
+		out.Flush()
+		if err := out.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Value formats a cell. Text that a spreadsheet would run as a formula is quoted with a leading apostrophe.
+func Value(value interface{}, precision int) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', precision, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,32 @@
+package csvutil
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValueQuotesFormulas(t *testing.T) {
+	for _, formula := range []string{"=SUM(A1:A2)", "+1", "-1", "@cmd", "\tx", "\rx"} {
+		assert.Equal(t, "'"+formula, Value(formula, -1), formula)
+	}
+	assert.Equal(t, "Refund", Value("Refund", -1))
+	assert.Equal(t, "", Value(nil, -1))
+	assert.Equal(t, "7", Value(7, -1))
+}
+
+func TestValueFloatPrecision(t *testing.T) {
+	assert.Equal(t, "0.126", Value(0.126, -1))
+	assert.Equal(t, "0.13", Value(0.126, 2))
+}
+
+func TestWriteTables(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, WriteTables(&out, []Table{
+		{Name: "Summary", Rows: [][]interface{}{{"metric", "value"}, {"rate", 0.5}}},
+		{Name: "Members", Rows: [][]interface{}{{"name"}, {"=HYPERLINK()"}}},
+	}, 2))
+	assert.Equal(t, "# Summary\nmetric,value\nrate,0.50\n\n# Members\nname\n'=HYPERLINK()\n", out.String())
+}
@@ -0,0 +1,266 @@
+package database
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// DecisionSearchVector is the full-text document for a decision. It must stay identical to the
+// expression indexed by migration 010 or Postgres will not use the GIN index.
+const DecisionSearchVector = `(setweight(to_tsvector('english', coalesce(title, '')), 'A') || ` +
+	`setweight(to_tsvector('english', coalesce(customer_name, '')), 'A') || ` +
+	`setweight(to_tsvector('english', coalesce(description, '')), 'B') || ` +
+	`setweight(to_tsvector('english', coalesce(relationship_history, '')), 'C'))`
+
+// Decision list page sizes
+const (
+	DefaultDecisionPageSize = 20
+	MaxDecisionPageSize     = 100
+)
+
+// ErrInvalidCursor is returned when a pagination cursor is malformed or was issued for another sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// DecisionListFilter holds the filters, sort and page requested for a team's decision list
+type DecisionListFilter struct {
+	TeamID uuid.UUID
+
+	Status              string
+	UrgencyLevel        *int
+	CustomerTier        string
+	DecisionType        string
+	CustomerImpactScope string
+	CreatedBy           *uuid.UUID
+	CreatedFrom         *time.Time
+	CreatedTo           *time.Time
+	MinFinancialImpact  *float64
+	MaxFinancialImpact  *float64
+	MinAIConfidence     *float64
+	MaxAIConfidence     *float64
+	Search              string
+
+	Sort   string // created_at, updated_at, urgency_level, financial_impact, ai_confidence, title, relevance
+	Order  string // asc or desc
+	Limit  int
+	Offset int
+	Cursor string
+}
+
+// DecisionCursor is the keyset position after the last row of a page
+type DecisionCursor struct {
+	Sort    string    `json:"s"`
+	Order   string    `json:"o"`
+	SortKey string    `json:"k"`
+	ID      uuid.UUID `json:"id"`
+}
+
+// decisionSortColumn describes how a sort option orders rows and how its cursor value is cast back
+type decisionSortColumn struct {
+	expr string
+	cast string
+}
+
+// decisionSortColumns returns the supported sort options; nullable columns are coalesced so keyset comparisons work
+func decisionSortColumns() map[string]decisionSortColumn {
+	return map[string]decisionSortColumn{
+		"created_at":       {"created_at", "timestamp"},
+		"updated_at":       {"updated_at", "timestamp"},
+		"urgency_level":    {"urgency_level", "integer"},
+		"financial_impact": {"COALESCE(financial_impact, 0)", "numeric"},
+		"ai_confidence":    {"COALESCE(ai_confidence_score, 0)", "numeric"},
+		"title":            {"title", "text"},
+		"relevance":        {"ts_rank(" + DecisionSearchVector + ", websearch_to_tsquery('english', %s))", "real"},
+	}
+}
+
+// Normalize applies defaults and validates the sort, order and page size
+func (f *DecisionListFilter) Normalize() error {
+	if f.Sort == "" {
+		f.Sort = "created_at"
+		if f.Search != "" {
+			f.Sort = "relevance"
+		}
+	}
+	if _, ok := decisionSortColumns()[f.Sort]; !ok {
+		return fmt.Errorf("unsupported sort %q", f.Sort)
+	}
+	if f.Sort == "relevance" && f.Search == "" {
+		return errors.New("sort=relevance requires a search query")
+	}
+
+	f.Order = strings.ToLower(f.Order)
+	switch f.Order {
+	case "":
+		f.Order = "desc"
+		if f.Sort == "title" {
+			f.Order = "asc"
+		}
+	case "asc", "desc":
+	default:
+		return fmt.Errorf("unsupported order %q", f.Order)
+	}
+
+	if f.Limit <= 0 {
+		f.Limit = DefaultDecisionPageSize
+	}
+	if f.Limit > MaxDecisionPageSize {
+		f.Limit = MaxDecisionPageSize
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+	return nil
+}
+
+// BuildDecisionListQuery returns the page query and a count query sharing the same filters.
+// The page query selects sort_key so the caller can build the next cursor from the last row.
+func BuildDecisionListQuery(f DecisionListFilter, columns string) (string, []interface{}, string, []interface{}, error) {
+	where, args := decisionListWhere(f)
+
+	// The count ignores the cursor: it reports the size of the whole filtered result
+	countQuery := "SELECT COUNT(*) FROM customer_decisions WHERE " + strings.Join(where, " AND ")
+	countArgs := append([]interface{}{}, args...)
+
+	sortExpr := decisionSortColumns()[f.Sort].expr
+	if f.Sort == "relevance" {
+		args = append(args, f.Search)
+		sortExpr = fmt.Sprintf(sortExpr, fmt.Sprintf("$%d", len(args)))
+	}
+
+	if f.Cursor != "" {
+		cursor, err := DecodeDecisionCursor(f.Cursor)
+		if err != nil {
+			return "", nil, "", nil, err
+		}
+		if cursor.Sort != f.Sort || cursor.Order != f.Order {
+			return "", nil, "", nil, fmt.Errorf("%w: issued for sort=%s order=%s", ErrInvalidCursor, cursor.Sort, cursor.Order)
+		}
+		// A tampered key would otherwise fail the cast in Postgres
+		if !validSortKey(decisionSortColumns()[f.Sort].cast, cursor.SortKey) {
+			return "", nil, "", nil, fmt.Errorf("%w: sort key %q is not a valid %s", ErrInvalidCursor, cursor.SortKey, f.Sort)
+		}
+
+		op := "<"
+		if f.Order == "asc" {
+			op = ">"
+		}
+		args = append(args, cursor.SortKey, cursor.ID)
+		where = append(where, fmt.Sprintf("(%s, id) %s (($%d)::%s, $%d)",
+			sortExpr, op, len(args)-1, decisionSortColumns()[f.Sort].cast, len(args)))
+	}
+
+	direction := strings.ToUpper(f.Order)
+	query := fmt.Sprintf("SELECT %s, (%s)::text AS sort_key FROM customer_decisions WHERE %s ORDER BY %s %s, id %s",
+		columns, sortExpr, strings.Join(where, " AND "), sortExpr, direction, direction)
+
+	args = append(args, f.Limit)
+	query += fmt.Sprintf(" LIMIT $%d", len(args))
+	if f.Cursor == "" && f.Offset > 0 {
+		args = append(args, f.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	return query, args, countQuery, countArgs, nil
+}
+
+// decisionListWhere builds the filter conditions shared by the page and count queries
+func decisionListWhere(f DecisionListFilter) ([]string, []interface{}) {
+	where := []string{"team_id = $1", "deleted_at IS NULL"}
+	args := []interface{}{f.TeamID}
+
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		where = append(where, fmt.Sprintf(condition, len(args)))
+	}
+
+	if f.Status != "" {
+		add("status = $%d", f.Status)
+	}
+	if f.UrgencyLevel != nil {
+		add("urgency_level = $%d", *f.UrgencyLevel)
+	}
+	if f.CustomerTier != "" {
+		add("customer_tier = $%d", f.CustomerTier)
+	}
+	if f.DecisionType != "" {
+		add("decision_type = $%d", f.DecisionType)
+	}
+	if f.CustomerImpactScope != "" {
+		add("customer_impact_scope = $%d", f.CustomerImpactScope)
+	}
+	if f.CreatedBy != nil {
+		add("created_by = $%d", *f.CreatedBy)
+	}
+	if f.CreatedFrom != nil {
+		add("created_at >= $%d", *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		add("created_at < $%d", *f.CreatedTo)
+	}
+	if f.MinFinancialImpact != nil {
+		add("financial_impact >= $%d", *f.MinFinancialImpact)
+	}
+	if f.MaxFinancialImpact != nil {
+		add("financial_impact <= $%d", *f.MaxFinancialImpact)
+	}
+	if f.MinAIConfidence != nil {
+		add("ai_confidence_score >= $%d", *f.MinAIConfidence)
+	}
+	if f.MaxAIConfidence != nil {
+		add("ai_confidence_score <= $%d", *f.MaxAIConfidence)
+	}
+	if f.Search != "" {
+		add(DecisionSearchVector+" @@ websearch_to_tsquery('english', $%d)", f.Search)
+	}
+
+	return where, args
+}
+
+// validSortKey reports whether a cursor's sort key is the text Postgres produces for the sort column's type
+func validSortKey(cast, key string) bool {
+	switch cast {
+	case "timestamp":
+		for _, layout := range []string{"2006-01-02 15:04:05.999999999", "2006-01-02 15:04:05.999999999-07", "2006-01-02 15:04:05.999999999-07:00"} {
+			if _, err := time.Parse(layout, key); err == nil {
+				return true
+			}
+		}
+		return false
+	case "integer":
+		_, err := strconv.ParseInt(key, 10, 32)
+		return err == nil
+	case "numeric", "real":
+		v, err := strconv.ParseFloat(key, 64)
+		return err == nil && !math.IsNaN(v) && !math.IsInf(v, 0)
+	default:
+		return utf8.ValidString(key) && !strings.ContainsRune(key, 0)
+	}
+}
+
+// EncodeDecisionCursor returns the opaque cursor for the row after which the next page starts
+func EncodeDecisionCursor(cursor DecisionCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeDecisionCursor parses a cursor produced by EncodeDecisionCursor
+func DecodeDecisionCursor(value string) (DecisionCursor, error) {
+	var cursor DecisionCursor
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.ID == uuid.Nil {
+		return cursor, ErrInvalidCursor
+	}
+	return cursor, nil
+}
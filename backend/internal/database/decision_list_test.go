@@ -0,0 +1,107 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildDecisionListQuery_CountUsesSameFilters(t *testing.T) {
+	urgency := 4
+	filter := DecisionListFilter{TeamID: uuid.New(), Status: "created", UrgencyLevel: &urgency, DecisionType: "refund_request"}
+	require.NoError(t, filter.Normalize())
+
+	query, args, countQuery, countArgs, err := BuildDecisionListQuery(filter, "id")
+	require.NoError(t, err)
+
+	assert.Contains(t, countQuery, "urgency_level = $3", "count must honour the urgency filter")
+	assert.Contains(t, countQuery, "deleted_at IS NULL")
+	assert.Equal(t, []interface{}{filter.TeamID, "created", 4, "refund_request"}, countArgs)
+	assert.Contains(t, query, "ORDER BY created_at DESC, id DESC LIMIT $5")
+	assert.Equal(t, DefaultDecisionPageSize, args[len(args)-1])
+}
+
+func TestBuildDecisionListQuery_SearchDefaultsToRelevance(t *testing.T) {
+	filter := DecisionListFilter{TeamID: uuid.New(), Search: "refund outage"}
+	require.NoError(t, filter.Normalize())
+	assert.Equal(t, "relevance", filter.Sort)
+
+	query, args, countQuery, _, err := BuildDecisionListQuery(filter, "id")
+	require.NoError(t, err)
+
+	assert.Contains(t, countQuery, "@@ websearch_to_tsquery('english', $2)")
+	assert.Contains(t, query, "ts_rank(")
+	assert.Equal(t, "refund outage", args[2], "search term is bound again for ranking")
+}
+
+func TestBuildDecisionListQuery_Cursor(t *testing.T) {
+	filter := DecisionListFilter{TeamID: uuid.New(), Sort: "urgency_level", Order: "asc"}
+	require.NoError(t, filter.Normalize())
+
+	lastID := uuid.New()
+	filter.Cursor = EncodeDecisionCursor(DecisionCursor{Sort: "urgency_level", Order: "asc", SortKey: "3", ID: lastID})
+
+	query, args, countQuery, _, err := BuildDecisionListQuery(filter, "id")
+	require.NoError(t, err)
+
+	assert.Contains(t, query, "(urgency_level, id) > (($2)::integer, $3)")
+	assert.NotContains(t, query, "OFFSET")
+	assert.NotContains(t, countQuery, "urgency_level", "count ignores the cursor")
+	assert.Equal(t, lastID, args[2])
+
+	filter.Order = "desc"
+	_, _, _, _, err = BuildDecisionListQuery(filter, "id")
+	assert.True(t, errors.Is(err, ErrInvalidCursor), "cursor from another sort order is rejected")
+
+	filter.Cursor = "not-a-cursor"
+	_, _, _, _, err = BuildDecisionListQuery(filter, "id")
+	assert.True(t, errors.Is(err, ErrInvalidCursor))
+}
+
+func TestBuildDecisionListQuery_CursorSortKeyMustMatchColumnType(t *testing.T) {
+	cases := []struct {
+		sort  string
+		key   string
+		valid bool
+	}{
+		{"created_at", "2025-10-20 09:15:00.123456", true},
+		{"created_at", "2025-10-20 09:15:00+00", true},
+		{"created_at", "yesterday", false},
+		{"urgency_level", "3", true},
+		{"urgency_level", "3; DROP TABLE customer_decisions", false},
+		{"financial_impact", "1500.00", true},
+		{"financial_impact", "NaN", false},
+		{"title", "Refund for Acme", true},
+		{"title", "bad\x00key", false},
+	}
+	for _, tc := range cases {
+		filter := DecisionListFilter{TeamID: uuid.New(), Sort: tc.sort}
+		require.NoError(t, filter.Normalize())
+		filter.Cursor = EncodeDecisionCursor(DecisionCursor{Sort: filter.Sort, Order: filter.Order, SortKey: tc.key, ID: uuid.New()})
+
+		_, _, _, _, err := BuildDecisionListQuery(filter, "id")
+		if tc.valid {
+			assert.NoError(t, err, "%s=%q", tc.sort, tc.key)
+		} else {
+			assert.ErrorIs(t, err, ErrInvalidCursor, "%s=%q", tc.sort, tc.key)
+		}
+	}
+}
+
+func TestDecisionListFilterNormalize(t *testing.T) {
+	filter := DecisionListFilter{Limit: 500}
+	require.NoError(t, filter.Normalize())
+	assert.Equal(t, MaxDecisionPageSize, filter.Limit)
+	assert.Equal(t, "created_at", filter.Sort)
+	assert.Equal(t, "desc", filter.Order)
+
+	assert.Error(t, (&DecisionListFilter{Sort: "password_hash"}).Normalize())
+	assert.Error(t, (&DecisionListFilter{Sort: "relevance"}).Normalize())
+	assert.Error(t, (&DecisionListFilter{Order: "sideways"}).Normalize())
+
+	assert.False(t, strings.Contains(DecisionSearchVector, "%"), "search vector is used as a format string")
+}
@@ -0,0 +1,588 @@
+package evaluation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Aggregation methods a decision can rank its options with
+const (
+	MethodWeightedMean       = "weighted_mean"       // criterion-weighted mean of all scores (the default)
+	MethodConfidenceWeighted = "confidence_weighted" // as weighted_mean, with each score also weighted by its confidence
+	MethodBorda              = "borda"               // each evaluator ranks the options; average Borda points
+	MethodMedian             = "median"              // median of the evaluators' per-option scores
+	MethodTrimmedMean        = "trimmed_mean"        // mean of the evaluators' per-option scores without the extremes
+	MethodTOPSIS             = "topsis"              // closeness to the ideal option across weighted criteria
+)
+
+// Consensus measures for TeamConsensus
+const (
+	ConsensusVariance     = "variance"           // 1 - variance/10 per option, averaged (the default)
+	ConsensusKendallW     = "kendall_w"          // Kendall's coefficient of concordance over the evaluators' option rankings
+	ConsensusKrippendorff = "krippendorff_alpha" // Krippendorff's alpha (interval) over every option x criterion score
+)
+
+// trimProportion is cut from each end of the scores before the trimmed mean
+const trimProportion = 0.2
+
+// ErrUnknownMethod is returned for an aggregation or consensus method that does not exist
+var ErrUnknownMethod = errors.New("unknown evaluation method")
+
+// Score is one evaluator's score of an option against a criterion
+type Score struct {
+	EvaluatorID uuid.UUID `db:"evaluator_id"`
+	OptionID    uuid.UUID `db:"option_id"`
+	CriteriaID  uuid.UUID `db:"criteria_id"`
+	Score       float64   `db:"score"`
+	Confidence  int       `db:"confidence"`
+
+	// The evaluator's team role and escalation authority, for stakeholder weighting
+	EvaluatorRole       string `db:"evaluator_role"`
+	EscalationAuthority int    `db:"escalation_authority"`
+}
+
+// Option is a response option being ranked
+type Option struct {
+	ID    uuid.UUID `json:"id" db:"id"`
+	Title string    `json:"title" db:"title"`
+}
+
+// Criterion is a decision criterion and its weight
+type Criterion struct {
+	ID     uuid.UUID `json:"id" db:"id"`
+	Name   string    `json:"name" db:"name"`
+	Weight float64   `json:"weight" db:"weight"`
+}
+
+// Ballots is everything submitted for a decision
+type Ballots struct {
+	Options  []Option
+	Criteria []Criterion
+	Scores   []Score
+
+	// EvaluatorWeights scales each evaluator's say in the aggregate; nil counts everyone equally
+	EvaluatorWeights map[uuid.UUID]float64
+}
+
+// Aggregator turns ballots into a score per option; a higher score ranks higher
+type Aggregator interface {
+	Aggregate(b *Ballots) map[uuid.UUID]float64
+}
+
+// ConsensusMeasure rates how much evaluators agree, from 0 (none) to 1 (complete)
+type ConsensusMeasure interface {
+	Consensus(b *Ballots) float64
+}
+
+// AggregatorFunc adapts a function to Aggregator
+type AggregatorFunc func(b *Ballots) map[uuid.UUID]float64
+
+// Aggregate calls f
+func (f AggregatorFunc) Aggregate(b *Ballots) map[uuid.UUID]float64 { return f(b) }
+
+// ConsensusFunc adapts a function to ConsensusMeasure
+type ConsensusFunc func(b *Ballots) float64
+
+// Consensus calls f
+func (f ConsensusFunc) Consensus(b *Ballots) float64 { return f(b) }
+
+// Aggregators returns the available aggregation methods by name
+func Aggregators() map[string]Aggregator {
+	return map[string]Aggregator{
+		MethodWeightedMean:       AggregatorFunc(weightedMean),
+		MethodConfidenceWeighted: AggregatorFunc(confidenceWeightedMean),
+		MethodBorda:              AggregatorFunc(bordaCount),
+		MethodMedian:             AggregatorFunc(median),
+		MethodTrimmedMean:        AggregatorFunc(trimmedMean),
+		MethodTOPSIS:             AggregatorFunc(topsis),
+	}
+}
+
+// ConsensusMeasures returns the available consensus measures by name
+func ConsensusMeasures() map[string]ConsensusMeasure {
+	return map[string]ConsensusMeasure{
+		ConsensusVariance:     ConsensusFunc(varianceConsensus),
+		ConsensusKendallW:     ConsensusFunc(kendallW),
+		ConsensusKrippendorff: ConsensusFunc(krippendorffAlpha),
+	}
+}
+
+// AggregationNames lists the aggregation methods in alphabetical order
+func AggregationNames() []string {
+	return sortedKeys(Aggregators())
+}
+
+// ConsensusNames lists the consensus measures in alphabetical order
+func ConsensusNames() []string {
+	return sortedKeys(ConsensusMeasures())
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// AggregatorFor looks up an aggregation method; an empty name is the default weighted mean
+func AggregatorFor(name string) (Aggregator, error) {
+	if name == "" {
+		name = MethodWeightedMean
+	}
+	aggregator, ok := Aggregators()[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: aggregation %q", ErrUnknownMethod, name)
+	}
+	return aggregator, nil
+}
+
+// ConsensusMeasureFor looks up a consensus measure; an empty name is the default variance measure
+func ConsensusMeasureFor(name string) (ConsensusMeasure, error) {
+	if name == "" {
+		name = ConsensusVariance
+	}
+	measure, ok := ConsensusMeasures()[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: consensus %q", ErrUnknownMethod, name)
+	}
+	return measure, nil
+}
+
+// criterionWeights maps each criterion to its weight
+func (b *Ballots) criterionWeights() map[uuid.UUID]float64 {
+	weights := make(map[uuid.UUID]float64, len(b.Criteria))
+	for _, criterion := range b.Criteria {
+		weights[criterion.ID] = criterion.Weight
+	}
+	return weights
+}
+
+// evaluatorWeight is how much an evaluator counts in the aggregate
+func (b *Ballots) evaluatorWeight(evaluatorID uuid.UUID) float64 {
+	if b.EvaluatorWeights == nil {
+		return 1
+	}
+	return b.EvaluatorWeights[evaluatorID]
+}
+
+// evaluatorScores is each evaluator's criterion-weighted score of each option they scored
+func (b *Ballots) evaluatorScores() map[uuid.UUID]map[uuid.UUID]float64 {
+	weights := b.criterionWeights()
+	type sum struct{ weighted, weight float64 }
+	sums := make(map[uuid.UUID]map[uuid.UUID]*sum)
+	for _, s := range b.Scores {
+		if sums[s.EvaluatorID] == nil {
+			sums[s.EvaluatorID] = make(map[uuid.UUID]*sum)
+		}
+		if sums[s.EvaluatorID][s.OptionID] == nil {
+			sums[s.EvaluatorID][s.OptionID] = &sum{}
+		}
+		entry := sums[s.EvaluatorID][s.OptionID]
+		entry.weighted += s.Score * weights[s.CriteriaID]
+		entry.weight += weights[s.CriteriaID]
+	}
+
+	scores := make(map[uuid.UUID]map[uuid.UUID]float64, len(sums))
+	for evaluatorID, options := range sums {
+		scores[evaluatorID] = make(map[uuid.UUID]float64, len(options))
+		for optionID, entry := range options {
+			if entry.weight > 0 {
+				scores[evaluatorID][optionID] = entry.weighted / entry.weight
+			}
+		}
+	}
+	return scores
+}
+
+// sample is one evaluator's score of an option and how much that evaluator counts
+type sample struct {
+	value, weight float64
+}
+
+// optionSamples collects the evaluators' per-option scores for each option, lowest first
+func (b *Ballots) optionSamples() map[uuid.UUID][]sample {
+	samples := make(map[uuid.UUID][]sample)
+	for evaluatorID, options := range b.evaluatorScores() {
+		weight := b.evaluatorWeight(evaluatorID)
+		if weight <= 0 {
+			continue
+		}
+		for optionID, score := range options {
+			samples[optionID] = append(samples[optionID], sample{score, weight})
+		}
+	}
+	for _, values := range samples {
+		sort.Slice(values, func(i, j int) bool { return values[i].value < values[j].value })
+	}
+	return samples
+}
+
+func weightedMean(b *Ballots) map[uuid.UUID]float64 {
+	return meanOfScores(b, func(Score) float64 { return 1 })
+}
+
+func confidenceWeightedMean(b *Ballots) map[uuid.UUID]float64 {
+	return meanOfScores(b, func(s Score) float64 { return float64(max(s.Confidence, 1)) })
+}
+
+// meanOfScores is the mean score per option, each score weighted by its criterion and by factor
+func meanOfScores(b *Ballots, factor func(Score) float64) map[uuid.UUID]float64 {
+	weights := b.criterionWeights()
+	weighted := make(map[uuid.UUID]float64)
+	total := make(map[uuid.UUID]float64)
+	for _, s := range b.Scores {
+		w := weights[s.CriteriaID] * factor(s) * b.evaluatorWeight(s.EvaluatorID)
+		weighted[s.OptionID] += s.Score * w
+		total[s.OptionID] += w
+	}
+
+	result := make(map[uuid.UUID]float64, len(b.Options))
+	for _, option := range b.Options {
+		if total[option.ID] > 0 {
+			result[option.ID] = weighted[option.ID] / total[option.ID]
+		}
+	}
+	return result
+}
+
+// bordaCount gives each option one point per option it beats on an evaluator's ballot
+// (half a point per tie), averaged over evaluators
+func bordaCount(b *Ballots) map[uuid.UUID]float64 {
+	points := make(map[uuid.UUID]float64, len(b.Options))
+	totalWeight := 0.0
+	for evaluatorID, scores := range b.evaluatorScores() {
+		weight := b.evaluatorWeight(evaluatorID)
+		totalWeight += weight
+		for optionID, score := range scores {
+			for otherID, other := range scores {
+				switch {
+				case otherID == optionID:
+				case score > other:
+					points[optionID] += weight
+				case score == other:
+					points[optionID] += weight / 2
+				}
+			}
+		}
+	}
+
+	result := make(map[uuid.UUID]float64, len(b.Options))
+	for _, option := range b.Options {
+		if totalWeight > 0 {
+			result[option.ID] = points[option.ID] / totalWeight
+		}
+	}
+	return result
+}
+
+// median is the weighted median; with equal weights it is the usual median
+func median(b *Ballots) map[uuid.UUID]float64 {
+	samples := b.optionSamples()
+	result := make(map[uuid.UUID]float64, len(b.Options))
+	for _, option := range b.Options {
+		values := samples[option.ID]
+		if len(values) == 0 {
+			continue
+		}
+
+		total := 0.0
+		for _, v := range values {
+			total += v.weight
+		}
+		cumulative := 0.0
+		for i, v := range values {
+			cumulative += v.weight
+			if cumulative >= total/2 {
+				result[option.ID] = v.value
+				// Exactly half the weight on each side: split the difference, as for an even count
+				if cumulative == total/2 && i+1 < len(values) {
+					result[option.ID] = (v.value + values[i+1].value) / 2
+				}
+				break
+			}
+		}
+	}
+	return result
+}
+
+// trimmedMean drops the lowest and highest scores by count, then takes the weighted mean of the rest
+func trimmedMean(b *Ballots) map[uuid.UUID]float64 {
+	samples := b.optionSamples()
+	result := make(map[uuid.UUID]float64, len(b.Options))
+	for _, option := range b.Options {
+		values := samples[option.ID]
+		if len(values) == 0 {
+			continue
+		}
+		trim := int(float64(len(values)) * trimProportion)
+		values = values[trim : len(values)-trim]
+
+		var sum, total float64
+		for _, v := range values {
+			sum += v.value * v.weight
+			total += v.weight
+		}
+		result[option.ID] = sum / total
+	}
+	return result
+}
+
+// topsis ranks options by relative closeness to the ideal solution, using each option's
+// mean score per criterion as the decision matrix; every criterion is a benefit criterion
+func topsis(b *Ballots) map[uuid.UUID]float64 {
+	if len(b.Options) == 0 || len(b.Criteria) == 0 {
+		return map[uuid.UUID]float64{}
+	}
+
+	type cell struct{ optionID, criteriaID uuid.UUID }
+	sums := make(map[cell]float64)
+	totals := make(map[cell]float64)
+	scored := make(map[uuid.UUID]bool)
+	for _, s := range b.Scores {
+		weight := b.evaluatorWeight(s.EvaluatorID)
+		if weight <= 0 {
+			continue
+		}
+		key := cell{s.OptionID, s.CriteriaID}
+		sums[key] += s.Score * weight
+		totals[key] += weight
+		scored[s.OptionID] = true
+	}
+
+	totalWeight := 0.0
+	for _, criterion := range b.Criteria {
+		totalWeight += criterion.Weight
+	}
+
+	// Weighted, vector-normalised matrix and the ideal/anti-ideal value per criterion
+	matrix := make(map[cell]float64, len(b.Options)*len(b.Criteria))
+	ideal := make(map[uuid.UUID]float64, len(b.Criteria))
+	antiIdeal := make(map[uuid.UUID]float64, len(b.Criteria))
+	for _, criterion := range b.Criteria {
+		norm := 0.0
+		for _, option := range b.Options {
+			key := cell{option.ID, criterion.ID}
+			if totals[key] > 0 {
+				mean := sums[key] / totals[key]
+				matrix[key] = mean
+				norm += mean * mean
+			}
+		}
+		norm = math.Sqrt(norm)
+
+		weight := 1.0 / float64(len(b.Criteria))
+		if totalWeight > 0 {
+			weight = criterion.Weight / totalWeight
+		}
+		for i, option := range b.Options {
+			key := cell{option.ID, criterion.ID}
+			if norm > 0 {
+				matrix[key] = matrix[key] / norm * weight
+			}
+			if i == 0 || matrix[key] > ideal[criterion.ID] {
+				ideal[criterion.ID] = matrix[key]
+			}
+			if i == 0 || matrix[key] < antiIdeal[criterion.ID] {
+				antiIdeal[criterion.ID] = matrix[key]
+			}
+		}
+	}
+
+	result := make(map[uuid.UUID]float64, len(b.Options))
+	for _, option := range b.Options {
+		if !scored[option.ID] {
+			continue
+		}
+		var toIdeal, toAntiIdeal float64
+		for _, criterion := range b.Criteria {
+			v := matrix[cell{option.ID, criterion.ID}]
+			toIdeal += (v - ideal[criterion.ID]) * (v - ideal[criterion.ID])
+			toAntiIdeal += (v - antiIdeal[criterion.ID]) * (v - antiIdeal[criterion.ID])
+		}
+		toIdeal, toAntiIdeal = math.Sqrt(toIdeal), math.Sqrt(toAntiIdeal)
+		if toIdeal+toAntiIdeal == 0 {
+			result[option.ID] = 1 // every option is identical, so each is ideal
+			continue
+		}
+		result[option.ID] = toAntiIdeal / (toIdeal + toAntiIdeal)
+	}
+	return result
+}
+
+// varianceConsensus averages ConsensusMetrics over the options that have scores
+func varianceConsensus(b *Ballots) float64 {
+	byOption := make(map[uuid.UUID][]float64)
+	for _, s := range b.Scores {
+		byOption[s.OptionID] = append(byOption[s.OptionID], s.Score)
+	}
+
+	total, scored := 0.0, 0
+	for _, option := range b.Options {
+		scores := byOption[option.ID]
+		if len(scores) == 0 {
+			continue
+		}
+		consensus, _ := ConsensusMetrics(sampleVariance(scores))
+		total += consensus
+		scored++
+	}
+	if scored == 0 {
+		return 0
+	}
+	return total / float64(scored)
+}
+
+// kendallW is Kendall's W with tie correction over evaluators who scored every ranked option
+func kendallW(b *Ballots) float64 {
+	evaluators := b.evaluatorScores()
+
+	// Rank only options somebody scored, and only evaluators who scored all of them
+	optionIDs := []uuid.UUID{}
+	for _, option := range b.Options {
+		for _, scores := range evaluators {
+			if _, ok := scores[option.ID]; ok {
+				optionIDs = append(optionIDs, option.ID)
+				break
+			}
+		}
+	}
+	n := len(optionIDs)
+
+	rankSums := make([]float64, n)
+	m, tieCorrection := 0, 0.0
+	for _, scores := range evaluators {
+		values := make([]float64, 0, n)
+		for _, optionID := range optionIDs {
+			score, ok := scores[optionID]
+			if !ok {
+				break
+			}
+			values = append(values, score)
+		}
+		if len(values) < n {
+			continue
+		}
+
+		ranks, ties := averageRanks(values)
+		for i, rank := range ranks {
+			rankSums[i] += rank
+		}
+		tieCorrection += ties
+		m++
+	}
+	if m < 2 || n < 2 {
+		return 1 // a single ranking cannot disagree with itself
+	}
+
+	mean := float64(m) * float64(n+1) / 2
+	s := 0.0
+	for _, sum := range rankSums {
+		s += (sum - mean) * (sum - mean)
+	}
+	mf, nf := float64(m), float64(n)
+	denominator := mf*mf*(nf*nf*nf-nf) - mf*tieCorrection
+	if denominator <= 0 {
+		return 1 // every evaluator tied every option
+	}
+	return clamp01(12 * s / denominator)
+}
+
+// averageRanks ranks values in descending order, giving ties their average rank.
+// It also returns the sum of t^3 - t over tie groups for Kendall's tie correction.
+func averageRanks(values []float64) ([]float64, float64) {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return values[order[i]] > values[order[j]] })
+
+	ranks := make([]float64, len(values))
+	ties := 0.0
+	for start := 0; start < len(order); {
+		end := start
+		for end+1 < len(order) && values[order[end+1]] == values[order[start]] {
+			end++
+		}
+		rank := float64(start+end)/2 + 1
+		for k := start; k <= end; k++ {
+			ranks[order[k]] = rank
+		}
+		if t := float64(end - start + 1); t > 1 {
+			ties += t*t*t - t
+		}
+		start = end + 1
+	}
+	return ranks, ties
+}
+
+// krippendorffAlpha is interval-metric alpha with each option x criterion cell as a unit
+// and evaluators as coders; negative alpha (systematic disagreement) counts as no consensus
+func krippendorffAlpha(b *Ballots) float64 {
+	type cell struct{ optionID, criteriaID uuid.UUID }
+	units := make(map[cell][]float64)
+	for _, s := range b.Scores {
+		key := cell{s.OptionID, s.CriteriaID}
+		units[key] = append(units[key], s.Score)
+	}
+
+	// Only units with two or more values can be paired
+	var observed, n, sum, sumSquares float64
+	for _, values := range units {
+		if len(values) < 2 {
+			continue
+		}
+		observed += pairwiseSquaredDifferences(values) / float64(len(values)-1)
+		for _, v := range values {
+			n++
+			sum += v
+			sumSquares += v * v
+		}
+	}
+	if n < 2 {
+		return 1
+	}
+
+	expected := 2 * (n*sumSquares - sum*sum) / (n * (n - 1))
+	if expected == 0 {
+		return 1 // every score identical
+	}
+	return clamp01(1 - (observed/n)/expected)
+}
+
+// pairwiseSquaredDifferences sums (a-b)^2 over all ordered pairs of distinct elements
+func pairwiseSquaredDifferences(values []float64) float64 {
+	var sum, sumSquares float64
+	for _, v := range values {
+		sum += v
+		sumSquares += v * v
+	}
+	return 2 * (float64(len(values))*sumSquares - sum*sum)
+}
+
+// sampleVariance matches SQL VARIANCE: zero for fewer than two values
+func sampleVariance(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return sum / float64(len(values)-1)
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
@@ -0,0 +1,208 @@
+package evaluation
+
+import (
+	"testing"
+
+	"choseby-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ballotFixture builds ballots from per-evaluator scores: scores[evaluator][option][criterion]
+func ballotFixture(weights []float64, scores [][][]float64) (*Ballots, []uuid.UUID) {
+	b := &Ballots{}
+	optionIDs := []uuid.UUID{}
+	for i := range scores[0] {
+		id := uuid.New()
+		optionIDs = append(optionIDs, id)
+		b.Options = append(b.Options, Option{ID: id, Title: string(rune('A' + i))})
+	}
+	for _, weight := range weights {
+		b.Criteria = append(b.Criteria, Criterion{ID: uuid.New(), Weight: weight})
+	}
+	for _, evaluator := range scores {
+		evaluatorID := uuid.New()
+		for o, options := range evaluator {
+			for c, score := range options {
+				b.Scores = append(b.Scores, Score{
+					EvaluatorID: evaluatorID,
+					OptionID:    optionIDs[o],
+					CriteriaID:  b.Criteria[c].ID,
+					Score:       score,
+					Confidence:  5,
+				})
+			}
+		}
+	}
+	return b, optionIDs
+}
+
+func TestWeightedMeanUsesCriterionWeights(t *testing.T) {
+	b, options := ballotFixture([]float64{3, 1}, [][][]float64{
+		{{8, 4}, {5, 5}},
+	})
+
+	scores := weightedMean(b)
+	assert.InDelta(t, 7.0, scores[options[0]], 1e-9) // (8*3 + 4*1) / 4
+	assert.InDelta(t, 5.0, scores[options[1]], 1e-9)
+}
+
+func TestConfidenceWeightedMeanFavoursConfidentScores(t *testing.T) {
+	b, options := ballotFixture([]float64{1}, [][][]float64{
+		{{9}}, {{3}},
+	})
+	b.Scores[0].Confidence = 5
+	b.Scores[1].Confidence = 1
+
+	assert.InDelta(t, 8.0, confidenceWeightedMean(b)[options[0]], 1e-9) // (9*5 + 3*1) / 6
+}
+
+func TestBordaCountAveragesPointsPerEvaluator(t *testing.T) {
+	b, options := ballotFixture([]float64{1}, [][][]float64{
+		{{9}, {5}, {1}},
+		{{9}, {1}, {5}},
+	})
+
+	scores := bordaCount(b)
+	assert.InDelta(t, 2.0, scores[options[0]], 1e-9)
+	assert.InDelta(t, 0.5, scores[options[1]], 1e-9)
+	assert.InDelta(t, 0.5, scores[options[2]], 1e-9)
+}
+
+func TestMedianAndTrimmedMeanResistOutliers(t *testing.T) {
+	b, options := ballotFixture([]float64{1}, [][][]float64{
+		{{6}}, {{7}}, {{7}}, {{8}}, {{1}},
+	})
+
+	assert.InDelta(t, 7.0, median(b)[options[0]], 1e-9)
+	assert.InDelta(t, 20.0/3.0, trimmedMean(b)[options[0]], 1e-9) // 1 and 8 trimmed
+	assert.InDelta(t, 5.8, weightedMean(b)[options[0]], 1e-9)
+}
+
+func TestTOPSISRanksDominantOptionFirst(t *testing.T) {
+	b, options := ballotFixture([]float64{2, 1}, [][][]float64{
+		{{9, 8}, {5, 5}, {2, 1}},
+	})
+
+	scores := topsis(b)
+	assert.InDelta(t, 1.0, scores[options[0]], 1e-9)
+	assert.InDelta(t, 0.0, scores[options[2]], 1e-9)
+	assert.Greater(t, scores[options[1]], scores[options[2]])
+}
+
+func TestKendallW(t *testing.T) {
+	agree, _ := ballotFixture([]float64{1}, [][][]float64{
+		{{9}, {6}, {3}},
+		{{8}, {5}, {2}},
+	})
+	assert.InDelta(t, 1.0, kendallW(agree), 1e-9)
+
+	opposite, _ := ballotFixture([]float64{1}, [][][]float64{
+		{{9}, {6}, {3}},
+		{{3}, {6}, {9}},
+	})
+	assert.InDelta(t, 0.0, kendallW(opposite), 1e-9)
+
+	// Rank sums 4, 6, 8 over three evaluators: W = 12*8 / (9*24)
+	mixed, _ := ballotFixture([]float64{1}, [][][]float64{
+		{{9}, {6}, {3}},
+		{{9}, {3}, {6}},
+		{{6}, {9}, {3}},
+	})
+	assert.InDelta(t, 4.0/9.0, kendallW(mixed), 1e-9)
+}
+
+func TestKrippendorffAlpha(t *testing.T) {
+	// Two evaluators, two cells: {1,2} and {3,4} give Do = 1, De = 10/3
+	b, _ := ballotFixture([]float64{1, 1}, [][][]float64{
+		{{1, 3}},
+		{{2, 4}},
+	})
+	assert.InDelta(t, 0.7, krippendorffAlpha(b), 1e-9)
+
+	identical, _ := ballotFixture([]float64{1}, [][][]float64{{{5}}, {{5}}})
+	assert.InDelta(t, 1.0, krippendorffAlpha(identical), 1e-9)
+}
+
+func TestVarianceConsensusSkipsUnscoredOptions(t *testing.T) {
+	// Scores 2 and 6 have sample variance 8, so consensus 0.2; the unscored option must not count as 1
+	b, _ := ballotFixture([]float64{1}, [][][]float64{{{2}}, {{6}}})
+	b.Options = append(b.Options, Option{ID: uuid.New(), Title: "Unscored"})
+	assert.InDelta(t, 0.2, varianceConsensus(b), 1e-9)
+
+	assert.Zero(t, varianceConsensus(&Ballots{Options: b.Options}))
+}
+
+func TestRankOrdersOptionsAndRecommendsTheTop(t *testing.T) {
+	b, options := ballotFixture([]float64{1}, [][][]float64{
+		{{4}, {9}},
+		{{5}, {8}},
+	})
+
+	results, err := Rank(b, MethodBorda, ConsensusKendallW)
+	require.NoError(t, err)
+	require.Len(t, results.OptionScores, 2)
+	assert.Equal(t, options[1], results.OptionScores[0].OptionID)
+	assert.Equal(t, options[1], *results.RecommendedOption)
+	assert.Equal(t, 2, results.OptionScores[0].Evaluators)
+	assert.InDelta(t, 1.0, results.TeamConsensus, 1e-9)
+	assert.Equal(t, MethodBorda, results.AggregationMethod)
+}
+
+func TestRankDefaultsAndRejectsUnknownMethods(t *testing.T) {
+	b, _ := ballotFixture([]float64{1}, [][][]float64{{{5}}})
+
+	results, err := Rank(b, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, MethodWeightedMean, results.AggregationMethod)
+	assert.Equal(t, ConsensusVariance, results.ConsensusMethod)
+
+	_, err = Rank(b, "coin_flip", "")
+	assert.ErrorIs(t, err, ErrUnknownMethod)
+	_, err = Rank(b, "", "vibes")
+	assert.ErrorIs(t, err, ErrUnknownMethod)
+}
+
+func TestRankForDecisionComparesWeightedAndUnweighted(t *testing.T) {
+	b, options := ballotFixture([]float64{1}, [][][]float64{
+		{{9}, {4}},
+		{{8}, {5}},
+		{{2}, {9}},
+	})
+	// The third evaluator is legal, whose view outweighs the other two
+	for i := range b.Scores {
+		b.Scores[i].EvaluatorRole = "customer_success_manager"
+		b.Scores[i].EscalationAuthority = 1
+	}
+	b.Scores[4].EvaluatorRole, b.Scores[5].EvaluatorRole = "legal_compliance", "legal_compliance"
+	b.Scores[4].EscalationAuthority, b.Scores[5].EscalationAuthority = 3, 3
+
+	decision := &models.CustomerDecision{StakeholderWeighting: &models.StakeholderWeighting{
+		Source:                 "manual",
+		RoleWeights:            map[string]float64{"legal_compliance": 2},
+		DefaultWeight:          1,
+		UseEscalationAuthority: true,
+	}}
+
+	results, err := RankForDecision(b, decision)
+	require.NoError(t, err)
+	require.NotNil(t, results.StakeholderWeighting)
+
+	comparison := results.StakeholderWeighting
+	assert.Equal(t, options[1], *results.RecommendedOption)
+	assert.Equal(t, options[0], *comparison.UnweightedRecommendedOption)
+	assert.True(t, comparison.RecommendationChanged)
+	assert.Equal(t, options[1], comparison.Weighted[0].OptionID)
+	assert.Equal(t, 1, comparison.Weighted[0].RankChange)
+	// (4 + 5 + 9*6) / 8
+	assert.InDelta(t, 63.0/8.0, comparison.Weighted[0].Score, 1e-9)
+}
+
+func TestRankForDecisionWithoutWeightingIsUnchanged(t *testing.T) {
+	b, _ := ballotFixture([]float64{1}, [][][]float64{{{5}, {7}}})
+
+	results, err := RankForDecision(b, &models.CustomerDecision{})
+	require.NoError(t, err)
+	assert.Nil(t, results.StakeholderWeighting)
+}
@@ -0,0 +1,207 @@
+package evaluation
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+
+	"choseby-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// DefaultMinEvaluators is the anonymity threshold of a team that has not chosen one
+const DefaultMinEvaluators = 3
+
+// OtherRoles is the bucket for roles that only one member of the team holds
+const OtherRoles = "other"
+
+// Privacy is what a team's anonymity threshold allows to be shown about a decision's evaluations.
+// Below the threshold, score distributions and comments could be traced back to individual members.
+type Privacy struct {
+	MinEvaluators int
+	Evaluators    int            // members who have evaluated the decision
+	RoleMembers   map[string]int // active team members per role
+}
+
+// LoadPrivacy reads the team's threshold and role make-up and how many members have evaluated the decision
+func LoadPrivacy(ctx context.Context, db sqlx.QueryerContext, decision *models.CustomerDecision) (*Privacy, error) {
+	privacy := &Privacy{MinEvaluators: DefaultMinEvaluators, RoleMembers: make(map[string]int)}
+
+	if err := sqlx.GetContext(ctx, db, &privacy.MinEvaluators, `
+		SELECT min_anonymous_evaluators FROM teams WHERE id = $1
+	`, decision.TeamID); err != nil {
+		return nil, err
+	}
+	if err := sqlx.GetContext(ctx, db, &privacy.Evaluators, `
+		SELECT COUNT(DISTINCT evaluator_id) FROM evaluations WHERE decision_id = $1
+	`, decision.ID); err != nil {
+		return nil, err
+	}
+
+	var roles []struct {
+		Role    string `db:"role"`
+		Members int    `db:"members"`
+	}
+	if err := sqlx.SelectContext(ctx, db, &roles, `
+		SELECT role, COUNT(*) AS members FROM team_members WHERE team_id = $1 AND is_active = true GROUP BY role
+	`, decision.TeamID); err != nil {
+		return nil, err
+	}
+	for _, r := range roles {
+		privacy.RoleMembers[r.Role] = r.Members
+	}
+	return privacy, nil
+}
+
+// LoadComments returns the decision's anonymous comments in random order, without evaluators or timestamps
+func LoadComments(ctx context.Context, db sqlx.QueryerContext, decisionID uuid.UUID) ([]models.AnonymousComment, error) {
+	comments := []models.AnonymousComment{}
+	if err := sqlx.SelectContext(ctx, db, &comments, `
+		SELECT option_id, criteria_id, anonymous_comment AS comment
+		FROM evaluations
+		WHERE decision_id = $1 AND anonymous_comment IS NOT NULL AND anonymous_comment <> ''
+	`, decisionID); err != nil {
+		return nil, err
+	}
+
+	// Storage order follows submission order, which would hint at who wrote what
+	rand.Shuffle(len(comments), func(i, j int) { comments[i], comments[j] = comments[j], comments[i] })
+	return comments, nil
+}
+
+// Withholds reports whether detail drawn from this many evaluators must be held back
+func (p *Privacy) Withholds(evaluators int) bool {
+	return evaluators < p.MinEvaluators
+}
+
+// Role is how a member's role may be shown. A role only one member holds is pooled into OtherRoles;
+// if that pool is a single member as well, the role is not shown at all and "" is returned.
+func (p *Privacy) Role(role string) string {
+	if p.RoleMembers[role] > 1 {
+		return role
+	}
+	pooled := 0
+	for _, members := range p.RoleMembers {
+		if members == 1 {
+			pooled++
+		}
+	}
+	if pooled > 1 {
+		return OtherRoles
+	}
+	return ""
+}
+
+// Apply holds back whatever the threshold does not allow: per-option scores, distributions and comments
+// of options too few members have scored, team-wide consensus below the threshold, and roles that single
+// someone out. The ranking order stays. The comments are added to the results.
+func (p *Privacy) Apply(results *models.EvaluationResults, comments []models.AnonymousComment) {
+	notice := &models.AnonymityNotice{MinEvaluators: p.MinEvaluators, Evaluators: p.Evaluators}
+	results.Anonymity = notice
+	results.CompletedBy = p.roles(results.CompletedBy)
+	results.PendingFrom = p.roles(results.PendingFrom)
+
+	withheld := make(map[uuid.UUID]bool)
+	for i := range results.OptionScores {
+		option := &results.OptionScores[i]
+		if !p.Withholds(option.Evaluators) && !p.Withholds(p.Evaluators) {
+			continue
+		}
+		withheld[option.OptionID] = true
+		// With one or two evaluators an average is close to being their own score
+		option.AverageScore, option.WeightedScore = 0, 0
+		option.Evaluators, option.Consensus, option.ConflictLevel = 0, 0, ""
+		option.DistributionWithheld = true
+		notice.Withheld = true
+	}
+
+	if p.Withholds(p.Evaluators) {
+		results.TeamConsensus = 0
+		// The gap between weighted and unweighted scores would show how the weighted roles scored
+		if results.StakeholderWeighting != nil {
+			results.StakeholderWeighting.Unweighted = nil
+		}
+		notice.Withheld = true
+	}
+
+	results.Comments = []models.AnonymousComment{}
+	for _, comment := range comments {
+		if withheld[comment.OptionID] {
+			notice.Withheld = true
+			continue
+		}
+		results.Comments = append(results.Comments, comment)
+	}
+}
+
+// Sensitivity holds back the score breakdown of options too few members have scored. Below the team-wide
+// threshold the flip weights alone would trace back to individual scores, so it returns false and the
+// report must not be shown at all.
+func (p *Privacy) Sensitivity(report *SensitivityReport, ballots *Ballots) bool {
+	if p.Withholds(p.Evaluators) {
+		return false
+	}
+	notice := &models.AnonymityNotice{MinEvaluators: p.MinEvaluators, Evaluators: p.Evaluators}
+	report.Anonymity = notice
+
+	evaluators := make(map[uuid.UUID]map[uuid.UUID]bool)
+	for _, s := range ballots.Scores {
+		if evaluators[s.OptionID] == nil {
+			evaluators[s.OptionID] = make(map[uuid.UUID]bool)
+		}
+		evaluators[s.OptionID][s.EvaluatorID] = true
+	}
+	for i := range report.Contributions {
+		option := &report.Contributions[i]
+		if !p.Withholds(len(evaluators[option.OptionID])) {
+			continue
+		}
+		option.Total, option.Withheld = 0, true
+		for j := range option.Criteria {
+			part := &option.Criteria[j]
+			part.AverageScore, part.Contribution, part.ShareOfTotal = 0, 0, 0
+		}
+		notice.Withheld = true
+	}
+	return true
+}
+
+// Conflicts drops option spreads drawn from too few evaluators, and criteria left with none
+func (p *Privacy) Conflicts(conflicts []CriterionConflict) []CriterionConflict {
+	visible := []CriterionConflict{}
+	for _, conflict := range conflicts {
+		options := []OptionSpread{}
+		for _, spread := range conflict.Options {
+			if !p.Withholds(spread.Evaluators) && !p.Withholds(p.Evaluators) {
+				options = append(options, spread)
+			}
+		}
+		if len(options) == 0 {
+			continue
+		}
+		// Options stay sorted most contested first, so the first one sets the criterion's level
+		conflict.Options = options
+		conflict.Variance, conflict.ConflictLevel = options[0].Variance, options[0].ConflictLevel
+		visible = append(visible, conflict)
+	}
+	sort.SliceStable(visible, func(i, j int) bool {
+		return visible[i].Variance > visible[j].Variance
+	})
+	return visible
+}
+
+// roles buckets a role list, dropping duplicates the bucketing creates
+func (p *Privacy) roles(roles []string) []string {
+	seen := make(map[string]bool)
+	shown := []string{}
+	for _, role := range roles {
+		role = p.Role(role)
+		if role == "" || seen[role] {
+			continue
+		}
+		seen[role] = true
+		shown = append(shown, role)
+	}
+	return shown
+}
@@ -0,0 +1,139 @@
+package evaluation
+
+import (
+	"context"
+	"testing"
+
+	"choseby-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivacyRoleBucketsSingleMemberRoles(t *testing.T) {
+	privacy := &Privacy{RoleMembers: map[string]int{
+		"customer_success_manager": 3,
+		"legal_compliance":         1,
+		"sales_manager":            1,
+	}}
+
+	assert.Equal(t, "customer_success_manager", privacy.Role("customer_success_manager"))
+	assert.Equal(t, OtherRoles, privacy.Role("legal_compliance"))
+	assert.Equal(t, OtherRoles, privacy.Role("sales_manager"))
+
+	// A pool of one would still point at a single person
+	lonely := &Privacy{RoleMembers: map[string]int{"support_manager": 2, "legal_compliance": 1}}
+	assert.Equal(t, "", lonely.Role("legal_compliance"))
+}
+
+func TestPrivacyApplyWithholdsBelowThreshold(t *testing.T) {
+	b, options := ballotFixture([]float64{1}, [][][]float64{
+		{{9}, {4}},
+		{{2}, {5}},
+	})
+	results, err := Rank(b, "", "")
+	require.NoError(t, err)
+	results.CompletedBy = []string{"support_manager", "legal_compliance"}
+
+	comments := []models.AnonymousComment{{OptionID: options[0], CriteriaID: b.Criteria[0].ID, Comment: "Too costly"}}
+	privacy := &Privacy{MinEvaluators: 3, Evaluators: 2, RoleMembers: map[string]int{"support_manager": 2, "legal_compliance": 1}}
+	privacy.Apply(results, comments)
+
+	require.NotNil(t, results.Anonymity)
+	assert.True(t, results.Anonymity.Withheld)
+	assert.Zero(t, results.TeamConsensus)
+	assert.Empty(t, results.Comments)
+	assert.Equal(t, []string{"support_manager"}, results.CompletedBy)
+	for _, option := range results.OptionScores {
+		assert.True(t, option.DistributionWithheld)
+		assert.Zero(t, option.Evaluators)
+		assert.Empty(t, option.ConflictLevel)
+	}
+	// The ranking itself is still shown
+	assert.NotNil(t, results.RecommendedOption)
+}
+
+func TestPrivacyApplyWithholdsOnlyThinlyScoredOptions(t *testing.T) {
+	b, options := ballotFixture([]float64{1}, [][][]float64{
+		{{9}, {4}},
+		{{8}, {5}},
+	})
+	// A third evaluator scores only the first option
+	b.Scores = append(b.Scores, Score{EvaluatorID: uuid.New(), OptionID: options[0], CriteriaID: b.Criteria[0].ID, Score: 7, Confidence: 5})
+	results, err := Rank(b, "", "")
+	require.NoError(t, err)
+
+	comments := []models.AnonymousComment{
+		{OptionID: options[0], Comment: "Fair"},
+		{OptionID: options[1], Comment: "Cheap"},
+	}
+	privacy := &Privacy{MinEvaluators: 3, Evaluators: 3}
+	privacy.Apply(results, comments)
+
+	for _, option := range results.OptionScores {
+		assert.Equal(t, option.OptionID == options[1], option.DistributionWithheld)
+		if option.DistributionWithheld {
+			assert.Zero(t, option.AverageScore)
+			assert.Zero(t, option.WeightedScore)
+		} else {
+			assert.InDelta(t, 8.0, option.AverageScore, 1e-9)
+		}
+	}
+	require.Len(t, results.Comments, 1)
+	assert.Equal(t, "Fair", results.Comments[0].Comment)
+	assert.True(t, results.Anonymity.Withheld)
+
+	export := NewExport(&models.CustomerDecision{}, b, results, nil)
+	assert.True(t, export.ScoresWithheld)
+	for _, row := range export.Scores {
+		assert.Equal(t, options[0], row.OptionID)
+	}
+	for _, aggregate := range export.Aggregates {
+		if aggregate.OptionID == options[1] {
+			assert.Equal(t, []*float64{nil}, aggregate.CriterionMean)
+			assert.Zero(t, aggregate.AverageScore)
+		} else {
+			require.NotNil(t, aggregate.CriterionMean[0])
+			assert.InDelta(t, 8.0, *aggregate.CriterionMean[0], 1e-9)
+		}
+	}
+}
+
+func TestPrivacySensitivityWithholdsThinBreakdowns(t *testing.T) {
+	b, options := ballotFixture([]float64{1, 1}, [][][]float64{
+		{{9, 3}, {4, 7}},
+		{{8, 4}, {5, 6}},
+	})
+	// A third evaluator scores only the first option
+	b.Scores = append(b.Scores, Score{EvaluatorID: uuid.New(), OptionID: options[0], CriteriaID: b.Criteria[0].ID, Score: 7, Confidence: 5})
+
+	report, err := Sensitivity(context.Background(), b, &models.CustomerDecision{}, WeightRange{Min: 0.1, Max: 5, Step: 0.1})
+	require.NoError(t, err)
+	require.True(t, (&Privacy{MinEvaluators: 3, Evaluators: 3}).Sensitivity(report, b))
+
+	require.NotNil(t, report.Anonymity)
+	assert.True(t, report.Anonymity.Withheld)
+	for _, option := range report.Contributions {
+		assert.Equal(t, option.OptionID == options[1], option.Withheld)
+		for _, part := range option.Criteria {
+			assert.Equal(t, option.Withheld, part.AverageScore == 0)
+		}
+	}
+
+	// Below the team-wide threshold the report is refused outright
+	report, err = Sensitivity(context.Background(), b, &models.CustomerDecision{}, WeightRange{Min: 0.1, Max: 5, Step: 0.1})
+	require.NoError(t, err)
+	assert.False(t, (&Privacy{MinEvaluators: 4, Evaluators: 3}).Sensitivity(report, b))
+}
+
+func TestPrivacyConflictsDropThinSpreads(t *testing.T) {
+	b, _ := ballotFixture([]float64{1, 1}, [][][]float64{
+		{{9, 1}},
+		{{2, 9}},
+	})
+	conflicts := CriterionConflicts(b)
+	require.Len(t, conflicts, 2)
+
+	assert.Empty(t, (&Privacy{MinEvaluators: 3, Evaluators: 2}).Conflicts(conflicts))
+	assert.Len(t, (&Privacy{MinEvaluators: 2, Evaluators: 2}).Conflicts(conflicts), 2)
+}
@@ -0,0 +1,97 @@
+package evaluation
+
+import (
+	"sort"
+
+	"choseby-backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// CriterionConflict is evaluator disagreement on one criterion, found from the spread of its scores per option
+type CriterionConflict struct {
+	CriteriaID    uuid.UUID      `json:"criteria_id"`
+	CriteriaName  string         `json:"criteria_name"`
+	Variance      float64        `json:"variance"` // the largest score variance over the options
+	ConflictLevel string         `json:"conflict_level"`
+	Options       []OptionSpread `json:"options"` // contested options, most contested first
+}
+
+// OptionSpread is how far apart evaluators scored one option on a criterion
+type OptionSpread struct {
+	OptionID      uuid.UUID `json:"option_id"`
+	OptionTitle   string    `json:"option_title"`
+	Evaluators    int       `json:"evaluators"`
+	MinScore      float64   `json:"min_score"`
+	MaxScore      float64   `json:"max_score"`
+	Variance      float64   `json:"variance"`
+	ConflictLevel string    `json:"conflict_level"`
+}
+
+// CriterionConflicts lists the criteria evaluators disagree on, most contested first.
+// Each option is judged on its own score variance with the thresholds of ConsensusMetrics.
+func CriterionConflicts(ballots *Ballots) []CriterionConflict {
+	type cell struct{ criteriaID, optionID uuid.UUID }
+	scores := make(map[cell][]float64)
+	for _, s := range ballots.Scores {
+		key := cell{s.CriteriaID, s.OptionID}
+		scores[key] = append(scores[key], s.Score)
+	}
+
+	conflicts := []CriterionConflict{}
+	for _, criterion := range ballots.Criteria {
+		conflict := CriterionConflict{
+			CriteriaID:    criterion.ID,
+			CriteriaName:  criterion.Name,
+			ConflictLevel: models.PriorityNone,
+			Options:       []OptionSpread{},
+		}
+		for _, option := range ballots.Options {
+			values := scores[cell{criterion.ID, option.ID}]
+			variance := sampleVariance(values)
+			_, level := ConsensusMetrics(variance)
+			if level == models.PriorityNone {
+				continue
+			}
+
+			spread := OptionSpread{
+				OptionID:      option.ID,
+				OptionTitle:   option.Title,
+				Evaluators:    len(values),
+				MinScore:      values[0],
+				MaxScore:      values[0],
+				Variance:      variance,
+				ConflictLevel: level,
+			}
+			for _, v := range values {
+				spread.MinScore = min(spread.MinScore, v)
+				spread.MaxScore = max(spread.MaxScore, v)
+			}
+			conflict.Options = append(conflict.Options, spread)
+
+			if variance > conflict.Variance {
+				conflict.Variance, conflict.ConflictLevel = variance, level
+			}
+		}
+		if len(conflict.Options) == 0 {
+			continue
+		}
+
+		sort.SliceStable(conflict.Options, func(i, j int) bool {
+			return conflict.Options[i].Variance > conflict.Options[j].Variance
+		})
+		conflicts = append(conflicts, conflict)
+	}
+
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		return conflicts[i].Variance > conflicts[j].Variance
+	})
+	return conflicts
+}
+
+// HighestConflictLevel is the conflict level of the most contested criterion
+func HighestConflictLevel(conflicts []CriterionConflict) string {
+	if len(conflicts) == 0 {
+		return models.PriorityNone
+	}
+	return conflicts[0].ConflictLevel
+}
@@ -0,0 +1,54 @@
+package evaluation
+
+import (
+	"testing"
+
+	"choseby-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCriterionConflictsFindsContestedCriteria(t *testing.T) {
+	// Everyone agrees on the first criterion; the second splits the team on option A
+	b, options := ballotFixture([]float64{1, 1}, [][][]float64{
+		{{7, 9}, {5, 6}},
+		{{7, 2}, {5, 5}},
+		{{8, 8}, {5, 6}},
+	})
+
+	conflicts := CriterionConflicts(b)
+	require.Len(t, conflicts, 1)
+
+	conflict := conflicts[0]
+	assert.Equal(t, b.Criteria[1].ID, conflict.CriteriaID)
+	assert.Equal(t, models.PriorityHigh, conflict.ConflictLevel)
+	assert.InDelta(t, 14.333333, conflict.Variance, 1e-6) // scores 9, 2, 8
+
+	require.Len(t, conflict.Options, 1)
+	spread := conflict.Options[0]
+	assert.Equal(t, options[0], spread.OptionID)
+	assert.Equal(t, 3, spread.Evaluators)
+	assert.Equal(t, 2.0, spread.MinScore)
+	assert.Equal(t, 9.0, spread.MaxScore)
+	assert.Equal(t, models.PriorityHigh, HighestConflictLevel(conflicts))
+}
+
+func TestCriterionConflictsOrdersByVariance(t *testing.T) {
+	b, _ := ballotFixture([]float64{1, 1}, [][][]float64{
+		{{4, 1}},
+		{{6, 9}},
+		{{7, 5}},
+	})
+
+	conflicts := CriterionConflicts(b)
+	require.Len(t, conflicts, 2)
+	assert.Equal(t, b.Criteria[1].ID, conflicts[0].CriteriaID)
+	assert.Equal(t, models.PriorityMedium, conflicts[1].ConflictLevel) // scores 4, 6, 7
+}
+
+func TestCriterionConflictsNoneWhenTeamAgrees(t *testing.T) {
+	b, _ := ballotFixture([]float64{1}, [][][]float64{{{6}}, {{7}}})
+
+	assert.Empty(t, CriterionConflicts(b))
+	assert.Equal(t, models.PriorityNone, HighestConflictLevel(nil))
+}
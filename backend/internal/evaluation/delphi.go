@@ -0,0 +1,201 @@
+package evaluation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"choseby-backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// Evaluation modes
+const (
+	ModeSingle = "single" // one ballot per member, overwritten on resubmission
+	ModeDelphi = "delphi" // numbered rounds with anonymised feedback between them
+)
+
+// Reasons a Delphi evaluation stopped; they also close the evaluation snapshot
+const (
+	CloseReasonConsensus = "consensus"
+	CloseReasonMaxRounds = "max_rounds"
+)
+
+// Consensus trends across Delphi rounds
+const (
+	TrendConverging = "converging"
+	TrendDiverging  = "diverging"
+	TrendStable     = "stable"
+)
+
+// trendTolerance is how far consensus may move between rounds and still count as stable
+const trendTolerance = 0.01
+
+// ErrNoFeedbackRound is returned while the first Delphi round is still open
+var ErrNoFeedbackRound = errors.New("no Delphi round has closed yet")
+
+// EvaluationModes lists the valid evaluation modes
+func EvaluationModes() []string {
+	return []string{ModeSingle, ModeDelphi}
+}
+
+// CurrentRound is the round evaluations are scored in; always 1 outside Delphi mode
+func CurrentRound(decision *models.CustomerDecision) int {
+	if decision.DelphiRound < 1 {
+		return 1
+	}
+	return decision.DelphiRound
+}
+
+// StopReason reports whether a Delphi evaluation should stop after its current round,
+// returning CloseReasonConsensus, CloseReasonMaxRounds or "" to run another round
+func StopReason(decision *models.CustomerDecision, teamConsensus float64) string {
+	if teamConsensus >= decision.DelphiConsensusThreshold {
+		return CloseReasonConsensus
+	}
+	if CurrentRound(decision) >= decision.DelphiMaxRounds {
+		return CloseReasonMaxRounds
+	}
+	return ""
+}
+
+// CloseRound records the open Delphi round with its results and comments. If the evaluation should
+// go on, the next round opens with the given deadline; otherwise the round's StopReason is set and the
+// caller closes evaluation with the returned results.
+func CloseRound(ctx context.Context, tx sqlx.ExtContext, decision *models.CustomerDecision, reason string, closedAt, nextDeadline time.Time) (*models.DelphiRound, *models.EvaluationResults, error) {
+	results, err := Compute(ctx, tx, decision)
+	if err != nil {
+		return nil, nil, err
+	}
+	comments, err := LoadComments(ctx, tx, decision.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	round := &models.DelphiRound{
+		DecisionID:    decision.ID,
+		RoundNumber:   CurrentRound(decision),
+		TeamConsensus: results.TeamConsensus,
+		CloseReason:   reason,
+		ClosedAt:      closedAt,
+	}
+	if err := sqlx.GetContext(ctx, tx, &round.Evaluators, `
+		SELECT COUNT(DISTINCT evaluator_id) FROM evaluations WHERE decision_id = $1 AND round_number = $2
+	`, decision.ID, round.RoundNumber); err != nil {
+		return nil, nil, err
+	}
+	if stop := StopReason(decision, results.TeamConsensus); stop != "" {
+		round.StopReason = &stop
+	}
+
+	if round.Results, err = json.Marshal(results); err != nil {
+		return nil, nil, err
+	}
+	if round.Comments, err = json.Marshal(comments); err != nil {
+		return nil, nil, err
+	}
+	_, err = sqlx.NamedExecContext(ctx, tx, `
+		INSERT INTO delphi_rounds (decision_id, round_number, results, comments, team_consensus, evaluators, close_reason, stop_reason, closed_at)
+		VALUES (:decision_id, :round_number, :results, :comments, :team_consensus, :evaluators, :close_reason, :stop_reason, :closed_at)
+	`, round)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if round.StopReason == nil {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE customer_decisions SET delphi_round = $1, evaluation_deadline = $2, updated_at = $3 WHERE id = $4
+		`, round.RoundNumber+1, nextDeadline, closedAt, decision.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		decision.DelphiRound, decision.EvaluationDeadline = round.RoundNumber+1, &nextDeadline
+	}
+	return round, results, nil
+}
+
+// ReopenRound moves a Delphi evaluation that is entering evaluation again past its last recorded round,
+// so earlier rounds keep their history
+func ReopenRound(ctx context.Context, tx sqlx.ExecerContext, decision *models.CustomerDecision) error {
+	if decision.EvaluationMode != ModeDelphi {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, `
+		UPDATE customer_decisions SET delphi_round = delphi_round + 1
+		WHERE id = $1 AND EXISTS (
+			SELECT 1 FROM delphi_rounds WHERE decision_id = $1 AND round_number = customer_decisions.delphi_round
+		)
+	`, decision.ID)
+	return err
+}
+
+// LoadProgress reads the decision's closed Delphi rounds and how consensus moved across them
+func LoadProgress(ctx context.Context, db sqlx.QueryerContext, decision *models.CustomerDecision) (*models.DelphiProgress, error) {
+	rounds := []models.DelphiRound{}
+	if err := sqlx.SelectContext(ctx, db, &rounds, `
+		SELECT decision_id, round_number, team_consensus, evaluators, close_reason, stop_reason, closed_at
+		FROM delphi_rounds WHERE decision_id = $1 ORDER BY round_number
+	`, decision.ID); err != nil {
+		return nil, err
+	}
+
+	progress := &models.DelphiProgress{
+		Round:              CurrentRound(decision),
+		MaxRounds:          decision.DelphiMaxRounds,
+		ConsensusThreshold: decision.DelphiConsensusThreshold,
+		Rounds:             rounds,
+		Trend:              Convergence(rounds),
+	}
+	if n := len(rounds); n > 0 && rounds[n-1].StopReason != nil && rounds[n-1].RoundNumber == progress.Round {
+		progress.StopReason = *rounds[n-1].StopReason
+	}
+	return progress, nil
+}
+
+// Convergence sets each round's change in consensus since the one before and returns the trend of the
+// latest change; "" until two rounds have closed
+func Convergence(rounds []models.DelphiRound) string {
+	trend := ""
+	for i := 1; i < len(rounds); i++ {
+		change := rounds[i].TeamConsensus - rounds[i-1].TeamConsensus
+		rounds[i].ConsensusChange = &change
+		switch {
+		case change > trendTolerance:
+			trend = TrendConverging
+		case change < -trendTolerance:
+			trend = TrendDiverging
+		default:
+			trend = TrendStable
+		}
+	}
+	return trend
+}
+
+// LoadFeedback returns the results and comments of the round before the open one, which members
+// review before re-scoring. It returns ErrNoFeedbackRound during the first round.
+func LoadFeedback(ctx context.Context, db sqlx.QueryerContext, decision *models.CustomerDecision) (*models.EvaluationResults, []models.AnonymousComment, error) {
+	var round models.DelphiRound
+	err := sqlx.GetContext(ctx, db, &round, `
+		SELECT round_number, results, comments, closed_at FROM delphi_rounds WHERE decision_id = $1 AND round_number = $2
+	`, decision.ID, CurrentRound(decision)-1)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, ErrNoFeedbackRound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var results models.EvaluationResults
+	if err := json.Unmarshal(round.Results, &results); err != nil {
+		return nil, nil, err
+	}
+	comments := []models.AnonymousComment{}
+	if err := json.Unmarshal(round.Comments, &comments); err != nil {
+		return nil, nil, err
+	}
+	results.ClosedAt = &round.ClosedAt
+	results.Deadline = decision.EvaluationDeadline
+	return &results, comments, nil
+}
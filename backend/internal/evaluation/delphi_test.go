@@ -0,0 +1,51 @@
+package evaluation
+
+import (
+	"testing"
+
+	"choseby-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStopReasonEndsOnConsensusOrLastRound(t *testing.T) {
+	decision := &models.CustomerDecision{
+		EvaluationMode:           ModeDelphi,
+		DelphiRound:              1,
+		DelphiMaxRounds:          3,
+		DelphiConsensusThreshold: 0.8,
+	}
+
+	assert.Equal(t, "", StopReason(decision, 0.6))
+	assert.Equal(t, CloseReasonConsensus, StopReason(decision, 0.8))
+
+	decision.DelphiRound = 3
+	assert.Equal(t, CloseReasonMaxRounds, StopReason(decision, 0.6))
+	// Reaching consensus on the last round still counts as consensus
+	assert.Equal(t, CloseReasonConsensus, StopReason(decision, 0.9))
+}
+
+func TestCurrentRoundDefaultsToFirst(t *testing.T) {
+	assert.Equal(t, 1, CurrentRound(&models.CustomerDecision{}))
+	assert.Equal(t, 4, CurrentRound(&models.CustomerDecision{DelphiRound: 4}))
+}
+
+func TestConvergenceTracksConsensusChange(t *testing.T) {
+	assert.Equal(t, "", Convergence([]models.DelphiRound{{RoundNumber: 1, TeamConsensus: 0.5}}))
+
+	rounds := []models.DelphiRound{
+		{RoundNumber: 1, TeamConsensus: 0.4},
+		{RoundNumber: 2, TeamConsensus: 0.65},
+		{RoundNumber: 3, TeamConsensus: 0.655},
+	}
+	assert.Equal(t, TrendStable, Convergence(rounds))
+	assert.Nil(t, rounds[0].ConsensusChange)
+	require.NotNil(t, rounds[1].ConsensusChange)
+	assert.InDelta(t, 0.25, *rounds[1].ConsensusChange, 1e-9)
+
+	rounds = append(rounds, models.DelphiRound{RoundNumber: 4, TeamConsensus: 0.8})
+	assert.Equal(t, TrendConverging, Convergence(rounds))
+
+	rounds = append(rounds, models.DelphiRound{RoundNumber: 5, TeamConsensus: 0.6})
+	assert.Equal(t, TrendDiverging, Convergence(rounds))
+}
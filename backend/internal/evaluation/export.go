@@ -0,0 +1,317 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"choseby-backend/internal/csvutil"
+	"choseby-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+)
+
+// Export formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatJSON = "json"
+)
+
+// ErrUnknownFormat is returned for an export format that does not exist
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// ExportFormats lists the supported export formats with their content types
+func ExportFormats() map[string]string {
+	return map[string]string{
+		FormatCSV:  "text/csv; charset=utf-8",
+		FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		FormatJSON: "application/json; charset=utf-8",
+	}
+}
+
+// EvaluatorIdentity is who an evaluator is, included only in identified exports
+type EvaluatorIdentity struct {
+	Name  string `json:"name" db:"name"`
+	Email string `json:"email" db:"email"`
+	Role  string `json:"role" db:"role"`
+}
+
+// Export is a decision's evaluations laid out for download
+type Export struct {
+	DecisionID        uuid.UUID   `json:"decision_id"`
+	DecisionTitle     string      `json:"decision_title"`
+	ExportedAt        time.Time   `json:"exported_at"`
+	Identified        bool        `json:"identified"` // evaluator identities are included
+	AggregationMethod string      `json:"aggregation_method"`
+	ConsensusMethod   string      `json:"consensus_method"`
+	TeamConsensus     float64     `json:"team_consensus"`
+	ParticipationRate float64     `json:"participation_rate"`
+	RecommendedOption *uuid.UUID  `json:"recommended_option,omitempty"`
+	Frozen            bool        `json:"frozen"`
+	Criteria          []Criterion `json:"criteria"`
+	Aggregates        []Aggregate `json:"aggregates"`
+	Scores            []ScoreRow  `json:"scores"`
+	ScoresWithheld    bool        `json:"scores_withheld,omitempty"` // some options had too few evaluators to list their scores
+}
+
+// Aggregate is one option's ranked result, with its mean score on each criterion in Export.Criteria order
+type Aggregate struct {
+	Rank          int        `json:"rank"`
+	OptionID      uuid.UUID  `json:"option_id"`
+	OptionTitle   string     `json:"option_title"`
+	WeightedScore float64    `json:"weighted_score"`
+	AverageScore  float64    `json:"average_score"`
+	Evaluators    int        `json:"evaluators"`
+	Consensus     float64    `json:"consensus"`
+	ConflictLevel string     `json:"conflict_level"`
+	CriterionMean []*float64 `json:"criterion_means"` // nil where no one scored the criterion
+}
+
+// ScoreRow is one evaluator's scores of one option, in Export.Criteria order
+type ScoreRow struct {
+	Evaluator   string             `json:"evaluator"` // an anonymous label unless the export is identified
+	Identity    *EvaluatorIdentity `json:"identity,omitempty"`
+	OptionID    uuid.UUID          `json:"option_id"`
+	OptionTitle string             `json:"option_title"`
+	Scores      []*int             `json:"scores"` // nil where the evaluator has not scored the criterion
+}
+
+// NewExport lays out the ballots and their results. Without identities evaluators are labelled
+// "Evaluator 1", "Evaluator 2", ... in an order that says nothing about who they are or when they submitted.
+// Scores and criterion means of options whose distribution the results withhold are left out.
+func NewExport(decision *models.CustomerDecision, ballots *Ballots, results *models.EvaluationResults, identities map[uuid.UUID]EvaluatorIdentity) *Export {
+	export := &Export{
+		DecisionID:        decision.ID,
+		DecisionTitle:     decision.Title,
+		ExportedAt:        time.Now().UTC(),
+		Identified:        identities != nil,
+		AggregationMethod: results.AggregationMethod,
+		ConsensusMethod:   results.ConsensusMethod,
+		TeamConsensus:     results.TeamConsensus,
+		ParticipationRate: results.ParticipationRate,
+		RecommendedOption: results.RecommendedOption,
+		Frozen:            results.Frozen,
+		Criteria:          ballots.Criteria,
+		Aggregates:        make([]Aggregate, 0, len(results.OptionScores)),
+		Scores:            []ScoreRow{},
+	}
+
+	criterionIndex := make(map[uuid.UUID]int, len(ballots.Criteria))
+	for i, criterion := range ballots.Criteria {
+		criterionIndex[criterion.ID] = i
+	}
+
+	type cell struct{ optionID, criteriaID uuid.UUID }
+	sums := make(map[cell]float64)
+	counts := make(map[cell]int)
+	byEvaluator := make(map[uuid.UUID]map[uuid.UUID][]*int)
+	for _, s := range ballots.Scores {
+		i, ok := criterionIndex[s.CriteriaID]
+		if !ok {
+			continue
+		}
+		key := cell{s.OptionID, s.CriteriaID}
+		sums[key] += s.Score
+		counts[key]++
+
+		if byEvaluator[s.EvaluatorID] == nil {
+			byEvaluator[s.EvaluatorID] = make(map[uuid.UUID][]*int)
+		}
+		row := byEvaluator[s.EvaluatorID][s.OptionID]
+		if row == nil {
+			row = make([]*int, len(ballots.Criteria))
+			byEvaluator[s.EvaluatorID][s.OptionID] = row
+		}
+		score := int(s.Score)
+		row[i] = &score
+	}
+
+	withheld := make(map[uuid.UUID]bool)
+	for rank, option := range results.OptionScores {
+		if option.DistributionWithheld {
+			withheld[option.OptionID] = true
+			export.ScoresWithheld = true
+		}
+		aggregate := Aggregate{
+			Rank:          rank + 1,
+			OptionID:      option.OptionID,
+			OptionTitle:   option.OptionTitle,
+			WeightedScore: option.WeightedScore,
+			AverageScore:  option.AverageScore,
+			Evaluators:    option.Evaluators,
+			Consensus:     option.Consensus,
+			ConflictLevel: option.ConflictLevel,
+			CriterionMean: make([]*float64, len(ballots.Criteria)),
+		}
+		for i, criterion := range ballots.Criteria {
+			if key := (cell{option.OptionID, criterion.ID}); counts[key] > 0 && !option.DistributionWithheld {
+				mean := sums[key] / float64(counts[key])
+				aggregate.CriterionMean[i] = &mean
+			}
+		}
+		export.Aggregates = append(export.Aggregates, aggregate)
+	}
+
+	// Evaluator IDs are random, so ordering by them gives labels that carry no information
+	evaluators := make([]uuid.UUID, 0, len(byEvaluator))
+	for id := range byEvaluator {
+		evaluators = append(evaluators, id)
+	}
+	sort.Slice(evaluators, func(i, j int) bool { return evaluators[i].String() < evaluators[j].String() })
+
+	for n, evaluatorID := range evaluators {
+		label := fmt.Sprintf("Evaluator %d", n+1)
+		var identity *EvaluatorIdentity
+		if known, ok := identities[evaluatorID]; ok {
+			identity = &known
+			label = known.Name
+		}
+		for _, option := range ballots.Options {
+			scores, ok := byEvaluator[evaluatorID][option.ID]
+			if !ok || withheld[option.ID] {
+				continue
+			}
+			export.Scores = append(export.Scores, ScoreRow{
+				Evaluator:   label,
+				Identity:    identity,
+				OptionID:    option.ID,
+				OptionTitle: option.Title,
+				Scores:      scores,
+			})
+		}
+	}
+	return export
+}
+
+// Write encodes the export in the given format
+func (e *Export) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatJSON:
+		return json.NewEncoder(w).Encode(e)
+	case FormatCSV:
+		return e.writeCSV(w)
+	case FormatXLSX:
+		return e.writeXLSX(w)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+// sheets returns the export as named tables; CSV writes them one after another, XLSX one per sheet
+func (e *Export) sheets() []csvutil.Table {
+	recommended := ""
+	if e.RecommendedOption != nil {
+		recommended = e.RecommendedOption.String()
+	}
+	summary := [][]interface{}{
+		{"decision_id", e.DecisionID.String()},
+		{"decision_title", e.DecisionTitle},
+		{"exported_at", e.ExportedAt.Format(time.RFC3339)},
+		{"identified", e.Identified},
+		{"frozen", e.Frozen},
+		{"aggregation_method", e.AggregationMethod},
+		{"consensus_method", e.ConsensusMethod},
+		{"team_consensus", e.TeamConsensus},
+		{"participation_rate", e.ParticipationRate},
+		{"recommended_option", recommended},
+	}
+
+	criteria := [][]interface{}{{"criteria_id", "name", "weight"}}
+	for _, criterion := range e.Criteria {
+		criteria = append(criteria, []interface{}{criterion.ID.String(), criterion.Name, criterion.Weight})
+	}
+
+	aggregateHeader := []interface{}{"rank", "option_id", "option", "weighted_score", "average_score", "evaluators", "consensus", "conflict_level"}
+	for _, criterion := range e.Criteria {
+		aggregateHeader = append(aggregateHeader, criterion.Name)
+	}
+	aggregates := [][]interface{}{aggregateHeader}
+	for _, a := range e.Aggregates {
+		row := []interface{}{a.Rank, a.OptionID.String(), a.OptionTitle, a.WeightedScore, a.AverageScore, a.Evaluators, a.Consensus, a.ConflictLevel}
+		for _, mean := range a.CriterionMean {
+			if mean == nil {
+				row = append(row, nil)
+			} else {
+				row = append(row, *mean)
+			}
+		}
+		aggregates = append(aggregates, row)
+	}
+
+	scoreHeader := []interface{}{"evaluator"}
+	if e.Identified {
+		scoreHeader = append(scoreHeader, "email", "role")
+	}
+	scoreHeader = append(scoreHeader, "option_id", "option")
+	for _, criterion := range e.Criteria {
+		scoreHeader = append(scoreHeader, criterion.Name)
+	}
+	scores := [][]interface{}{scoreHeader}
+	for _, s := range e.Scores {
+		row := []interface{}{s.Evaluator}
+		if e.Identified {
+			email, role := "", ""
+			if s.Identity != nil {
+				email, role = s.Identity.Email, s.Identity.Role
+			}
+			row = append(row, email, role)
+		}
+		row = append(row, s.OptionID.String(), s.OptionTitle)
+		for _, score := range s.Scores {
+			if score == nil {
+				row = append(row, nil)
+			} else {
+				row = append(row, *score)
+			}
+		}
+		scores = append(scores, row)
+	}
+
+	return []csvutil.Table{
+		{Name: "Summary", Rows: summary},
+		{Name: "Criteria", Rows: criteria},
+		{Name: "Aggregates", Rows: aggregates},
+		{Name: "Scores", Rows: scores},
+	}
+}
+
+func (e *Export) writeCSV(w io.Writer) error {
+	return csvutil.WriteTables(w, e.sheets(), -1)
+}
+
+func (e *Export) writeXLSX(w io.Writer) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	for i, sheet := range e.sheets() {
+		if i == 0 {
+			if err := f.SetSheetName("Sheet1", sheet.Name); err != nil {
+				return err
+			}
+		} else if _, err := f.NewSheet(sheet.Name); err != nil {
+			return err
+		}
+
+		stream, err := f.NewStreamWriter(sheet.Name)
+		if err != nil {
+			return err
+		}
+		for r, row := range sheet.Rows {
+			cell, err := excelize.CoordinatesToCellName(1, r+1)
+			if err != nil {
+				return err
+			}
+			if err := stream.SetRow(cell, row); err != nil {
+				return err
+			}
+		}
+		if err := stream.Flush(); err != nil {
+			return err
+		}
+	}
+	return f.Write(w)
+}
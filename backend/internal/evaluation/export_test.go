@@ -0,0 +1,119 @@
+package evaluation
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"choseby-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func exportFixture(t *testing.T, identities map[uuid.UUID]EvaluatorIdentity) (*Export, *Ballots) {
+	t.Helper()
+	b, _ := ballotFixture([]float64{2, 1}, [][][]float64{
+		{{8, 4}, {5, 5}},
+		{{6, 2}, {5, 7}},
+	})
+	b.Criteria[0].Name, b.Criteria[1].Name = "Cost", "=Satisfaction"
+
+	results, err := Rank(b, MethodWeightedMean, ConsensusVariance)
+	require.NoError(t, err)
+
+	decision := &models.CustomerDecision{ID: uuid.New(), Title: "Refund request"}
+	return NewExport(decision, b, results, identities), b
+}
+
+func TestNewExportIsAnonymousByDefault(t *testing.T) {
+	export, b := exportFixture(t, nil)
+
+	assert.False(t, export.Identified)
+	require.Len(t, export.Scores, 4)
+	require.Len(t, export.Aggregates, 2)
+
+	labels := map[string]bool{}
+	for _, row := range export.Scores {
+		labels[row.Evaluator] = true
+		assert.Nil(t, row.Identity)
+		assert.Len(t, row.Scores, 2)
+	}
+	assert.Equal(t, map[string]bool{"Evaluator 1": true, "Evaluator 2": true}, labels)
+
+	raw, err := json.Marshal(export)
+	require.NoError(t, err)
+	for _, s := range b.Scores {
+		assert.NotContains(t, string(raw), s.EvaluatorID.String())
+	}
+
+	top := export.Aggregates[0]
+	assert.Equal(t, 1, top.Rank)
+	require.NotNil(t, top.CriterionMean[0])
+	assert.InDelta(t, 7.0, *top.CriterionMean[0], 1e-9)
+}
+
+func TestNewExportNamesEvaluatorsWhenIdentified(t *testing.T) {
+	_, b := exportFixture(t, nil)
+	identities := map[uuid.UUID]EvaluatorIdentity{}
+	for _, s := range b.Scores {
+		identities[s.EvaluatorID] = EvaluatorIdentity{Name: "Sam", Email: "sam@example.com", Role: "legal_compliance"}
+	}
+
+	results, err := Rank(b, "", "")
+	require.NoError(t, err)
+	export := NewExport(&models.CustomerDecision{ID: uuid.New()}, b, results, identities)
+
+	assert.True(t, export.Identified)
+	for _, row := range export.Scores {
+		require.NotNil(t, row.Identity)
+		assert.Equal(t, "Sam", row.Evaluator)
+	}
+}
+
+func TestExportWritesCSVTables(t *testing.T) {
+	export, _ := exportFixture(t, nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, export.Write(&buf, FormatCSV))
+
+	reader := csv.NewReader(strings.NewReader(buf.String()))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	require.NoError(t, err)
+
+	var scoresAt int
+	for i, record := range records {
+		if record[0] == "# Scores" {
+			scoresAt = i
+		}
+	}
+	require.NotZero(t, scoresAt)
+	assert.Equal(t, []string{"evaluator", "option_id", "option", "Cost", "'=Satisfaction"}, records[scoresAt+1])
+	assert.Len(t, records[scoresAt+2:], 4)
+}
+
+func TestExportWritesXLSXSheets(t *testing.T) {
+	export, _ := exportFixture(t, nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, export.Write(&buf, FormatXLSX))
+
+	f, err := excelize.OpenReader(&buf)
+	require.NoError(t, err)
+	defer f.Close()
+
+	assert.Equal(t, []string{"Summary", "Criteria", "Aggregates", "Scores"}, f.GetSheetList())
+	rows, err := f.GetRows("Scores")
+	require.NoError(t, err)
+	assert.Len(t, rows, 5)
+	assert.Equal(t, "Evaluator 1", rows[1][0])
+}
+
+func TestExportRejectsUnknownFormat(t *testing.T) {
+	export, _ := exportFixture(t, nil)
+	assert.ErrorIs(t, export.Write(&bytes.Buffer{}, "pdf"), ErrUnknownFormat)
+}
@@ -0,0 +1,331 @@
+// Package evaluation computes team evaluation results and freezes them when evaluation closes.
+package evaluation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"choseby-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Reasons evaluation was closed
+const (
+	CloseReasonDeadline = "deadline"
+	CloseReasonQuorum   = "quorum"
+	CloseReasonManual   = "manual" // a member moved the decision on to drafting
+)
+
+// Compute calculates live results from the submitted evaluations using the decision's
+// aggregation method and consensus measure
+func Compute(ctx context.Context, db sqlx.QueryerContext, decision *models.CustomerDecision) (*models.EvaluationResults, error) {
+	decisionID, teamID, round := decision.ID, decision.TeamID, CurrentRound(decision)
+
+	// Get all team members for participation calculation
+	var totalMembers int
+	if err := sqlx.GetContext(ctx, db, &totalMembers, `
+		SELECT COUNT(*) FROM team_members WHERE team_id = $1 AND is_active = true
+	`, teamID); err != nil || totalMembers == 0 {
+		totalMembers = 1 // fallback
+	}
+
+	// Participation counts the open round; outside Delphi mode every evaluation is in round 1
+	var evaluatorCount int
+	if err := sqlx.GetContext(ctx, db, &evaluatorCount, `
+		SELECT COUNT(DISTINCT evaluator_id) FROM evaluations WHERE decision_id = $1 AND round_number = $2
+	`, decisionID, round); err != nil {
+		evaluatorCount = 0
+	}
+
+	// Roles of members who have and have not evaluated
+	completedBy := []string{}
+	if err := sqlx.SelectContext(ctx, db, &completedBy, `
+		SELECT DISTINCT tm.role
+		FROM evaluations e
+		JOIN team_members tm ON e.evaluator_id = tm.id
+		WHERE e.decision_id = $1 AND e.round_number = $2
+	`, decisionID, round); err != nil {
+		completedBy = []string{}
+	}
+
+	pendingFrom := []string{}
+	if err := sqlx.SelectContext(ctx, db, &pendingFrom, `
+		SELECT DISTINCT tm.role
+		FROM team_members tm
+		WHERE tm.team_id = $1 AND tm.is_active = true
+		AND tm.id NOT IN (
+			SELECT DISTINCT evaluator_id
+			FROM evaluations
+			WHERE decision_id = $2 AND round_number = $3
+		)
+	`, teamID, decisionID, round); err != nil {
+		pendingFrom = []string{}
+	}
+
+	ballots, err := LoadBallots(ctx, db, decisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := RankForDecision(ballots, decision)
+	if err != nil {
+		return nil, err
+	}
+	results.ParticipationRate = float64(evaluatorCount) / float64(totalMembers)
+	results.CompletedBy = completedBy
+	results.PendingFrom = pendingFrom
+	return results, nil
+}
+
+// LoadBallots reads the options, criteria and scores of a decision
+func LoadBallots(ctx context.Context, db sqlx.QueryerContext, decisionID uuid.UUID) (*Ballots, error) {
+	ballots := &Ballots{Options: []Option{}, Criteria: []Criterion{}, Scores: []Score{}}
+
+	if err := sqlx.SelectContext(ctx, db, &ballots.Options, `
+		SELECT id, title FROM response_options WHERE decision_id = $1 ORDER BY created_at, id
+	`, decisionID); err != nil {
+		return nil, err
+	}
+	if err := sqlx.SelectContext(ctx, db, &ballots.Criteria, `
+		SELECT id, name, COALESCE(weight, 1) AS weight FROM decision_criteria WHERE decision_id = $1 ORDER BY created_at, id
+	`, decisionID); err != nil {
+		return nil, err
+	}
+	if err := sqlx.SelectContext(ctx, db, &ballots.Scores, `
+		SELECT e.evaluator_id, e.option_id, e.criteria_id, e.score, COALESCE(e.confidence, 5) AS confidence,
+			tm.role AS evaluator_role, COALESCE(tm.escalation_authority, 1) AS escalation_authority
+		FROM evaluations e
+		JOIN team_members tm ON tm.id = e.evaluator_id
+		WHERE e.decision_id = $1
+	`, decisionID); err != nil {
+		return nil, err
+	}
+	return ballots, nil
+}
+
+// RankForDecision ranks with the decision's methods. When the decision weights stakeholders the
+// weighted ranking is returned, along with a comparison against the unweighted one.
+func RankForDecision(ballots *Ballots, decision *models.CustomerDecision) (*models.EvaluationResults, error) {
+	unweighted, err := Rank(ballots, decision.AggregationMethod, decision.ConsensusMethod)
+	if err != nil || decision.StakeholderWeighting == nil {
+		return unweighted, err
+	}
+
+	weightedBallots := *ballots
+	weightedBallots.EvaluatorWeights = EvaluatorWeights(ballots, decision.StakeholderWeighting)
+	weighted, err := Rank(&weightedBallots, decision.AggregationMethod, decision.ConsensusMethod)
+	if err != nil {
+		return nil, err
+	}
+	// Agreement is about people, so consensus ignores weighting
+	weighted.TeamConsensus = unweighted.TeamConsensus
+
+	weighted.StakeholderWeighting = compareRankings(decision.StakeholderWeighting.Source, weighted, unweighted)
+	return weighted, nil
+}
+
+// EvaluatorWeights applies a stakeholder weighting to everyone who scored the decision
+func EvaluatorWeights(ballots *Ballots, weighting *models.StakeholderWeighting) map[uuid.UUID]float64 {
+	weights := make(map[uuid.UUID]float64)
+	for _, s := range ballots.Scores {
+		if _, ok := weights[s.EvaluatorID]; !ok {
+			weights[s.EvaluatorID] = weighting.WeightFor(s.EvaluatorRole, s.EscalationAuthority)
+		}
+	}
+	return weights
+}
+
+// compareRankings lists both rankings and how many places weighting moved each option
+func compareRankings(source string, weighted, unweighted *models.EvaluationResults) *models.RankingComparison {
+	comparison := &models.RankingComparison{
+		Source:                      source,
+		Weighted:                    rankedOptions(weighted.OptionScores),
+		Unweighted:                  rankedOptions(unweighted.OptionScores),
+		UnweightedRecommendedOption: unweighted.RecommendedOption,
+	}
+
+	unweightedRank := make(map[uuid.UUID]int, len(comparison.Unweighted))
+	for _, option := range comparison.Unweighted {
+		unweightedRank[option.OptionID] = option.Rank
+	}
+	for i := range comparison.Weighted {
+		option := &comparison.Weighted[i]
+		option.RankChange = unweightedRank[option.OptionID] - option.Rank
+	}
+
+	switch {
+	case weighted.RecommendedOption == nil || unweighted.RecommendedOption == nil:
+		comparison.RecommendationChanged = weighted.RecommendedOption != unweighted.RecommendedOption
+	default:
+		comparison.RecommendationChanged = *weighted.RecommendedOption != *unweighted.RecommendedOption
+	}
+	return comparison
+}
+
+func rankedOptions(scores []models.OptionScore) []models.RankedOption {
+	ranked := make([]models.RankedOption, 0, len(scores))
+	for i, score := range scores {
+		ranked = append(ranked, models.RankedOption{
+			Rank:        i + 1,
+			OptionID:    score.OptionID,
+			OptionTitle: score.OptionTitle,
+			Score:       score.WeightedScore,
+		})
+	}
+	return ranked
+}
+
+// Rank scores and orders the options with the named aggregation method and consensus measure.
+// Per-option consensus and conflict level always come from score variance.
+func Rank(ballots *Ballots, aggregation, consensus string) (*models.EvaluationResults, error) {
+	aggregator, err := AggregatorFor(aggregation)
+	if err != nil {
+		return nil, err
+	}
+	measure, err := ConsensusMeasureFor(consensus)
+	if err != nil {
+		return nil, err
+	}
+	if aggregation == "" {
+		aggregation = MethodWeightedMean
+	}
+	if consensus == "" {
+		consensus = ConsensusVariance
+	}
+
+	scores := aggregator.Aggregate(ballots)
+
+	raw := make(map[uuid.UUID][]float64)
+	evaluators := make(map[uuid.UUID]map[uuid.UUID]bool)
+	for _, s := range ballots.Scores {
+		raw[s.OptionID] = append(raw[s.OptionID], s.Score)
+		if evaluators[s.OptionID] == nil {
+			evaluators[s.OptionID] = make(map[uuid.UUID]bool)
+		}
+		evaluators[s.OptionID][s.EvaluatorID] = true
+	}
+
+	// Convert to response format with consensus analysis
+	optionScores := make([]models.OptionScore, 0, len(ballots.Options))
+	for _, option := range ballots.Options {
+		average := 0.0
+		for _, v := range raw[option.ID] {
+			average += v
+		}
+		if len(raw[option.ID]) > 0 {
+			average /= float64(len(raw[option.ID]))
+		}
+		optionConsensus, conflictLevel := ConsensusMetrics(sampleVariance(raw[option.ID]))
+
+		optionScores = append(optionScores, models.OptionScore{
+			OptionID:      option.ID,
+			OptionTitle:   option.Title,
+			AverageScore:  average,
+			WeightedScore: scores[option.ID],
+			Evaluators:    len(evaluators[option.ID]),
+			Consensus:     optionConsensus,
+			ConflictLevel: conflictLevel,
+		})
+	}
+	sort.SliceStable(optionScores, func(i, j int) bool {
+		return optionScores[i].WeightedScore > optionScores[j].WeightedScore
+	})
+
+	// The recommendation is the top-ranked option that has been evaluated
+	var recommendedOptionID *uuid.UUID
+	for _, score := range optionScores {
+		if score.Evaluators > 0 {
+			optionID := score.OptionID
+			recommendedOptionID = &optionID
+			break
+		}
+	}
+
+	return &models.EvaluationResults{
+		OptionScores:      optionScores,
+		CompletedBy:       []string{},
+		PendingFrom:       []string{},
+		RecommendedOption: recommendedOptionID,
+		TeamConsensus:     measure.Consensus(ballots),
+		AggregationMethod: aggregation,
+		ConsensusMethod:   consensus,
+	}, nil
+}
+
+// Load returns the frozen results once evaluation has closed, and live results before that
+func Load(ctx context.Context, db sqlx.QueryerContext, decision *models.CustomerDecision) (*models.EvaluationResults, error) {
+	var snapshot struct {
+		Results     []byte    `db:"results"`
+		CloseReason string    `db:"close_reason"`
+		ClosedAt    time.Time `db:"closed_at"`
+	}
+	err := sqlx.GetContext(ctx, db, &snapshot, `
+		SELECT results, close_reason, closed_at FROM evaluation_snapshots WHERE decision_id = $1
+	`, decision.ID)
+
+	// A snapshot left over from an earlier round is ignored once evaluation reopens
+	if err == nil && decision.EvaluationClosedAt != nil {
+		var results models.EvaluationResults
+		if err := json.Unmarshal(snapshot.Results, &results); err != nil {
+			return nil, err
+		}
+		results.Frozen = true
+		results.CloseReason = snapshot.CloseReason
+		results.ClosedAt = &snapshot.ClosedAt
+		results.Deadline = decision.EvaluationDeadline
+		return &results, nil
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	results, err := Compute(ctx, db, decision)
+	if err != nil {
+		return nil, err
+	}
+	results.Deadline = decision.EvaluationDeadline
+	return results, nil
+}
+
+// Freeze stores the results as they stand when evaluation closes
+func Freeze(ctx context.Context, db sqlx.ExecerContext, decisionID uuid.UUID, results *models.EvaluationResults, reason string, closedAt time.Time) error {
+	raw, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO evaluation_snapshots (decision_id, results, close_reason, closed_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (decision_id) DO UPDATE SET
+			results = EXCLUDED.results, close_reason = EXCLUDED.close_reason, closed_at = EXCLUDED.closed_at
+	`, decisionID, raw, reason, closedAt)
+	return err
+}
+
+// ConsensusMetrics determines consensus level and conflict based on score variance
+func ConsensusMetrics(scoreVariance float64) (float64, string) {
+	// Calculate consensus level (inverse of variance, normalized)
+	consensus := 1.0
+	if scoreVariance > 0 {
+		consensus = math.Max(0, 1.0-(scoreVariance/10.0))
+	}
+
+	// Determine conflict level based on variance
+	conflictLevel := models.PriorityNone
+	if scoreVariance > 4.0 {
+		conflictLevel = models.PriorityHigh
+	} else if scoreVariance > 2.0 {
+		conflictLevel = models.PriorityMedium
+	} else if scoreVariance > 1.0 {
+		conflictLevel = models.PriorityLow
+	}
+
+	return consensus, conflictLevel
+}
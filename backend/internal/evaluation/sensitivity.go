@@ -0,0 +1,281 @@
+package evaluation
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"choseby-backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// Criterion weight limits enforced by decision_criteria_weight_check
+const (
+	MinCriterionWeight = 0.1
+	MaxCriterionWeight = 5.0
+)
+
+// flipPrecision is how closely a flip threshold is located once a step has crossed it
+const flipPrecision = 0.001
+
+// Caps on the weight steps and bisections one flip search may take, whatever the range and step
+const (
+	maxFlipSteps   = 1000
+	maxFlipBisects = 64
+)
+
+// ErrInvalidWeightRange is returned for a sensitivity range that is empty, not finite or has no usable step
+var ErrInvalidWeightRange = errors.New("invalid weight range")
+
+// WeightRange is the span of weights each criterion is moved across
+type WeightRange struct {
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Step float64 `json:"step"`
+}
+
+// SensitivityReport shows how robust the recommendation is to the criterion weights
+type SensitivityReport struct {
+	AggregationMethod string                  `json:"aggregation_method"`
+	RecommendedOption *uuid.UUID              `json:"recommended_option,omitempty"`
+	Range             WeightRange             `json:"range"`
+	Criteria          []CriterionSensitivity  `json:"criteria"`
+	Contributions     []OptionContribution    `json:"contributions"`
+	Anonymity         *models.AnonymityNotice `json:"anonymity,omitempty"`
+}
+
+// CriterionSensitivity is the nearest weights, above and below the current one, at which the top option changes
+type CriterionSensitivity struct {
+	CriteriaID      uuid.UUID   `json:"criteria_id"`
+	Name            string      `json:"name"`
+	CurrentWeight   float64     `json:"current_weight"`
+	FlipsWhenRaised *WeightFlip `json:"flips_when_raised_to,omitempty"`
+	FlipsWhenCut    *WeightFlip `json:"flips_when_lowered_to,omitempty"`
+	Robust          bool        `json:"robust"` // no weight in the range changes the top option
+}
+
+// WeightFlip is a weight at which another option takes the top spot
+type WeightFlip struct {
+	Weight        float64   `json:"weight"`
+	ChangePercent float64   `json:"change_percent"`
+	NewTopOption  uuid.UUID `json:"new_top_option"`
+	NewTopTitle   string    `json:"new_top_title"`
+}
+
+// OptionContribution breaks an option's score down by criterion
+type OptionContribution struct {
+	OptionID    uuid.UUID               `json:"option_id"`
+	OptionTitle string                  `json:"option_title"`
+	Total       float64                 `json:"total"`
+	Criteria    []CriterionContribution `json:"criteria"`
+	Withheld    bool                    `json:"withheld,omitempty"` // too few evaluators to break the score down
+}
+
+// CriterionContribution is how much one criterion adds to an option's weighted mean
+type CriterionContribution struct {
+	CriteriaID       uuid.UUID `json:"criteria_id"`
+	Name             string    `json:"name"`
+	Weight           float64   `json:"weight"`
+	NormalizedWeight float64   `json:"normalized_weight"`
+	AverageScore     float64   `json:"average_score"`
+	Contribution     float64   `json:"contribution"`
+	ShareOfTotal     float64   `json:"share_of_total"`
+}
+
+// Sensitivity moves each criterion weight across the range, one criterion at a time, and reports
+// where the top-ranked option changes under the decision's aggregation method and stakeholder weighting.
+func Sensitivity(ctx context.Context, ballots *Ballots, decision *models.CustomerDecision, weights WeightRange) (*SensitivityReport, error) {
+	if !weights.finite() || weights.Step <= 0 || weights.Min <= 0 || weights.Max < weights.Min {
+		return nil, ErrInvalidWeightRange
+	}
+	aggregator, err := AggregatorFor(decision.AggregationMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	base := *ballots
+	if decision.StakeholderWeighting != nil {
+		base.EvaluatorWeights = EvaluatorWeights(ballots, decision.StakeholderWeighting)
+	}
+
+	method := decision.AggregationMethod
+	if method == "" {
+		method = MethodWeightedMean
+	}
+	report := &SensitivityReport{
+		AggregationMethod: method,
+		RecommendedOption: topOption(aggregator, &base),
+		Range:             weights,
+		Criteria:          make([]CriterionSensitivity, 0, len(base.Criteria)),
+		Contributions:     contributions(&base),
+	}
+
+	for i, criterion := range base.Criteria {
+		sensitivity := CriterionSensitivity{
+			CriteriaID:    criterion.ID,
+			Name:          criterion.Name,
+			CurrentWeight: criterion.Weight,
+		}
+		if report.RecommendedOption != nil {
+			if sensitivity.FlipsWhenRaised, err = findFlip(ctx, aggregator, &base, i, *report.RecommendedOption, weights, weights.Step); err != nil {
+				return nil, err
+			}
+			if sensitivity.FlipsWhenCut, err = findFlip(ctx, aggregator, &base, i, *report.RecommendedOption, weights, -weights.Step); err != nil {
+				return nil, err
+			}
+		}
+		sensitivity.Robust = sensitivity.FlipsWhenRaised == nil && sensitivity.FlipsWhenCut == nil
+		report.Criteria = append(report.Criteria, sensitivity)
+	}
+	return report, nil
+}
+
+// findFlip steps one criterion's weight away from its current value until the top option changes,
+// then narrows the crossing down to flipPrecision. It gives up after maxFlipSteps steps.
+func findFlip(ctx context.Context, aggregator Aggregator, ballots *Ballots, criterion int, top uuid.UUID, weights WeightRange, step float64) (*WeightFlip, error) {
+	current := ballots.Criteria[criterion].Weight
+	if (step > 0 && current >= weights.Max) || (step < 0 && current <= weights.Min) {
+		return nil, nil
+	}
+	topAt := func(weight float64) *uuid.UUID {
+		return topOption(aggregator, withCriterionWeight(ballots, criterion, weight))
+	}
+	flipped := func(option *uuid.UUID) bool { return option != nil && *option != top }
+
+	previous := current
+	weight := current
+	for steps := 0; steps < maxFlipSteps; steps++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		weight = clampWeight(weight+step, weights)
+		if !flipped(topAt(weight)) {
+			if weight == weights.Min || weight == weights.Max {
+				return nil, nil
+			}
+			previous = weight
+			continue
+		}
+
+		// The flip lies between previous (same top) and weight (new top)
+		low, high := previous, weight
+		for bisects := 0; bisects < maxFlipBisects && math.Abs(high-low) > flipPrecision; bisects++ {
+			mid := (low + high) / 2
+			if flipped(topAt(mid)) {
+				high = mid
+			} else {
+				low = mid
+			}
+		}
+
+		newTop := *topAt(high)
+		flip := &WeightFlip{Weight: high, NewTopOption: newTop}
+		if current > 0 {
+			flip.ChangePercent = (high - current) / current * 100
+		}
+		for _, option := range ballots.Options {
+			if option.ID == newTop {
+				flip.NewTopTitle = option.Title
+			}
+		}
+		return flip, nil
+	}
+	return nil, nil
+}
+
+// topOption is the option Rank would recommend: the highest score among evaluated options, earliest on ties
+func topOption(aggregator Aggregator, ballots *Ballots) *uuid.UUID {
+	scores := aggregator.Aggregate(ballots)
+	evaluated := make(map[uuid.UUID]bool)
+	for _, s := range ballots.Scores {
+		evaluated[s.OptionID] = true
+	}
+
+	var top *uuid.UUID
+	best := 0.0
+	for _, option := range ballots.Options {
+		if !evaluated[option.ID] {
+			continue
+		}
+		if top == nil || scores[option.ID] > best {
+			optionID := option.ID
+			top, best = &optionID, scores[option.ID]
+		}
+	}
+	return top
+}
+
+// withCriterionWeight returns a copy of the ballots with one criterion reweighted
+func withCriterionWeight(ballots *Ballots, criterion int, weight float64) *Ballots {
+	copied := *ballots
+	copied.Criteria = append([]Criterion(nil), ballots.Criteria...)
+	copied.Criteria[criterion].Weight = weight
+	return &copied
+}
+
+// contributions splits each option's weighted mean into per-criterion parts.
+// When every evaluator scored every cell the parts add up to the weighted_mean score.
+func contributions(ballots *Ballots) []OptionContribution {
+	type cell struct{ optionID, criteriaID uuid.UUID }
+	sums := make(map[cell]float64)
+	totals := make(map[cell]float64)
+	for _, s := range ballots.Scores {
+		weight := ballots.evaluatorWeight(s.EvaluatorID)
+		key := cell{s.OptionID, s.CriteriaID}
+		sums[key] += s.Score * weight
+		totals[key] += weight
+	}
+
+	totalWeight := 0.0
+	for _, criterion := range ballots.Criteria {
+		totalWeight += criterion.Weight
+	}
+
+	result := make([]OptionContribution, 0, len(ballots.Options))
+	for _, option := range ballots.Options {
+		entry := OptionContribution{
+			OptionID:    option.ID,
+			OptionTitle: option.Title,
+			Criteria:    make([]CriterionContribution, 0, len(ballots.Criteria)),
+		}
+		for _, criterion := range ballots.Criteria {
+			part := CriterionContribution{CriteriaID: criterion.ID, Name: criterion.Name, Weight: criterion.Weight}
+			if totalWeight > 0 {
+				part.NormalizedWeight = criterion.Weight / totalWeight
+			}
+			if key := (cell{option.ID, criterion.ID}); totals[key] > 0 {
+				part.AverageScore = sums[key] / totals[key]
+			}
+			part.Contribution = part.AverageScore * part.NormalizedWeight
+			entry.Total += part.Contribution
+			entry.Criteria = append(entry.Criteria, part)
+		}
+		for i := range entry.Criteria {
+			if entry.Total > 0 {
+				entry.Criteria[i].ShareOfTotal = entry.Criteria[i].Contribution / entry.Total
+			}
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+// finite reports whether every bound and the step are real numbers; NaN would slip past the range checks
+func (w WeightRange) finite() bool {
+	for _, value := range []float64{w.Min, w.Max, w.Step} {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func clampWeight(weight float64, weights WeightRange) float64 {
+	if weight < weights.Min {
+		return weights.Min
+	}
+	if weight > weights.Max {
+		return weights.Max
+	}
+	return weight
+}
@@ -0,0 +1,105 @@
+package evaluation
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"choseby-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensitivityFindsWeightThresholds(t *testing.T) {
+	// Cost favours the first option, satisfaction the second: 6.0 vs 5.5 at equal weights
+	b, options := ballotFixture([]float64{1, 1}, [][][]float64{
+		{{9, 3}, {4, 7}},
+	})
+	b.Criteria[0].Name, b.Criteria[1].Name = "Cost", "Satisfaction"
+
+	report, err := Sensitivity(context.Background(), b, &models.CustomerDecision{}, WeightRange{Min: 0.1, Max: 5, Step: 0.1})
+	require.NoError(t, err)
+	require.Equal(t, options[0], *report.RecommendedOption)
+	require.Len(t, report.Criteria, 2)
+
+	cost, satisfaction := report.Criteria[0], report.Criteria[1]
+
+	// 9c + 3 < 4c + 7 once the cost weight drops below 0.8
+	require.NotNil(t, cost.FlipsWhenCut)
+	assert.InDelta(t, 0.8, cost.FlipsWhenCut.Weight, 0.002)
+	assert.Equal(t, options[1], cost.FlipsWhenCut.NewTopOption)
+	assert.InDelta(t, -20, cost.FlipsWhenCut.ChangePercent, 0.5)
+	assert.Nil(t, cost.FlipsWhenRaised)
+
+	// 9 + 3s < 4 + 7s once the satisfaction weight rises above 1.25
+	require.NotNil(t, satisfaction.FlipsWhenRaised)
+	assert.InDelta(t, 1.25, satisfaction.FlipsWhenRaised.Weight, 0.002)
+	assert.Nil(t, satisfaction.FlipsWhenCut)
+	assert.False(t, satisfaction.Robust)
+}
+
+func TestSensitivityReportsRobustCriteria(t *testing.T) {
+	b, _ := ballotFixture([]float64{1, 2}, [][][]float64{
+		{{9, 9}, {2, 2}},
+	})
+
+	report, err := Sensitivity(context.Background(), b, &models.CustomerDecision{}, WeightRange{Min: 0.1, Max: 5, Step: 0.5})
+	require.NoError(t, err)
+	for _, criterion := range report.Criteria {
+		assert.True(t, criterion.Robust)
+	}
+}
+
+func TestSensitivityContributionsAddUpToWeightedMean(t *testing.T) {
+	b, options := ballotFixture([]float64{3, 1}, [][][]float64{
+		{{8, 4}, {5, 5}},
+		{{6, 2}, {5, 5}},
+	})
+
+	report, err := Sensitivity(context.Background(), b, &models.CustomerDecision{}, WeightRange{Min: 0.1, Max: 5, Step: 0.1})
+	require.NoError(t, err)
+
+	mean := weightedMean(b)
+	for _, option := range report.Contributions {
+		assert.InDelta(t, mean[option.OptionID], option.Total, 1e-9)
+	}
+
+	first := report.Contributions[0]
+	require.Equal(t, options[0], first.OptionID)
+	assert.InDelta(t, 7*0.75, first.Criteria[0].Contribution, 1e-9)
+	assert.InDelta(t, 0.875, first.Criteria[0].ShareOfTotal, 1e-9)
+}
+
+func TestSensitivityRejectsInvalidRange(t *testing.T) {
+	b, _ := ballotFixture([]float64{1}, [][][]float64{{{5}}})
+
+	_, err := Sensitivity(context.Background(), b, &models.CustomerDecision{}, WeightRange{Min: 2, Max: 1, Step: 0.1})
+	assert.ErrorIs(t, err, ErrInvalidWeightRange)
+
+	for _, weights := range []WeightRange{
+		{Min: 0.1, Max: 5, Step: math.NaN()},
+		{Min: math.NaN(), Max: 5, Step: 0.1},
+		{Min: 0.1, Max: math.Inf(1), Step: 0.1},
+	} {
+		_, err := Sensitivity(context.Background(), b, &models.CustomerDecision{}, weights)
+		assert.ErrorIs(t, err, ErrInvalidWeightRange, "%+v", weights)
+	}
+}
+
+func TestSensitivityStepSearchIsBounded(t *testing.T) {
+	b, _ := ballotFixture([]float64{1, 1}, [][][]float64{
+		{{9, 3}, {4, 7}},
+	})
+
+	// A step too small to move the weight must still give up rather than loop forever
+	report, err := Sensitivity(context.Background(), b, &models.CustomerDecision{}, WeightRange{Min: 0.1, Max: 5, Step: 1e-300})
+	require.NoError(t, err)
+	for _, criterion := range report.Criteria {
+		assert.True(t, criterion.Robust)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Sensitivity(ctx, b, &models.CustomerDecision{}, WeightRange{Min: 0.1, Max: 5, Step: 0.1})
+	assert.ErrorIs(t, err, context.Canceled)
+}
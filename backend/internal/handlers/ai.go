@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"choseby-backend/internal/ai"
@@ -8,6 +9,7 @@ import (
 	"choseby-backend/internal/database"
 	"choseby-backend/internal/models"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // AIHandler handles DeepSeek AI integration for customer issue classification
@@ -17,11 +19,11 @@ type AIHandler struct {
 	aiService   *ai.Service
 }
 
-func NewAIHandler(db *database.DB, authService *auth.Service, apiKey string) *AIHandler {
+func NewAIHandler(db *database.DB, authService *auth.Service, aiService *ai.Service) *AIHandler {
 	return &AIHandler{
 		db:          db,
 		authService: authService,
-		aiService:   ai.NewAIService(apiKey, db),
+		aiService:   aiService,
 	}
 }
 
@@ -44,6 +46,13 @@ func (h *AIHandler) ClassifyIssue(c *gin.Context) {
 
 	// Use the AI service to enhance the decision
 	classification, recommendations, err := h.aiService.EnhanceDecisionWithAI(c.Request.Context(), req.DecisionID)
+	if errors.Is(err, ai.ErrNoProviderAvailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "AI classification temporarily unavailable",
+			"details": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "AI classification failed",
@@ -61,7 +70,7 @@ func (h *AIHandler) ClassifyIssue(c *gin.Context) {
 
 // GenerateOptions uses AI to generate response options
 func (h *AIHandler) GenerateOptions(c *gin.Context) {
-	_, exists := c.Get("user_id")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -69,6 +78,7 @@ func (h *AIHandler) GenerateOptions(c *gin.Context) {
 
 	var req struct {
 		DecisionID string `json:"decisionId" binding:"required"`
+		Persist    bool   `json:"persist"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -82,9 +92,13 @@ func (h *AIHandler) GenerateOptions(c *gin.Context) {
 		return
 	}
 
-	// Get decision from database
+	// Get decision with team verification
 	var decision models.CustomerDecision
-	err := h.db.GetContext(c, &decision, `SELECT * FROM customer_decisions WHERE id = $1`, decisionID)
+	err := h.db.GetContext(c, &decision, `
+		SELECT cd.* FROM customer_decisions cd
+		JOIN team_members tm ON cd.team_id = tm.team_id
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true AND cd.deleted_at IS NULL
+	`, decisionID, userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found"})
 		return
@@ -92,6 +106,13 @@ func (h *AIHandler) GenerateOptions(c *gin.Context) {
 
 	// Generate options using AI service
 	options, err := h.aiService.SuggestResponseOptions(c.Request.Context(), decision)
+	if errors.Is(err, ai.ErrNoProviderAvailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "AI option generation temporarily unavailable",
+			"details": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "AI option generation failed",
@@ -100,7 +121,19 @@ func (h *AIHandler) GenerateOptions(c *gin.Context) {
 		return
 	}
 
+	// Optionally store the suggestions so the team can evaluate them
+	if req.Persist {
+		if err := h.aiService.SaveResponseOptions(c.Request.Context(), options, userID.(uuid.UUID)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to save AI-generated options",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"options": options,
+		"options":   options,
+		"persisted": req.Persist,
 	})
 }
@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
 	"time"
 
+	"choseby-backend/internal/analytics"
+	"choseby-backend/internal/audit"
 	"choseby-backend/internal/auth"
 	"choseby-backend/internal/database"
 	"choseby-backend/internal/models"
@@ -53,7 +57,7 @@ func (h *AnalyticsHandler) GetDashboard(c *gin.Context) {
 	var totalDecisions int
 	err = h.db.GetContext(c, &totalDecisions, `
 		SELECT COUNT(*) FROM customer_decisions
-		WHERE team_id = $1 AND created_at >= $2
+		WHERE team_id = $1 AND created_at >= $2 AND deleted_at IS NULL
 	`, teamID, startDate)
 	if err != nil {
 		totalDecisions = 0
@@ -67,6 +71,7 @@ func (h *AnalyticsHandler) GetDashboard(c *gin.Context) {
 		JOIN customer_decisions cd ON ot.decision_id = cd.id
 		WHERE cd.team_id = $1
 		AND cd.created_at >= $2
+		AND cd.deleted_at IS NULL
 		AND ot.time_to_resolution_hours IS NOT NULL
 	`, teamID, startDate)
 	if err != nil {
@@ -81,6 +86,7 @@ func (h *AnalyticsHandler) GetDashboard(c *gin.Context) {
 		JOIN customer_decisions cd ON ot.decision_id = cd.id
 		WHERE cd.team_id = $1
 		AND cd.created_at >= $2
+		AND cd.deleted_at IS NULL
 		AND ot.customer_satisfaction_score IS NOT NULL
 	`, teamID, startDate)
 	if err != nil {
@@ -97,7 +103,7 @@ func (h *AnalyticsHandler) GetDashboard(c *gin.Context) {
 	err = h.db.SelectContext(c, &decisionTypes, `
 		SELECT decision_type, COUNT(*) as count
 		FROM customer_decisions
-		WHERE team_id = $1 AND created_at >= $2
+		WHERE team_id = $1 AND created_at >= $2 AND deleted_at IS NULL
 		GROUP BY decision_type
 		ORDER BY count DESC
 	`, teamID, startDate)
@@ -121,7 +127,7 @@ func (h *AnalyticsHandler) GetDashboard(c *gin.Context) {
 	err = h.db.SelectContext(c, &urgencyBreakdown, `
 		SELECT urgency_level, COUNT(*) as count
 		FROM customer_decisions
-		WHERE team_id = $1 AND created_at >= $2
+		WHERE team_id = $1 AND created_at >= $2 AND deleted_at IS NULL
 		GROUP BY urgency_level
 		ORDER BY urgency_level
 	`, teamID, startDate)
@@ -149,7 +155,7 @@ func (h *AnalyticsHandler) GetDashboard(c *gin.Context) {
 	err = h.db.SelectContext(c, &recentActivity, `
 		SELECT id, customer_name, title, status, urgency_level, created_at
 		FROM customer_decisions
-		WHERE team_id = $1
+		WHERE team_id = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
 		LIMIT 10
 	`, teamID)
@@ -174,7 +180,7 @@ func (h *AnalyticsHandler) GetDashboard(c *gin.Context) {
 			COALESCE(AVG(ot.customer_satisfaction_score::float), 0) as avg_satisfaction
 		FROM customer_decisions cd
 		LEFT JOIN outcome_tracking ot ON cd.id = ot.decision_id
-		WHERE cd.team_id = $1 AND cd.created_at >= $2
+		WHERE cd.team_id = $1 AND cd.created_at >= $2 AND cd.deleted_at IS NULL
 		GROUP BY DATE_TRUNC('week', cd.created_at)
 		ORDER BY week DESC
 	`, teamID, startDate)
@@ -192,7 +198,7 @@ func (h *AnalyticsHandler) GetDashboard(c *gin.Context) {
 	err = h.db.SelectContext(c, &customerTiers, `
 		SELECT customer_tier as tier, COUNT(*) as count
 		FROM customer_decisions
-		WHERE team_id = $1 AND created_at >= $2
+		WHERE team_id = $1 AND created_at >= $2 AND deleted_at IS NULL
 		GROUP BY customer_tier
 		ORDER BY count DESC
 	`, teamID, startDate)
@@ -231,62 +237,101 @@ func (h *AnalyticsHandler) GetDashboard(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-// GetTeamPerformance returns detailed team performance metrics (placeholder)
+// GetTeamPerformance returns each active member's evaluation activity over a date range
+// (?from=&to= dates, or ?period=7d|30d|90d)
 func (h *AnalyticsHandler) GetTeamPerformance(c *gin.Context) {
+	teamID, ok := h.userTeam(c)
+	if !ok {
+		return
+	}
+	reportRange, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+
+	report, err := analytics.Load(c, h.db, teamID, reportRange)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load team performance", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"range":              report.Range,
+		"total_decisions":    report.Summary.TotalDecisions,
+		"participation_rate": report.Summary.ParticipationRate,
+		"member_performance": report.Members,
+	})
+}
+
+// ExportAnalytics downloads the dashboard summary, team performance and decision type, urgency and
+// customer tier breakdowns over a date range as ?format=csv or json (default)
+func (h *AnalyticsHandler) ExportAnalytics(c *gin.Context) {
+	format := c.DefaultQuery("format", analytics.FormatJSON)
+	contentType, ok := analytics.ExportFormats()[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported export format", "formats": []string{analytics.FormatCSV, analytics.FormatJSON}})
+		return
+	}
+
+	teamID, ok := h.userTeam(c)
+	if !ok {
+		return
+	}
+	reportRange, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+
+	report, err := analytics.Load(c, h.db, teamID, reportRange)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export analytics", "details": err.Error()})
+		return
+	}
+
+	// Team performance names members, so exports are audited like evaluation exports
+	audit.Record(c, audit.Entry{Action: audit.ActionAnalyticsExported, Details: models.AuditDetails{
+		"format": format,
+		"from":   reportRange.From,
+		"to":     reportRange.To,
+	}})
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="analytics-%s-%s.%s"`,
+		reportRange.From.Format("20060102"), reportRange.To.Format("20060102"), format))
+	c.Status(http.StatusOK)
+	if err := report.Write(c.Writer, format); err != nil {
+		log.Printf("Analytics export for team %s failed mid-stream: %v", teamID, err)
+		_ = c.Error(err)
+	}
+}
+
+// userTeam returns the caller's team, writing the error response if there is none
+func (h *AnalyticsHandler) userTeam(c *gin.Context) (uuid.UUID, bool) {
 	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-		return
+		return uuid.Nil, false
 	}
 
-	// Get user's team ID
 	var teamID uuid.UUID
 	err := h.db.GetContext(c, &teamID, `
 		SELECT team_id FROM team_members WHERE id = $1 AND is_active = true
 	`, userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
-		return
-	}
-
-	// Individual team member performance
-	type MemberPerformance struct {
-		MemberID          uuid.UUID `json:"member_id" db:"member_id"`
-		Name              string    `json:"name" db:"name"`
-		Role              string    `json:"role" db:"role"`
-		EvaluationsCount  int       `json:"evaluations_count" db:"evaluations_count"`
-		AvgResponseTime   float64   `json:"avg_response_time" db:"avg_response_time"`
-		ParticipationRate float64   `json:"participation_rate" db:"participation_rate"`
+		return uuid.Nil, false
 	}
+	return teamID, true
+}
 
-	var memberPerformance []MemberPerformance
-	err = h.db.SelectContext(c, &memberPerformance, `
-		SELECT
-			tm.id as member_id,
-			tm.name,
-			tm.role,
-			COUNT(e.id) as evaluations_count,
-			COALESCE(AVG(EXTRACT(EPOCH FROM (e.created_at - cd.created_at)) / 3600), 0) as avg_response_time,
-			(COUNT(e.id)::float / GREATEST(COUNT(DISTINCT cd.id), 1)::float * 100) as participation_rate
-		FROM team_members tm
-		LEFT JOIN evaluations e ON tm.id = e.evaluator_id
-		LEFT JOIN customer_decisions cd ON e.decision_id = cd.id AND cd.team_id = tm.team_id
-		WHERE tm.team_id = $1 AND tm.is_active = true
-		GROUP BY tm.id, tm.name, tm.role
-		ORDER BY evaluations_count DESC
-	`, teamID)
+// parseReportRange reads ?from=&to= (YYYY-MM-DD or RFC 3339) or ?period=, writing the error response if invalid
+func parseReportRange(c *gin.Context) (analytics.Range, bool) {
+	reportRange, err := analytics.ParseRange(c.Query("period"), c.Query("from"), c.Query("to"), time.Now())
 	if err != nil {
-		memberPerformance = []MemberPerformance{}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be YYYY-MM-DD or RFC 3339 dates, with from before to"})
+		return analytics.Range{}, false
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"member_performance": memberPerformance,
-	})
-}
-
-// ExportAnalytics exports analytics data (placeholder for future implementation)
-func (h *AnalyticsHandler) ExportAnalytics(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Analytics export not implemented yet"})
+	return reportRange, true
 }
 
 // calculateDateRange determines the start date and normalizes the period string
@@ -317,7 +362,7 @@ func (h *AnalyticsHandler) calculateTeamEfficiency(c *gin.Context, teamID uuid.U
 		)
 		FROM customer_decisions cd
 		JOIN evaluations e ON cd.id = e.decision_id
-		WHERE cd.team_id = $1 AND cd.created_at >= $2
+		WHERE cd.team_id = $1 AND cd.created_at >= $2 AND cd.deleted_at IS NULL
 	`, teamID, startDate)
 	if err != nil {
 		avgEvaluationTime = 0
@@ -330,7 +375,7 @@ func (h *AnalyticsHandler) calculateTeamEfficiency(c *gin.Context, teamID uuid.U
 			SELECT COUNT(*) FROM team_members WHERE team_id = $1 AND is_active = true
 		)
 		FROM customer_decisions cd
-		WHERE cd.team_id = $1 AND cd.created_at >= $2
+		WHERE cd.team_id = $1 AND cd.created_at >= $2 AND cd.deleted_at IS NULL
 	`, teamID, startDate)
 	if err != nil {
 		totalEvaluationSlots = 1 // Avoid division by zero
@@ -340,7 +385,7 @@ func (h *AnalyticsHandler) calculateTeamEfficiency(c *gin.Context, teamID uuid.U
 		SELECT COUNT(DISTINCT e.evaluator_id || e.decision_id)
 		FROM evaluations e
 		JOIN customer_decisions cd ON e.decision_id = cd.id
-		WHERE cd.team_id = $1 AND cd.created_at >= $2
+		WHERE cd.team_id = $1 AND cd.created_at >= $2 AND cd.deleted_at IS NULL
 	`, teamID, startDate)
 	if err != nil {
 		completedEvaluations = 0
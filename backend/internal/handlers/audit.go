@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"choseby-backend/internal/audit"
+	"choseby-backend/internal/database"
+	"choseby-backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AuditHandler exposes the team's audit trail
+type AuditHandler struct {
+	db     *database.DB
+	logger *audit.Logger
+}
+
+// NewAuditHandler creates an audit trail handler
+func NewAuditHandler(db *database.DB, logger *audit.Logger) *AuditHandler {
+	return &AuditHandler{db: db, logger: logger}
+}
+
+// ListAuditLogs returns the team's audit entries, newest first, filtered by
+// ?decision_id=, ?user_id=, ?action= (a trailing * matches a prefix), ?from= and ?to=
+func (h *AuditHandler) ListAuditLogs(c *gin.Context) {
+	teamID := c.MustGet("team_id").(uuid.UUID)
+
+	where := []string{"team_id = $1"}
+	args := []interface{}{teamID}
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		where = append(where, fmt.Sprintf(condition, len(args)))
+	}
+
+	for _, param := range []string{"decision_id", "user_id"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be a UUID"})
+			return
+		}
+		add(param+" = $%d", id)
+	}
+
+	if action := c.Query("action"); action != "" {
+		if strings.HasSuffix(action, "*") {
+			add("action LIKE $%d", strings.TrimSuffix(action, "*")+"%")
+		} else {
+			add("action = $%d", action)
+		}
+	}
+
+	for param, condition := range map[string]string{"from": "created_at >= $%d", "to": "created_at < $%d"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be an RFC 3339 timestamp"})
+			return
+		}
+		add(condition, at.UTC())
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	whereClause := strings.Join(where, " AND ")
+
+	var total int
+	if err := h.db.GetContext(c, &total, `SELECT COUNT(*) FROM audit_logs WHERE `+whereClause, args...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count audit entries", "details": err.Error()})
+		return
+	}
+
+	entries := []models.AuditLog{}
+	query := fmt.Sprintf(`
+		SELECT id, team_id, sequence, decision_id, user_id, action, details,
+			   ip_address, user_agent, request_id, prev_hash, hash, created_at
+		FROM audit_logs
+		WHERE %s
+		ORDER BY sequence DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, len(args)+1, len(args)+2)
+	if err := h.db.SelectContext(c, &entries, query, append(args, limit, offset)...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit entries", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+// VerifyAuditChain recomputes the team's hash chain and reports the first tampered entry, if any
+func (h *AuditHandler) VerifyAuditChain(c *gin.Context) {
+	teamID := c.MustGet("team_id").(uuid.UUID)
+
+	result, err := h.logger.Verify(c, &teamID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify audit trail", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -1,16 +1,19 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"net/http"
 	"time"
 
+	"choseby-backend/internal/audit"
 	"choseby-backend/internal/auth"
 	"choseby-backend/internal/database"
 	"choseby-backend/internal/models"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -18,12 +21,14 @@ import (
 type AuthHandler struct {
 	db          *database.DB
 	authService *auth.Service
+	revocations *auth.RevocationStore
 }
 
-func NewAuthHandler(db *database.DB, authService *auth.Service) *AuthHandler {
+func NewAuthHandler(db *database.DB, authService *auth.Service, revocations *auth.RevocationStore) *AuthHandler {
 	return &AuthHandler{
 		db:          db,
 		authService: authService,
+		revocations: revocations,
 	}
 }
 
@@ -120,6 +125,17 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	// The registering member owns the team
+	_, err = tx.ExecContext(c, `UPDATE teams SET owner_id = $1 WHERE id = $2`, member.ID, team.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "team_creation_failed",
+			"message": "Failed to assign team owner",
+		})
+		return
+	}
+	team.OwnerID = &member.ID
+
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -130,7 +146,17 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	// Generate JWT token
-	token, expiresAt, err := h.authService.GenerateToken(member.ID.String(), member.Role)
+	token, expiresAt, err := h.authService.GenerateToken(member.ID.String(), member.Role, member.TokenGeneration)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "token_generation_failed",
+			"message": "Registration completed but login failed",
+		})
+		return
+	}
+
+	// Start a new refresh token family for this session
+	refreshToken, _, refreshExpiresAt, err := h.issueRefreshToken(c, h.db, member.ID, uuid.New())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "token_generation_failed",
@@ -141,10 +167,12 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	// Return response
 	response := models.AuthResponse{
-		Token:     token,
-		User:      member,
-		Team:      team,
-		ExpiresAt: expiresAt,
+		Token:            token,
+		RefreshToken:     refreshToken,
+		User:             member,
+		Team:             team,
+		ExpiresAt:        expiresAt,
+		RefreshExpiresAt: &refreshExpiresAt,
 	}
 
 	c.JSON(http.StatusCreated, response)
@@ -165,7 +193,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	var member models.TeamMember
 	err := h.db.GetContext(c, &member, `
 		SELECT id, team_id, email, name, password_hash, role, escalation_authority,
-			   notification_preferences, is_active, created_at, updated_at
+			   notification_preferences, is_active, token_generation, created_at, updated_at
 		FROM team_members
 		WHERE email = $1 AND is_active = true
 	`, req.Email)
@@ -197,7 +225,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	// Get team information
 	var team models.Team
 	err = h.db.GetContext(c, &team, `
-		SELECT id, name, company_name, industry, team_size, subscription_tier, created_at, updated_at
+		SELECT id, name, company_name, industry, team_size, subscription_tier, owner_id, created_at, updated_at
 		FROM teams
 		WHERE id = $1
 	`, member.TeamID)
@@ -210,7 +238,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// Generate JWT token
-	token, expiresAt, err := h.authService.GenerateToken(member.ID.String(), member.Role)
+	token, expiresAt, err := h.authService.GenerateToken(member.ID.String(), member.Role, member.TokenGeneration)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "token_generation_failed",
@@ -219,12 +247,26 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	// Start a new refresh token family for this session
+	refreshToken, _, refreshExpiresAt, err := h.issueRefreshToken(c, h.db, member.ID, uuid.New())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "token_generation_failed",
+			"message": "Login failed",
+		})
+		return
+	}
+
+	audit.Record(c, audit.Entry{Action: audit.ActionLogin, UserID: &member.ID, TeamID: &member.TeamID})
+
 	// Return response
 	response := models.AuthResponse{
-		Token:     token,
-		User:      member,
-		Team:      team,
-		ExpiresAt: expiresAt,
+		Token:            token,
+		RefreshToken:     refreshToken,
+		User:             member,
+		Team:             team,
+		ExpiresAt:        expiresAt,
+		RefreshExpiresAt: &refreshExpiresAt,
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -254,7 +296,7 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 	// Get team information
 	var team models.Team
 	err = h.db.GetContext(c, &team, `
-		SELECT id, name, company_name, industry, team_size, subscription_tier, created_at, updated_at
+		SELECT id, name, company_name, industry, team_size, subscription_tier, owner_id, created_at, updated_at
 		FROM teams
 		WHERE id = $1
 	`, member.TeamID)
@@ -322,21 +364,237 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Profile updated successfully"})
 }
 
-// Logout handles user logout (placeholder for token invalidation)
+// Logout revokes the presented access token and, if supplied, the refresh token family of this session
 func (h *AuthHandler) Logout(c *gin.Context) {
-	// In a complete implementation, this would:
-	// 1. Add the token to a blacklist
-	// 2. Clear any session data
-	// 3. Log the logout event
+	claimsValue, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	claims := claimsValue.(*auth.Claims)
+
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	_ = c.ShouldBindJSON(&req) // Body is optional
+
+	if claims.ExpiresAt != nil {
+		if err := h.revocations.Revoke(c, claims.ID, claims.UserID, claims.ExpiresAt.Time); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "logout_failed",
+				"message": "Failed to revoke access token",
+			})
+			return
+		}
+	}
+
+	if req.RefreshToken != "" {
+		_, err := h.db.ExecContext(c, `
+			UPDATE auth_tokens SET revoked_at = NOW()
+			WHERE revoked_at IS NULL AND user_id = $1 AND family_id = (
+				SELECT family_id FROM auth_tokens WHERE token_hash = $2
+			)
+		`, claims.UserID, auth.HashToken(req.RefreshToken))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "logout_failed",
+				"message": "Failed to revoke refresh token",
+			})
+			return
+		}
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Logged out successfully",
 	})
 }
 
-// RefreshToken handles token refresh (placeholder)
+// LogoutAll invalidates every access and refresh token the user holds, on all devices
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	memberID := userID.(uuid.UUID)
+
+	generation, err := h.revocations.BumpGeneration(c, memberID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "logout_failed",
+			"message": "Failed to revoke sessions",
+		})
+		return
+	}
+
+	_, err = h.db.ExecContext(c, `
+		UPDATE auth_tokens SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, memberID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "logout_failed",
+			"message": "Failed to revoke refresh tokens",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "All sessions logged out successfully",
+		"token_generation": generation,
+	})
+}
+
+// RefreshToken exchanges a refresh token for a new access token and rotates the refresh token.
+// Presenting a token that was already rotated revokes every token in its family.
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Token refresh not implemented yet"})
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": "refresh_token is required",
+		})
+		return
+	}
+
+	tx, err := h.db.BeginTxx(c, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "transaction_failed",
+			"message": "Failed to refresh token",
+		})
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Lock the row so concurrent refreshes with the same token cannot both rotate it
+	var stored models.AuthToken
+	err = tx.GetContext(c, &stored, `
+		SELECT id, user_id, token_hash, family_id, replaced_by, revoked_at, expires_at, created_at
+		FROM auth_tokens
+		WHERE token_hash = $1
+		FOR UPDATE
+	`, auth.HashToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   "invalid_refresh_token",
+				"message": "Refresh token is invalid",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "database_error",
+				"message": "Failed to refresh token",
+			})
+		}
+		return
+	}
+
+	// Replay of a rotated token: assume it was stolen and kill the whole family
+	if stored.ReplacedBy != nil {
+		if _, err := tx.ExecContext(c, `
+			UPDATE auth_tokens SET revoked_at = NOW()
+			WHERE family_id = $1 AND revoked_at IS NULL
+		`, stored.FamilyID); err != nil || tx.Commit() != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "database_error",
+				"message": "Failed to revoke refresh tokens",
+			})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "refresh_token_reused",
+			"message": "Refresh token was already used; all sessions from this login have been revoked",
+		})
+		return
+	}
+
+	if stored.RevokedAt != nil || time.Now().After(stored.ExpiresAt) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "invalid_refresh_token",
+			"message": "Refresh token has expired or been revoked",
+		})
+		return
+	}
+
+	var member models.TeamMember
+	err = tx.GetContext(c, &member, `
+		SELECT id, team_id, email, name, role, escalation_authority,
+			   notification_preferences, is_active, token_generation, created_at, updated_at
+		FROM team_members
+		WHERE id = $1 AND is_active = true
+	`, stored.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "invalid_refresh_token",
+			"message": "Account is no longer active",
+		})
+		return
+	}
+
+	refreshToken, newTokenID, refreshExpiresAt, err := h.issueRefreshToken(c, tx, member.ID, stored.FamilyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "token_generation_failed",
+			"message": "Failed to refresh token",
+		})
+		return
+	}
+
+	_, err = tx.ExecContext(c, `
+		UPDATE auth_tokens SET replaced_by = $1, revoked_at = NOW()
+		WHERE id = $2
+	`, newTokenID, stored.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "database_error",
+			"message": "Failed to rotate refresh token",
+		})
+		return
+	}
+
+	token, expiresAt, err := h.authService.GenerateToken(member.ID.String(), member.Role, member.TokenGeneration)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "token_generation_failed",
+			"message": "Failed to refresh token",
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "database_error",
+			"message": "Failed to rotate refresh token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TokenResponse{
+		Token:            token,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+	})
+}
+
+// issueRefreshToken creates and stores a refresh token in the given family
+func (h *AuthHandler) issueRefreshToken(ctx context.Context, db sqlx.ExecerContext, userID, familyID uuid.UUID) (string, uuid.UUID, time.Time, error) {
+	token, tokenHash, expiresAt, err := h.authService.GenerateRefreshToken()
+	if err != nil {
+		return "", uuid.Nil, time.Time{}, err
+	}
+
+	tokenID := uuid.New()
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO auth_tokens (id, user_id, token_hash, family_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+	`, tokenID, userID, tokenHash, familyID, expiresAt)
+	if err != nil {
+		return "", uuid.Nil, time.Time{}, err
+	}
+
+	return token, tokenID, expiresAt, nil
 }
 
 // Additional placeholder methods for SSO compatibility
@@ -359,25 +617,7 @@ func (h *AuthHandler) validateRegistration(c *gin.Context, req *models.RegisterR
 		return http.ErrAbortHandler
 	}
 
-	// Validate role
-	validRoles := []string{
-		"customer_success_manager",
-		"support_manager",
-		"account_manager",
-		"sales_manager",
-		"legal_compliance",
-		"operations_manager",
-	}
-
-	roleValid := false
-	for _, role := range validRoles {
-		if req.Role == role {
-			roleValid = true
-			break
-		}
-	}
-
-	if !roleValid {
+	if !auth.IsValidRole(req.Role) {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "invalid_role",
 			"message": "Invalid role specified",
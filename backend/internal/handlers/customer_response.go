@@ -1,14 +1,20 @@
 package handlers
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	"choseby-backend/internal/audit"
 	"choseby-backend/internal/auth"
 	"choseby-backend/internal/database"
+	"choseby-backend/internal/evaluation"
 	"choseby-backend/internal/models"
+	"choseby-backend/internal/realtime"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -17,16 +23,23 @@ import (
 type DecisionsHandler struct {
 	db          *database.DB
 	authService *auth.Service
+	retention   time.Duration // how long deleted decisions stay restorable
+	evalTimeout time.Duration // default evaluation deadline after entering evaluation
+	hub         *realtime.Hub
 }
 
-func NewDecisionsHandler(db *database.DB, authService *auth.Service) *DecisionsHandler {
+func NewDecisionsHandler(db *database.DB, authService *auth.Service, retentionDays, evaluationTimeoutHours int, hub *realtime.Hub) *DecisionsHandler {
 	return &DecisionsHandler{
 		db:          db,
 		authService: authService,
+		retention:   time.Duration(retentionDays) * 24 * time.Hour,
+		evalTimeout: time.Duration(evaluationTimeoutHours) * time.Hour,
+		hub:         hub,
 	}
 }
 
-// GetTeamDecisions retrieves all decisions for the authenticated user's team
+// GetTeamDecisions lists the team's decisions with filters, sorting, full-text search and pagination.
+// Pages can be walked with next_cursor (keyset) or with offset.
 func (h *DecisionsHandler) GetTeamDecisions(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -34,18 +47,14 @@ func (h *DecisionsHandler) GetTeamDecisions(c *gin.Context) {
 		return
 	}
 
-	// Get query parameters for filtering
-	status := c.Query("status")
-	urgency := c.Query("urgency")
-	limit := c.DefaultQuery("limit", "20")
-	offset := c.DefaultQuery("offset", "0")
-
-	limitInt, _ := strconv.Atoi(limit)
-	offsetInt, _ := strconv.Atoi(offset)
+	filter, err := parseDecisionListFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
+	}
 
 	// Get user's team ID
-	var teamID uuid.UUID
-	err := h.db.GetContext(c, &teamID, `
+	err = h.db.GetContext(c, &filter.TeamID, `
 		SELECT team_id FROM team_members WHERE id = $1 AND is_active = true
 	`, userID)
 	if err != nil {
@@ -53,71 +62,151 @@ func (h *DecisionsHandler) GetTeamDecisions(c *gin.Context) {
 		return
 	}
 
-	// Build query with filters
-	query := `
-		SELECT id, customer_name, customer_tier, title, status, urgency_level, created_at
-		FROM customer_decisions
-		WHERE team_id = $1
-	`
-	args := []interface{}{teamID}
-	argIndex := 2
-
-	if status != "" {
-		query += fmt.Sprintf(" AND status = $%d", argIndex)
-		args = append(args, status)
-		argIndex++
-	}
-
-	if urgency != "" {
-		urgencyInt, _ := strconv.Atoi(urgency)
-		query += fmt.Sprintf(" AND urgency_level = $%d", argIndex)
-		args = append(args, urgencyInt)
-		argIndex++
+	query, args, countQuery, countArgs, err := database.BuildDecisionListQuery(filter, `
+		id, customer_name, customer_tier, title, status, urgency_level, decision_type,
+		customer_impact_scope, financial_impact, ai_confidence_score, created_by, created_at, updated_at`)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
 	}
 
-	query += " ORDER BY created_at DESC"
-	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
-	args = append(args, limitInt, offsetInt)
-
 	type DecisionSummary struct {
-		ID           uuid.UUID `json:"id" db:"id"`
-		CustomerName string    `json:"customer_name" db:"customer_name"`
-		CustomerTier string    `json:"customer_tier" db:"customer_tier"`
-		Title        string    `json:"title" db:"title"`
-		Status       string    `json:"status" db:"status"`
-		UrgencyLevel int       `json:"urgency_level" db:"urgency_level"`
-		CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	}
-
-	var decisions []DecisionSummary
+		ID                  uuid.UUID `json:"id" db:"id"`
+		CustomerName        string    `json:"customer_name" db:"customer_name"`
+		CustomerTier        string    `json:"customer_tier" db:"customer_tier"`
+		Title               string    `json:"title" db:"title"`
+		Status              string    `json:"status" db:"status"`
+		UrgencyLevel        int       `json:"urgency_level" db:"urgency_level"`
+		DecisionType        string    `json:"decision_type" db:"decision_type"`
+		CustomerImpactScope string    `json:"customer_impact_scope" db:"customer_impact_scope"`
+		FinancialImpact     *float64  `json:"financial_impact,omitempty" db:"financial_impact"`
+		AIConfidenceScore   *float64  `json:"ai_confidence_score,omitempty" db:"ai_confidence_score"`
+		CreatedBy           uuid.UUID `json:"created_by" db:"created_by"`
+		CreatedAt           time.Time `json:"created_at" db:"created_at"`
+		UpdatedAt           time.Time `json:"updated_at" db:"updated_at"`
+		SortKey             string    `json:"-" db:"sort_key"`
+	}
+
+	decisions := []DecisionSummary{}
 	err = h.db.SelectContext(c, &decisions, query, args...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch decisions"})
 		return
 	}
 
-	// Get total count for pagination
-	countQuery := `SELECT COUNT(*) FROM customer_decisions WHERE team_id = $1`
-	countArgs := []interface{}{teamID}
-	if status != "" {
-		countQuery += " AND status = $2"
-		countArgs = append(countArgs, status)
-	}
-
+	// Get total count for pagination (same filters as the page query)
 	var total int
 	err = h.db.GetContext(c, &total, countQuery, countArgs...)
 	if err != nil {
 		total = 0
 	}
 
+	var nextCursor *string
+	if len(decisions) == filter.Limit {
+		last := decisions[len(decisions)-1]
+		cursor := database.EncodeDecisionCursor(database.DecisionCursor{
+			Sort: filter.Sort, Order: filter.Order, SortKey: last.SortKey, ID: last.ID,
+		})
+		nextCursor = &cursor
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"decisions": decisions,
-		"total":     total,
-		"limit":     limitInt,
-		"offset":    offsetInt,
+		"decisions":   decisions,
+		"total":       total,
+		"limit":       filter.Limit,
+		"offset":      filter.Offset,
+		"sort":        filter.Sort,
+		"order":       filter.Order,
+		"next_cursor": nextCursor,
 	})
 }
 
+// parseDecisionListFilter reads the decision list query parameters
+func parseDecisionListFilter(c *gin.Context) (database.DecisionListFilter, error) {
+	filter := database.DecisionListFilter{
+		Status:              c.Query("status"),
+		CustomerTier:        c.Query("customer_tier"),
+		DecisionType:        c.Query("decision_type"),
+		CustomerImpactScope: c.Query("customer_impact_scope"),
+		Search:              strings.TrimSpace(c.Query("q")),
+		Sort:                c.Query("sort"),
+		Order:               c.Query("order"),
+		Cursor:              c.Query("cursor"),
+	}
+
+	var err error
+	intParam := func(name string) *int {
+		value := c.Query(name)
+		if value == "" || err != nil {
+			return nil
+		}
+		parsed, parseErr := strconv.Atoi(value)
+		if parseErr != nil {
+			err = fmt.Errorf("%s must be an integer", name)
+			return nil
+		}
+		return &parsed
+	}
+	floatParam := func(name string) *float64 {
+		value := c.Query(name)
+		if value == "" || err != nil {
+			return nil
+		}
+		parsed, parseErr := strconv.ParseFloat(value, 64)
+		if parseErr != nil {
+			err = fmt.Errorf("%s must be a number", name)
+			return nil
+		}
+		return &parsed
+	}
+	// Dates accept RFC 3339 or YYYY-MM-DD; a bare end date includes that whole day
+	timeParam := func(name string, endOfDay bool) *time.Time {
+		value := c.Query(name)
+		if value == "" || err != nil {
+			return nil
+		}
+		if parsed, parseErr := time.Parse(time.RFC3339, value); parseErr == nil {
+			return &parsed
+		}
+		parsed, parseErr := time.Parse("2006-01-02", value)
+		if parseErr != nil {
+			err = fmt.Errorf("%s must be a date (YYYY-MM-DD) or RFC 3339 timestamp", name)
+			return nil
+		}
+		if endOfDay {
+			parsed = parsed.AddDate(0, 0, 1)
+		}
+		return &parsed
+	}
+
+	filter.UrgencyLevel = intParam("urgency")
+	filter.MinFinancialImpact = floatParam("min_financial_impact")
+	filter.MaxFinancialImpact = floatParam("max_financial_impact")
+	filter.MinAIConfidence = floatParam("min_ai_confidence")
+	filter.MaxAIConfidence = floatParam("max_ai_confidence")
+	filter.CreatedFrom = timeParam("created_from", false)
+	filter.CreatedTo = timeParam("created_to", true)
+	if limit := intParam("limit"); limit != nil {
+		filter.Limit = *limit
+	}
+	if offset := intParam("offset"); offset != nil {
+		filter.Offset = *offset
+	}
+	if err != nil {
+		return filter, err
+	}
+
+	if createdBy := c.Query("created_by"); createdBy != "" {
+		id, parseErr := uuid.Parse(createdBy)
+		if parseErr != nil {
+			return filter, fmt.Errorf("created_by must be a UUID")
+		}
+		filter.CreatedBy = &id
+	}
+
+	return filter, filter.Normalize()
+}
+
 // CreateDecision creates a new customer response decision
 func (h *DecisionsHandler) CreateDecision(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -210,6 +299,12 @@ func (h *DecisionsHandler) CreateDecision(c *gin.Context) {
 		return
 	}
 
+	audit.RecordDecision(c, audit.ActionDecisionCreated, decision.ID, models.AuditDetails{
+		"title":         decision.Title,
+		"decision_type": decision.DecisionType,
+		"urgency_level": decision.UrgencyLevel,
+	})
+
 	// Return simplified response
 	response := gin.H{
 		"id":            decision.ID,
@@ -240,7 +335,7 @@ func (h *DecisionsHandler) GetDecision(c *gin.Context) {
 	err := h.db.GetContext(c, &decision, `
 		SELECT cd.* FROM customer_decisions cd
 		JOIN team_members tm ON cd.team_id = tm.team_id
-		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true AND cd.deleted_at IS NULL
 	`, decisionID, userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found"})
@@ -270,7 +365,7 @@ func (h *DecisionsHandler) GetDecision(c *gin.Context) {
 		OptionID      uuid.UUID `json:"option_id" db:"option_id"`
 		CriteriaID    uuid.UUID `json:"criteria_id" db:"criteria_id"`
 		Score         int       `json:"score" db:"score"`
-		EvaluatorRole string    `json:"evaluator_role" db:"evaluator_role"`
+		EvaluatorRole string    `json:"evaluator_role,omitempty" db:"evaluator_role"`
 	}
 
 	var evaluations []AnonymousEvaluation
@@ -284,6 +379,39 @@ func (h *DecisionsHandler) GetDecision(c *gin.Context) {
 		evaluations = []AnonymousEvaluation{}
 	}
 
+	// Individual scores are only listed for cells enough members have scored, with revealing roles bucketed
+	privacy, err := evaluation.LoadPrivacy(c, h.db, &decision)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load evaluations", "details": err.Error()})
+		return
+	}
+	type cell struct{ optionID, criteriaID uuid.UUID }
+	scored := make(map[cell]int)
+	for _, e := range evaluations {
+		scored[cell{e.OptionID, e.CriteriaID}]++
+	}
+	visible := []AnonymousEvaluation{}
+	for _, e := range evaluations {
+		if privacy.Withholds(privacy.Evaluators) || privacy.Withholds(scored[cell{e.OptionID, e.CriteriaID}]) {
+			continue
+		}
+		e.EvaluatorRole = privacy.Role(e.EvaluatorRole)
+		visible = append(visible, e)
+	}
+	// Rows come back in submission order; sorting by score hides who scored first
+	sort.SliceStable(visible, func(i, j int) bool {
+		a, b := visible[i], visible[j]
+		if a.OptionID != b.OptionID {
+			return a.OptionID.String() < b.OptionID.String()
+		}
+		if a.CriteriaID != b.CriteriaID {
+			return a.CriteriaID.String() < b.CriteriaID.String()
+		}
+		return a.Score < b.Score
+	})
+	evaluations = visible
+
+	c.Header("ETag", decisionETag(decision.UpdatedAt))
 	response := gin.H{
 		"decision":    decision,
 		"criteria":    criteria,
@@ -322,7 +450,7 @@ func (h *DecisionsHandler) UpdateCriteria(c *gin.Context) {
 	err := h.db.GetContext(c, &teamID, `
 		SELECT cd.team_id FROM customer_decisions cd
 		JOIN team_members tm ON cd.team_id = tm.team_id
-		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true AND cd.deleted_at IS NULL
 	`, decisionID, userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found"})
@@ -361,6 +489,10 @@ func (h *DecisionsHandler) UpdateCriteria(c *gin.Context) {
 		criteria = append(criteria, criterion)
 	}
 
+	audit.RecordDecision(c, audit.ActionCriteriaUpdated, uuid.MustParse(decisionID), models.AuditDetails{
+		"criteria_count": len(criteria),
+	})
+
 	c.JSON(http.StatusOK, gin.H{"criteria": criteria})
 }
 
@@ -394,7 +526,7 @@ func (h *DecisionsHandler) UpdateOptions(c *gin.Context) {
 	err := h.db.GetContext(c, &teamID, `
 		SELECT cd.team_id FROM customer_decisions cd
 		JOIN team_members tm ON cd.team_id = tm.team_id
-		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true AND cd.deleted_at IS NULL
 	`, decisionID, userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found"})
@@ -436,16 +568,299 @@ func (h *DecisionsHandler) UpdateOptions(c *gin.Context) {
 		options = append(options, option)
 	}
 
+	audit.RecordDecision(c, audit.ActionOptionsUpdated, uuid.MustParse(decisionID), models.AuditDetails{
+		"option_count": len(options),
+	})
+
 	c.JSON(http.StatusOK, gin.H{"options": options})
 }
 
-// Placeholder methods for interface compatibility
+// UpdateDecision applies a partial update, rejecting it if the decision changed since the client read it
 func (h *DecisionsHandler) UpdateDecision(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented yet"})
+	decisionID := c.Param("id")
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req models.UpdateDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		return
+	}
+	if msg := validateDecisionUpdate(&req); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	// Optimistic concurrency: If-Match header, or updated_at echoed back in the body
+	expectedETag := strings.TrimPrefix(c.GetHeader("If-Match"), "W/")
+	if expectedETag == "" && req.UpdatedAt != nil {
+		expectedETag = decisionETag(*req.UpdatedAt)
+	}
+	if expectedETag == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header or updated_at is required"})
+		return
+	}
+
+	tx, err := h.db.BeginTxx(c, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var decision models.CustomerDecision
+	err = tx.GetContext(c, &decision, `
+		SELECT cd.* FROM customer_decisions cd
+		JOIN team_members tm ON cd.team_id = tm.team_id
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true AND cd.deleted_at IS NULL
+		FOR UPDATE OF cd
+	`, decisionID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found"})
+		return
+	}
+
+	if currentETag := decisionETag(decision.UpdatedAt); currentETag != expectedETag {
+		c.Header("ETag", currentETag)
+		c.JSON(http.StatusPreconditionFailed, gin.H{
+			"error":    "Decision was modified by someone else",
+			"decision": decision,
+		})
+		return
+	}
+
+	// Rounds already run were scored under the current settings
+	if delphiSettingsChanged(&req) && (decision.DelphiRound > 1 || decision.EvaluationClosedAt != nil) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Evaluation mode and Delphi settings cannot change once a round has closed",
+			"round": decision.DelphiRound,
+		})
+		return
+	}
+
+	applyDecisionUpdate(&decision, &req)
+
+	err = tx.GetContext(c, &decision.UpdatedAt, `
+		UPDATE customer_decisions SET
+			customer_name = $1, customer_email = $2, customer_tier = $3, customer_value = $4,
+			relationship_duration_months = $5, customer_tier_detailed = $6, urgency_level_detailed = $7,
+			customer_impact_scope = $8, relationship_history = $9, previous_issues_count = $10,
+			last_interaction_date = $11, nps_score = $12, title = $13, description = $14,
+			decision_type = $15, urgency_level = $16, financial_impact = $17,
+			expected_resolution_date = $18, aggregation_method = $19, consensus_method = $20,
+			evaluation_mode = $21, delphi_max_rounds = $22, delphi_consensus_threshold = $23,
+			updated_at = NOW()
+		WHERE id = $24
+		RETURNING updated_at
+	`, decision.CustomerName, decision.CustomerEmail, decision.CustomerTier, decision.CustomerValue,
+		decision.RelationshipDurationMonths, decision.CustomerTierDetailed, decision.UrgencyLevelDetailed,
+		decision.CustomerImpactScope, decision.RelationshipHistory, decision.PreviousIssuesCount,
+		decision.LastInteractionDate, decision.NPSScore, decision.Title, decision.Description,
+		decision.DecisionType, decision.UrgencyLevel, decision.FinancialImpact,
+		decision.ExpectedResolutionDate, decision.AggregationMethod, decision.ConsensusMethod,
+		decision.EvaluationMode, decision.DelphiMaxRounds, decision.DelphiConsensusThreshold, decision.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update decision", "details": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update decision", "details": err.Error()})
+		return
+	}
+
+	audit.RecordDecision(c, audit.ActionDecisionUpdated, decision.ID, models.AuditDetails{
+		"fields": updatedDecisionFields(&req),
+	})
+
+	c.Header("ETag", decisionETag(decision.UpdatedAt))
+	c.JSON(http.StatusOK, gin.H{"decision": decision})
 }
 
+// DeleteDecision soft-deletes a decision; it can be restored until the retention window passes
 func (h *DecisionsHandler) DeleteDecision(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented yet"})
+	decisionID := c.Param("id")
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var deletedAt time.Time
+	err := h.db.GetContext(c, &deletedAt, `
+		UPDATE customer_decisions cd SET deleted_at = NOW(), deleted_by = tm.id, updated_at = NOW()
+		FROM team_members tm
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.team_id = cd.team_id AND tm.is_active = true
+			AND cd.deleted_at IS NULL
+		RETURNING cd.deleted_at
+	`, decisionID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found"})
+		return
+	}
+
+	audit.RecordDecision(c, audit.ActionDecisionDeleted, uuid.MustParse(decisionID), nil)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Decision deleted",
+		"deleted_at":    deletedAt,
+		"restore_until": deletedAt.Add(h.retention),
+	})
+}
+
+// RestoreDecision undoes a soft delete that is still inside the retention window
+func (h *DecisionsHandler) RestoreDecision(c *gin.Context) {
+	decisionID := c.Param("id")
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var decision models.CustomerDecision
+	err := h.db.GetContext(c, &decision, `
+		UPDATE customer_decisions cd SET deleted_at = NULL, deleted_by = NULL, updated_at = NOW()
+		FROM team_members tm
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.team_id = cd.team_id AND tm.is_active = true
+			AND cd.deleted_at IS NOT NULL AND cd.deleted_at > NOW() - make_interval(secs => $3)
+		RETURNING cd.*
+	`, decisionID, userID, h.retention.Seconds())
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Deleted decision not found"})
+		return
+	}
+
+	audit.RecordDecision(c, audit.ActionDecisionRestored, decision.ID, nil)
+
+	c.Header("ETag", decisionETag(decision.UpdatedAt))
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Decision restored",
+		"decision": decision,
+	})
+}
+
+// updatedDecisionFields lists the fields a partial update set, for the audit trail
+func updatedDecisionFields(req *models.UpdateDecisionRequest) []string {
+	raw, _ := json.Marshal(req)
+	var set map[string]interface{}
+	_ = json.Unmarshal(raw, &set)
+	delete(set, "updated_at")
+
+	fields := make([]string, 0, len(set))
+	for field := range set {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// decisionETag derives a version tag from the decision's updated_at
+func decisionETag(updatedAt time.Time) string {
+	return fmt.Sprintf(`"%d"`, updatedAt.UnixMicro())
+}
+
+// validateDecisionUpdate returns a message describing the first invalid field, or ""
+func validateDecisionUpdate(req *models.UpdateDecisionRequest) string {
+	for field, value := range map[string]*string{
+		"customer_name": req.CustomerName,
+		"customer_tier": req.CustomerTier,
+		"title":         req.Title,
+		"description":   req.Description,
+		"decision_type": req.DecisionType,
+	} {
+		if value != nil && strings.TrimSpace(*value) == "" {
+			return field + " cannot be empty"
+		}
+	}
+	if req.UrgencyLevel != nil && (*req.UrgencyLevel < 1 || *req.UrgencyLevel > 5) {
+		return "urgency_level must be between 1 and 5"
+	}
+	if req.NPSScore != nil && (*req.NPSScore < 0 || *req.NPSScore > 10) {
+		return "nps_score must be between 0 and 10"
+	}
+	if req.AggregationMethod != nil {
+		if _, err := evaluation.AggregatorFor(*req.AggregationMethod); err != nil || *req.AggregationMethod == "" {
+			return "aggregation_method must be one of " + strings.Join(evaluation.AggregationNames(), ", ")
+		}
+	}
+	if req.ConsensusMethod != nil {
+		if _, err := evaluation.ConsensusMeasureFor(*req.ConsensusMethod); err != nil || *req.ConsensusMethod == "" {
+			return "consensus_method must be one of " + strings.Join(evaluation.ConsensusNames(), ", ")
+		}
+	}
+	if req.EvaluationMode != nil && *req.EvaluationMode != evaluation.ModeSingle && *req.EvaluationMode != evaluation.ModeDelphi {
+		return "evaluation_mode must be one of " + strings.Join(evaluation.EvaluationModes(), ", ")
+	}
+	if req.DelphiMaxRounds != nil && (*req.DelphiMaxRounds < 2 || *req.DelphiMaxRounds > 10) {
+		return "delphi_max_rounds must be between 2 and 10"
+	}
+	if req.DelphiConsensusThreshold != nil && (*req.DelphiConsensusThreshold <= 0 || *req.DelphiConsensusThreshold > 1) {
+		return "delphi_consensus_threshold must be greater than 0 and at most 1"
+	}
+	return ""
+}
+
+// delphiSettingsChanged reports whether the update touches the evaluation mode or Delphi settings
+func delphiSettingsChanged(req *models.UpdateDecisionRequest) bool {
+	return req.EvaluationMode != nil || req.DelphiMaxRounds != nil || req.DelphiConsensusThreshold != nil
+}
+
+// applyDecisionUpdate copies the fields present in the request onto the decision
+func applyDecisionUpdate(decision *models.CustomerDecision, req *models.UpdateDecisionRequest) {
+	setString := func(dst *string, src *string) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	setInt := func(dst *int, src *int) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+
+	setString(&decision.CustomerName, req.CustomerName)
+	setString(&decision.CustomerTier, req.CustomerTier)
+	setString(&decision.CustomerTierDetailed, req.CustomerTierDetailed)
+	setString(&decision.UrgencyLevelDetailed, req.UrgencyLevelDetailed)
+	setString(&decision.CustomerImpactScope, req.CustomerImpactScope)
+	setString(&decision.Title, req.Title)
+	setString(&decision.Description, req.Description)
+	setString(&decision.DecisionType, req.DecisionType)
+	setString(&decision.AggregationMethod, req.AggregationMethod)
+	setString(&decision.ConsensusMethod, req.ConsensusMethod)
+	setString(&decision.EvaluationMode, req.EvaluationMode)
+	setInt(&decision.RelationshipDurationMonths, req.RelationshipDurationMonths)
+	setInt(&decision.PreviousIssuesCount, req.PreviousIssuesCount)
+	setInt(&decision.UrgencyLevel, req.UrgencyLevel)
+	setInt(&decision.DelphiMaxRounds, req.DelphiMaxRounds)
+
+	if req.CustomerEmail != nil {
+		decision.CustomerEmail = req.CustomerEmail
+	}
+	if req.CustomerValue != nil {
+		decision.CustomerValue = req.CustomerValue
+	}
+	if req.RelationshipHistory != nil {
+		decision.RelationshipHistory = req.RelationshipHistory
+	}
+	if req.LastInteractionDate != nil {
+		decision.LastInteractionDate = req.LastInteractionDate
+	}
+	if req.NPSScore != nil {
+		decision.NPSScore = req.NPSScore
+	}
+	if req.FinancialImpact != nil {
+		decision.FinancialImpact = req.FinancialImpact
+	}
+	if req.ExpectedResolutionDate != nil {
+		decision.ExpectedResolutionDate = req.ExpectedResolutionDate
+	}
+	if req.DelphiConsensusThreshold != nil {
+		decision.DelphiConsensusThreshold = *req.DelphiConsensusThreshold
+	}
 }
 
 func (h *DecisionsHandler) GetCriteria(c *gin.Context) {
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"choseby-backend/internal/testutil"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+const testDecisionID = "550e8400-e29b-41d4-a716-446655440002"
+
+type DecisionsHandlerSuite struct {
+	testutil.TestSuite
+	router *gin.Engine
+}
+
+func (s *DecisionsHandlerSuite) SetupTest() {
+	s.TestSuite.SetupTest()
+	gin.SetMode(gin.TestMode)
+
+	handler := NewDecisionsHandler(s.DB, s.AuthService, 30, 72, nil)
+	s.router = gin.New()
+	s.router.Use(func(c *gin.Context) {
+		c.Set("user_id", testutil.MockJWTClaims().UserID)
+	})
+	s.router.PUT("/decisions/:id", handler.UpdateDecision)
+	s.router.DELETE("/decisions/:id", handler.DeleteDecision)
+	s.router.POST("/decisions/:id/restore", handler.RestoreDecision)
+	s.router.PUT("/decisions/:id/stakeholder-weighting", handler.UpdateStakeholderWeighting)
+}
+
+func (s *DecisionsHandlerSuite) do(method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func (s *DecisionsHandlerSuite) decisionRows(updatedAt time.Time) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "team_id", "title", "status", "current_phase", "updated_at"}).
+		AddRow(testDecisionID, testutil.MockJWTClaims().TeamID, "Refund request", "created", 1, updatedAt)
+}
+
+func (s *DecisionsHandlerSuite) TestUpdateRequiresIfMatch() {
+	w := s.do(http.MethodPut, "/decisions/"+testDecisionID, `{"title":"Updated"}`, nil)
+	s.Equal(http.StatusPreconditionRequired, w.Code)
+}
+
+func (s *DecisionsHandlerSuite) TestUpdateRejectsStaleETag() {
+	seen := time.Date(2025, 10, 20, 9, 0, 0, 0, time.UTC)
+	current := seen.Add(time.Minute)
+
+	s.Mock.ExpectBegin()
+	s.Mock.ExpectQuery("SELECT cd.\\* FROM customer_decisions cd").
+		WithArgs(testDecisionID, testutil.MockJWTClaims().UserID).
+		WillReturnRows(s.decisionRows(current))
+	s.Mock.ExpectRollback()
+
+	w := s.do(http.MethodPut, "/decisions/"+testDecisionID, `{"title":"Updated"}`,
+		map[string]string{"If-Match": decisionETag(seen)})
+	s.Equal(http.StatusPreconditionFailed, w.Code)
+	s.Equal(decisionETag(current), w.Header().Get("ETag"))
+}
+
+func (s *DecisionsHandlerSuite) TestRestoreAfterDelete() {
+	deletedAt := time.Now().UTC()
+	restoredAt := deletedAt.Add(time.Minute)
+
+	s.Mock.ExpectQuery("UPDATE customer_decisions cd SET deleted_at = NOW\\(\\)").
+		WithArgs(testDecisionID, testutil.MockJWTClaims().UserID).
+		WillReturnRows(sqlmock.NewRows([]string{"deleted_at"}).AddRow(deletedAt))
+	s.Mock.ExpectQuery("UPDATE customer_decisions cd SET deleted_at = NULL").
+		WithArgs(testDecisionID, testutil.MockJWTClaims().UserID, float64(30*24*60*60)).
+		WillReturnRows(s.decisionRows(restoredAt))
+
+	w := s.do(http.MethodDelete, "/decisions/"+testDecisionID, "", nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var deleted struct {
+		RestoreUntil time.Time `json:"restore_until"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &deleted))
+	s.WithinDuration(deletedAt.Add(30*24*time.Hour), deleted.RestoreUntil, time.Second)
+
+	w = s.do(http.MethodPost, "/decisions/"+testDecisionID+"/restore", "", nil)
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal(decisionETag(restoredAt), w.Header().Get("ETag"))
+}
+
+func (s *DecisionsHandlerSuite) TestRestoreOutsideRetentionWindow() {
+	s.Mock.ExpectQuery("UPDATE customer_decisions cd SET deleted_at = NULL").
+		WillReturnError(sql.ErrNoRows)
+
+	w := s.do(http.MethodPost, "/decisions/"+testDecisionID+"/restore", "", nil)
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *DecisionsHandlerSuite) TestAISeededWeightsAreClamped() {
+	recommendations := `{"recommended_stakeholders":[` +
+		`{"role":"support_manager","weight":25},{"role":"account_manager","weight":-3}],"suggested_criteria":[]}`
+	s.Mock.ExpectQuery("SELECT cd.\\* FROM customer_decisions cd").
+		WithArgs(testDecisionID, testutil.MockJWTClaims().UserID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "team_id", "status", "current_phase", "ai_recommendations"}).
+			AddRow(testDecisionID, testutil.MockJWTClaims().TeamID, "evaluating", 4, []byte(recommendations)))
+	s.Mock.ExpectExec("UPDATE customer_decisions SET stakeholder_weighting").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := s.do(http.MethodPut, "/decisions/"+testDecisionID+"/stakeholder-weighting", `{"source":"ai"}`, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var body struct {
+		Weighting struct {
+			RoleWeights   map[string]float64 `json:"role_weights"`
+			DefaultWeight float64            `json:"default_weight"`
+		} `json:"stakeholder_weighting"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &body))
+	s.Equal(map[string]float64{"support_manager": 10, "account_manager": 0}, body.Weighting.RoleWeights)
+	s.Zero(body.Weighting.DefaultWeight)
+}
+
+func TestDecisionsHandlerSuite(t *testing.T) {
+	suite.Run(t, new(DecisionsHandlerSuite))
+}
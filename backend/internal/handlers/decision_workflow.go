@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"choseby-backend/internal/audit"
+	"choseby-backend/internal/evaluation"
+	"choseby-backend/internal/models"
+	"choseby-backend/internal/realtime"
+	"choseby-backend/internal/workflow"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TransitionDecision moves a decision to another lifecycle state after checking the state machine and its guards
+func (h *DecisionsHandler) TransitionDecision(c *gin.Context) {
+	decisionID := c.Param("id")
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	type TransitionRequest struct {
+		To               string     `json:"to" binding:"required"`
+		Reason           *string    `json:"reason,omitempty"`
+		SelectedOptionID *uuid.UUID `json:"selected_option_id,omitempty"`
+		// Overrides the default deadline (now + EVALUATION_TIMEOUT_HOURS) when entering evaluation
+		EvaluationDeadline *time.Time `json:"evaluation_deadline,omitempty"`
+	}
+
+	var req TransitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		return
+	}
+
+	to, err := workflow.ParseState(req.To)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target state", "details": err.Error()})
+		return
+	}
+
+	tx, err := h.db.BeginTxx(c, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Lock the decision so concurrent transitions are applied one at a time
+	var decision models.CustomerDecision
+	err = tx.GetContext(c, &decision, `
+		SELECT cd.* FROM customer_decisions cd
+		JOIN team_members tm ON cd.team_id = tm.team_id
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true AND cd.deleted_at IS NULL
+		FOR UPDATE OF cd
+	`, decisionID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found"})
+		return
+	}
+
+	from := workflow.StateOf(decision.Status, decision.CurrentPhase)
+	if err := workflow.CanTransition(from, to); err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Transition not allowed",
+			"details": err.Error(),
+			"from":    from,
+			"allowed": workflow.AllowedTransitions(from),
+		})
+		return
+	}
+
+	selectedOptionID := decision.SelectedOptionID
+	if req.SelectedOptionID != nil {
+		var optionCount int
+		err = tx.GetContext(c, &optionCount, `
+			SELECT COUNT(*) FROM response_options WHERE id = $1 AND decision_id = $2
+		`, *req.SelectedOptionID, decision.ID)
+		if err != nil || optionCount == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Selected option does not belong to this decision"})
+			return
+		}
+		selectedOptionID = req.SelectedOptionID
+	}
+
+	facts := workflow.GuardFacts{SelectedOptionID: selectedOptionID}
+	err = tx.GetContext(c, &facts, `
+		SELECT
+			(SELECT COUNT(*) FROM decision_criteria WHERE decision_id = $1) AS criteria_count,
+			(SELECT COUNT(*) FROM response_options WHERE decision_id = $1) AS option_count,
+			(SELECT COUNT(*) FROM evaluations WHERE decision_id = $1) AS evaluation_count,
+			(SELECT COUNT(*) FROM response_drafts WHERE decision_id = $1) AS draft_count
+	`, decision.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check transition guards", "details": err.Error()})
+		return
+	}
+
+	if err := workflow.CheckGuards(from, to, facts); err != nil {
+		var guardErr *workflow.GuardError
+		if errors.As(err, &guardErr) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Transition guard failed", "details": guardErr.Reason, "from": from, "to": to})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check transition guards", "details": err.Error()})
+		return
+	}
+
+	status, phase := workflow.StatusAndPhase(to)
+	if phase == 0 {
+		phase = decision.CurrentPhase
+	}
+
+	now := time.Now()
+	var resolvedAt *time.Time
+	if to == workflow.StateResolved {
+		resolvedAt = &now
+	}
+
+	// Entering evaluation opens a new round with a fresh deadline
+	evaluationDeadline, evaluationClosedAt := decision.EvaluationDeadline, decision.EvaluationClosedAt
+	if to == workflow.StateEvaluation {
+		deadline := now.Add(h.evalTimeout)
+		if req.EvaluationDeadline != nil {
+			if !req.EvaluationDeadline.After(now) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "evaluation_deadline must be in the future"})
+				return
+			}
+			deadline = *req.EvaluationDeadline
+		}
+		evaluationDeadline, evaluationClosedAt = &deadline, nil
+
+		if err := evaluation.ReopenRound(c, tx, &decision); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open Delphi round", "details": err.Error()})
+			return
+		}
+	}
+
+	// Moving on to drafting closes evaluation and freezes the results, unless the scheduler already did
+	if from == workflow.StateEvaluation && to == workflow.StateDrafting && evaluationClosedAt == nil {
+		results, err := evaluation.Compute(c, tx, &decision)
+		if err == nil {
+			err = evaluation.Freeze(c, tx, decision.ID, results, evaluation.CloseReasonManual, now)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to freeze evaluation results", "details": err.Error()})
+			return
+		}
+		evaluationClosedAt = &now
+	}
+
+	_, err = tx.ExecContext(c, `
+		UPDATE customer_decisions SET
+			status = $1,
+			current_phase = $2,
+			selected_option_id = $3,
+			actual_resolution_date = COALESCE($4, actual_resolution_date),
+			evaluation_deadline = $5,
+			evaluation_closed_at = $6,
+			updated_at = $7
+		WHERE id = $8
+	`, status, phase, selectedOptionID, resolvedAt, evaluationDeadline, evaluationClosedAt, now, decision.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update decision", "details": err.Error()})
+		return
+	}
+
+	actorID := userID.(uuid.UUID)
+	transition := models.DecisionTransition{
+		ID:         uuid.New(),
+		DecisionID: decision.ID,
+		FromState:  string(from),
+		ToState:    string(to),
+		ActorID:    &actorID,
+		Reason:     req.Reason,
+		CreatedAt:  now,
+	}
+	_, err = tx.NamedExecContext(c, `
+		INSERT INTO decision_transitions (id, decision_id, from_state, to_state, actor_id, reason, created_at)
+		VALUES (:id, :decision_id, :from_state, :to_state, :actor_id, :reason, :created_at)
+	`, transition)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record transition", "details": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transition", "details": err.Error()})
+		return
+	}
+
+	audit.RecordDecision(c, audit.ActionDecisionTransition, decision.ID, models.AuditDetails{
+		"from":   from,
+		"to":     to,
+		"reason": req.Reason,
+	})
+	publishDecisionEvent(c, h.hub, realtime.EventDecisionStatusChanged, decision.ID, gin.H{
+		"from":          from,
+		"to":            to,
+		"status":        status,
+		"current_phase": phase,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"decision_id":         decision.ID,
+		"state":               to,
+		"status":              status,
+		"current_phase":       phase,
+		"selected_option_id":  selectedOptionID,
+		"evaluation_deadline": evaluationDeadline,
+		"allowed":             workflow.AllowedTransitions(to),
+		"transition":          transition,
+	})
+}
+
+// GetTransitions returns the decision's current state and its transition history
+func (h *DecisionsHandler) GetTransitions(c *gin.Context) {
+	decisionID := c.Param("id")
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var decision models.CustomerDecision
+	err := h.db.GetContext(c, &decision, `
+		SELECT cd.* FROM customer_decisions cd
+		JOIN team_members tm ON cd.team_id = tm.team_id
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true AND cd.deleted_at IS NULL
+	`, decisionID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found"})
+		return
+	}
+
+	transitions := []models.DecisionTransition{}
+	err = h.db.SelectContext(c, &transitions, `
+		SELECT * FROM decision_transitions WHERE decision_id = $1 ORDER BY created_at
+	`, decision.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transitions", "details": err.Error()})
+		return
+	}
+
+	state := workflow.StateOf(decision.Status, decision.CurrentPhase)
+	c.JSON(http.StatusOK, gin.H{
+		"decision_id": decision.ID,
+		"state":       state,
+		"allowed":     workflow.AllowedTransitions(state),
+		"transitions": transitions,
+	})
+}
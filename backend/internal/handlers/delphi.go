@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"choseby-backend/internal/audit"
+	"choseby-backend/internal/evaluation"
+	"choseby-backend/internal/models"
+	"choseby-backend/internal/realtime"
+	"choseby-backend/internal/workflow"
+	"github.com/gin-gonic/gin"
+)
+
+// GetDelphiRounds returns the decision's Delphi settings, the open round and how consensus converged
+func (h *DecisionsHandler) GetDelphiRounds(c *gin.Context) {
+	decision, ok := h.loadDecisionForWeighting(c)
+	if !ok {
+		return
+	}
+	if decision.EvaluationMode != evaluation.ModeDelphi {
+		c.JSON(http.StatusConflict, gin.H{"error": "Decision is not evaluated in Delphi rounds", "evaluation_mode": decision.EvaluationMode})
+		return
+	}
+
+	progress, err := evaluation.LoadProgress(c, h.db, decision)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load Delphi rounds", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"decision_id":          decision.ID,
+		"evaluation_deadline":  decision.EvaluationDeadline,
+		"evaluation_closed_at": decision.EvaluationClosedAt,
+		"delphi":               progress,
+	})
+}
+
+// CloseDelphiRound closes the open Delphi round ahead of its deadline. The next round opens unless
+// consensus reached the threshold or this was the last round; then evaluation closes and the results
+// freeze, and the team moves the decision on to drafting.
+func (h *DecisionsHandler) CloseDelphiRound(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	tx, err := h.db.BeginTxx(c, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var decision models.CustomerDecision
+	err = tx.GetContext(c, &decision, `
+		SELECT cd.* FROM customer_decisions cd
+		JOIN team_members tm ON cd.team_id = tm.team_id
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true AND cd.deleted_at IS NULL
+		FOR UPDATE OF cd
+	`, c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found"})
+		return
+	}
+
+	if decision.EvaluationMode != evaluation.ModeDelphi {
+		c.JSON(http.StatusConflict, gin.H{"error": "Decision is not evaluated in Delphi rounds", "evaluation_mode": decision.EvaluationMode})
+		return
+	}
+	state := workflow.StateOf(decision.Status, decision.CurrentPhase)
+	if state != workflow.StateEvaluation || decision.EvaluationClosedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     "Evaluation is not open for this decision",
+			"state":     state,
+			"closed_at": decision.EvaluationClosedAt,
+		})
+		return
+	}
+
+	now := time.Now()
+	round, results, err := evaluation.CloseRound(c, tx, &decision, evaluation.CloseReasonManual, now, now.Add(h.evalTimeout))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to close Delphi round", "details": err.Error()})
+		return
+	}
+
+	stopReason := ""
+	if round.StopReason != nil {
+		stopReason = *round.StopReason
+		if err := evaluation.Freeze(c, tx, decision.ID, results, stopReason, now); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to freeze evaluation results", "details": err.Error()})
+			return
+		}
+		_, err = tx.ExecContext(c, `
+			UPDATE customer_decisions SET evaluation_closed_at = $1, updated_at = $1 WHERE id = $2
+		`, now, decision.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to close evaluation", "details": err.Error()})
+			return
+		}
+		decision.EvaluationClosedAt = &now
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to close Delphi round", "details": err.Error()})
+		return
+	}
+
+	audit.RecordDecision(c, audit.ActionDelphiRoundClosed, decision.ID, models.AuditDetails{
+		"round":          round.RoundNumber,
+		"reason":         round.CloseReason,
+		"team_consensus": round.TeamConsensus,
+		"stop_reason":    round.StopReason,
+	})
+	publishDecisionEvent(c, h.hub, realtime.EventDelphiRoundClosed, decision.ID, gin.H{
+		"round":          round.RoundNumber,
+		"reason":         round.CloseReason,
+		"team_consensus": round.TeamConsensus,
+		"next_round":     decision.DelphiRound,
+		"deadline":       decision.EvaluationDeadline,
+		"stop_reason":    round.StopReason,
+	})
+	if stopReason != "" {
+		publishDecisionEvent(c, h.hub, realtime.EventEvaluationClosed, decision.ID, gin.H{
+			"reason":             stopReason,
+			"recommended_option": results.RecommendedOption,
+			"participation_rate": results.ParticipationRate,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"decision_id":          decision.ID,
+		"round":                round,
+		"evaluation_round":     decision.DelphiRound,
+		"evaluation_deadline":  decision.EvaluationDeadline,
+		"evaluation_closed_at": decision.EvaluationClosedAt,
+		"stop_reason":          round.StopReason,
+	})
+}
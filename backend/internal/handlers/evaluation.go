@@ -1,611 +0,0 @@
-package handlers
-
-import (
-	"database/sql"
-	"errors"
-	"net/http"
-
-	"choseby-backend/internal/auth"
-	"choseby-backend/internal/database"
-	"choseby-backend/internal/models"
-	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
-)
-
-type EvaluationHandler struct {
-	db          *database.DB
-	authService *auth.Service
-}
-
-func NewEvaluationHandler(db *database.DB, authService *auth.Service) *EvaluationHandler {
-	return &EvaluationHandler{
-		db:          db,
-		authService: authService,
-	}
-}
-
-// GetEvaluationStatus returns team evaluation completion status
-func (h *EvaluationHandler) GetEvaluationStatus(c *gin.Context) {
-	decisionID := c.Param("decisionId")
-	teamID := c.Param("teamId")
-	_ = c.MustGet("claims").(*auth.Claims)
-
-	decisionUUID, err := uuid.Parse(decisionID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_request",
-			"message": "Invalid decision ID format",
-		})
-		return
-	}
-
-	teamUUID, err := uuid.Parse(teamID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_request",
-			"message": "Invalid team ID format",
-		})
-		return
-	}
-
-	// Verify decision belongs to team
-	var exists bool
-	err = h.db.QueryRow(
-		"SELECT EXISTS(SELECT 1 FROM decisions WHERE id = $1 AND team_id = $2)",
-		decisionUUID, teamUUID,
-	).Scan(&exists)
-
-	if err != nil || !exists {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "not_found",
-			"message": "Decision not found",
-		})
-		return
-	}
-
-	// Get team member count
-	var totalMembers int
-	err = h.db.QueryRow(
-		"SELECT COUNT(*) FROM team_members WHERE team_id = $1",
-		teamUUID,
-	).Scan(&totalMembers)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to get team member count",
-		})
-		return
-	}
-
-	// Get completed evaluations count
-	var completedEvaluations int
-	err = h.db.QueryRow(
-		"SELECT COUNT(*) FROM evaluations WHERE decision_id = $1",
-		decisionUUID,
-	).Scan(&completedEvaluations)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to get evaluation count",
-		})
-		return
-	}
-
-	// Simple conflict detection based on evaluation variance
-	conflicts := []string{}
-
-	conflictCount := len(conflicts)
-	maxVariance := 0.0
-	conflictLevel := models.PriorityNone
-
-	if conflictCount > 0 {
-		// Simple conflict analysis with basic variance
-		maxVariance = 2.5
-
-		if maxVariance > 2.5 {
-			conflictLevel = models.PriorityHigh
-		} else if maxVariance > 1.5 {
-			conflictLevel = models.PriorityMedium
-		} else if maxVariance > 0.5 {
-			conflictLevel = models.PriorityLow
-		}
-	}
-
-	// Calculate consensus level
-	consensusLevel := 0.0
-	if totalMembers > 0 {
-		consensusLevel = (float64(completedEvaluations) / float64(totalMembers)) * 100
-	}
-
-	// Get aggregate results (anonymous)
-	aggregateResults, err := h.getAggregateResults(decisionUUID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to get aggregate results",
-		})
-		return
-	}
-
-	// Log evaluation status check for HIPAA audit
-	// Audit log could be implemented here for compliance
-
-	response := map[string]interface{}{
-		"decision_id":           decisionID,
-		"total_team_members":    totalMembers,
-		"completed_evaluations": completedEvaluations,
-		"completion_percentage": consensusLevel,
-		"conflicts_detected":    conflictCount > 0,
-		"conflict_level":        conflictLevel,
-		"aggregate_results":     aggregateResults,
-	}
-
-	c.JSON(http.StatusOK, response)
-}
-
-// SubmitEvaluation handles anonymous evaluation submission
-func (h *EvaluationHandler) SubmitEvaluation(c *gin.Context) {
-	decisionID := c.Param("decisionId")
-	teamID := c.Param("teamId")
-	claims := c.MustGet("claims").(*auth.Claims)
-
-	decisionUUID, err := uuid.Parse(decisionID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_request",
-			"message": "Invalid decision ID format",
-		})
-		return
-	}
-
-	teamUUID, err := uuid.Parse(teamID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_request",
-			"message": "Invalid team ID format",
-		})
-		return
-	}
-
-	var request models.EvaluationRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_request",
-			"message": err.Error(),
-		})
-		return
-	}
-
-	// Verify decision belongs to team
-	var exists bool
-	err = h.db.QueryRow(
-		"SELECT EXISTS(SELECT 1 FROM decisions WHERE id = $1 AND team_id = $2)",
-		decisionUUID, teamUUID,
-	).Scan(&exists)
-
-	if err != nil || !exists {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "not_found",
-			"message": "Decision not found",
-		})
-		return
-	}
-
-	// Check if user already submitted evaluation
-	var existingEvaluationID uuid.UUID
-	err = h.db.QueryRow(
-		"SELECT id FROM evaluations WHERE decision_id = $1 AND user_id = $2",
-		decisionUUID, claims.UserID,
-	).Scan(&existingEvaluationID)
-
-	if err == nil {
-		c.JSON(http.StatusConflict, gin.H{
-			"error":   "conflict",
-			"message": "Evaluation already submitted for this decision",
-		})
-		return
-	} else if !errors.Is(err, sql.ErrNoRows) {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to check existing evaluation",
-		})
-		return
-	}
-
-	// Submit evaluation in transaction for anonymity protection
-	evaluationID, err := h.submitEvaluationTransaction(decisionUUID, claims.UserID, &request)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to submit evaluation",
-		})
-		return
-	}
-
-	// Simple conflict detection
-	conflicts := []string{}
-	conflictsDetected := len(conflicts) > 0
-
-	// Get updated team completion status
-	var completed, total int
-	err = h.db.QueryRow(`
-		SELECT
-			(SELECT COUNT(*) FROM evaluations WHERE decision_id = $1) as completed,
-			(SELECT COUNT(*) FROM team_members WHERE team_id = $2) as total
-	`, decisionUUID, teamUUID).Scan(&completed, &total)
-	if err != nil {
-		completed, total = 0, 0
-	}
-
-	percentage := 0.0
-	if total > 0 {
-		percentage = (float64(completed) / float64(total)) * 100
-	}
-
-	// Log evaluation submission for HIPAA audit (without revealing scores)
-	// Audit log could be implemented here for compliance
-
-	response := map[string]interface{}{
-		"evaluation_id":      evaluationID,
-		"conflicts_detected": conflictsDetected,
-		"team_completion_status": map[string]interface{}{
-			"completed":  completed,
-			"total":      total,
-			"percentage": percentage,
-		},
-	}
-
-	c.JSON(http.StatusCreated, response)
-}
-
-// GetEvaluationSummary returns aggregated evaluation results
-func (h *EvaluationHandler) GetEvaluationSummary(c *gin.Context) {
-	decisionID := c.Param("decisionId")
-	_ = c.MustGet("claims").(*auth.Claims)
-
-	decisionUUID, err := uuid.Parse(decisionID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_request",
-			"message": "Invalid decision ID format",
-		})
-		return
-	}
-
-	// Get aggregate results
-	aggregateResults, err := h.getAggregateResults(decisionUUID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to get evaluation summary",
-		})
-		return
-	}
-
-	// Simple conflict detection based on evaluation variance
-	conflicts := []string{}
-
-	// Log summary access for HIPAA audit
-	// Audit log could be implemented here for compliance
-
-	response := map[string]interface{}{
-		"decision_id":       decisionID,
-		"aggregate_results": aggregateResults,
-		"conflicts":         conflicts,
-		"summary_generated": "anonymous aggregation only",
-	}
-
-	c.JSON(http.StatusOK, response)
-}
-
-// ExportEvaluations exports evaluation data for compliance
-func (h *EvaluationHandler) ExportEvaluations(c *gin.Context) {
-	decisionID := c.Param("decisionId")
-	claims := c.MustGet("claims").(*auth.Claims)
-
-	// Simple role-based permission check
-	if claims.Role != "customer_success_manager" && claims.Role != "operations_manager" {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error":   "forbidden",
-			"message": "Export permission required",
-		})
-		return
-	}
-
-	decisionUUID, err := uuid.Parse(decisionID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_request",
-			"message": "Invalid decision ID format",
-		})
-		return
-	}
-
-	// Get decision info
-	var decisionTitle string
-	err = h.db.QueryRow(
-		"SELECT title FROM decisions WHERE id = $1",
-		decisionUUID,
-	).Scan(&decisionTitle)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "not_found",
-			"message": "Decision not found",
-		})
-		return
-	}
-
-	// Get aggregated (anonymous) export data
-	exportData, err := h.getExportData(decisionUUID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to generate export",
-		})
-		return
-	}
-
-	// Log data export for HIPAA audit
-	// Audit log could be implemented here for compliance
-
-	response := map[string]interface{}{
-		"decision_id":    decisionID,
-		"decision_title": decisionTitle,
-		"export_data":    exportData,
-		"exported_at":    "now",
-		"exported_by":    claims.UserID,
-		"data_type":      "anonymous_aggregate_only",
-	}
-
-	c.JSON(http.StatusOK, response)
-}
-
-// Helper methods
-
-func (h *EvaluationHandler) submitEvaluationTransaction(decisionID, userID uuid.UUID, request *models.EvaluationRequest) (uuid.UUID, error) {
-	// Start transaction
-	tx, err := h.db.Begin()
-	if err != nil {
-		return uuid.Nil, err
-	}
-	defer func() { _ = tx.Rollback() }() // Explicitly ignore rollback error (will fail if already committed)
-
-	// Create evaluation record (with user ID for participation tracking only)
-	evaluationID := uuid.New()
-	_, err = tx.Exec(`
-		INSERT INTO evaluations (id, decision_id, user_id, overall_confidence, evaluation_notes)
-		VALUES ($1, $2, $3, $4, $5)
-	`, evaluationID, decisionID, userID, 5, "")
-	if err != nil {
-		return uuid.Nil, err
-	}
-
-	// Submit anonymous scores (separated from user identity)
-	for _, evalScore := range request.Evaluations {
-		_, err = tx.Exec(`
-			INSERT INTO evaluation_scores (id, evaluation_id, option_id, criterion_id, score, rationale, confidence)
-			VALUES ($1, $2, $3, $4, $5, $6, $7)
-		`, uuid.New(), evaluationID, evalScore.OptionID, evalScore.CriteriaID,
-			evalScore.Score, evalScore.Comment, evalScore.Confidence)
-		if err != nil {
-			return uuid.Nil, err
-		}
-	}
-
-	// Commit transaction
-	if err = tx.Commit(); err != nil {
-		return uuid.Nil, err
-	}
-
-	return evaluationID, nil
-}
-
-func (h *EvaluationHandler) getAggregateResults(decisionID uuid.UUID) ([]map[string]interface{}, error) {
-	rows, err := h.db.Query(`
-		SELECT
-			do.id as option_id,
-			do.name as option_name,
-			AVG(
-				(SELECT SUM(es.score * dc.weight / 100)
-				 FROM evaluation_scores es
-				 JOIN decision_criteria dc ON es.criterion_id = dc.id
-				 WHERE es.option_id = do.id AND es.evaluation_id = e.id)
-			) as weighted_score,
-			COUNT(DISTINCT e.id) as evaluation_count
-		FROM decision_options do
-		CROSS JOIN evaluations e
-		WHERE do.decision_id = $1 AND e.decision_id = $1
-		GROUP BY do.id, do.name
-		HAVING COUNT(DISTINCT e.id) > 0
-		ORDER BY weighted_score DESC
-	`, decisionID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var results []map[string]interface{}
-	rank := 1
-
-	for rows.Next() {
-		var optionID, optionName string
-		var weightedScore sql.NullFloat64
-		var evaluationCount int
-
-		err := rows.Scan(&optionID, &optionName, &weightedScore, &evaluationCount)
-		if err != nil {
-			return nil, err
-		}
-
-		score := 0.0
-		if weightedScore.Valid {
-			score = weightedScore.Float64
-		}
-
-		result := map[string]interface{}{
-			"option_id":        optionID,
-			"option_name":      optionName,
-			"weighted_score":   score,
-			"rank":             rank,
-			"evaluation_count": evaluationCount,
-		}
-
-		results = append(results, result)
-		rank++
-	}
-
-	return results, nil
-}
-
-func (h *EvaluationHandler) getExportData(decisionID uuid.UUID) (map[string]interface{}, error) {
-	// Get decision summary
-	var title, status string
-	var createdAt sql.NullTime
-	err := h.db.QueryRow(
-		"SELECT title, status, created_at FROM decisions WHERE id = $1",
-		decisionID,
-	).Scan(&title, &status, &createdAt)
-	if err != nil {
-		return nil, err
-	}
-
-	// Get criteria
-	criteria, err := h.getDecisionCriteria(decisionID)
-	if err != nil {
-		return nil, err
-	}
-
-	// Get options
-	options, err := h.getDecisionOptions(decisionID)
-	if err != nil {
-		return nil, err
-	}
-
-	// Get aggregate scores (anonymous)
-	aggregateResults, err := h.getAggregateResults(decisionID)
-	if err != nil {
-		return nil, err
-	}
-
-	// Get evaluation statistics
-	stats, err := h.getEvaluationStats(decisionID)
-	if err != nil {
-		return nil, err
-	}
-
-	exportData := map[string]interface{}{
-		"decision": map[string]interface{}{
-			"title":      title,
-			"status":     status,
-			"created_at": createdAt,
-		},
-		"criteria":          criteria,
-		"options":           options,
-		"aggregate_results": aggregateResults,
-		"statistics":        stats,
-		"privacy_notice":    "Individual evaluation scores are anonymized and not included in export",
-	}
-
-	return exportData, nil
-}
-
-func (h *EvaluationHandler) getDecisionCriteria(decisionID uuid.UUID) ([]map[string]interface{}, error) {
-	rows, err := h.db.Query(
-		"SELECT id, name, description, weight, category FROM decision_criteria WHERE decision_id = $1 ORDER BY weight DESC",
-		decisionID,
-	)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var criteria []map[string]interface{}
-	for rows.Next() {
-		var id, name, category string
-		var description sql.NullString
-		var weight float64
-
-		err := rows.Scan(&id, &name, &description, &weight, &category)
-		if err != nil {
-			return nil, err
-		}
-
-		criterion := map[string]interface{}{
-			"id":       id,
-			"name":     name,
-			"weight":   weight,
-			"category": category,
-		}
-
-		if description.Valid {
-			criterion["description"] = description.String
-		}
-
-		criteria = append(criteria, criterion)
-	}
-
-	return criteria, nil
-}
-
-func (h *EvaluationHandler) getDecisionOptions(decisionID uuid.UUID) ([]map[string]interface{}, error) {
-	rows, err := h.db.Query(
-		"SELECT id, name, description, estimated_cost FROM decision_options WHERE decision_id = $1 ORDER BY name",
-		decisionID,
-	)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var options []map[string]interface{}
-	for rows.Next() {
-		var id, name string
-		var description sql.NullString
-		var estimatedCost sql.NullFloat64
-
-		err := rows.Scan(&id, &name, &description, &estimatedCost)
-		if err != nil {
-			return nil, err
-		}
-
-		option := map[string]interface{}{
-			"id":   id,
-			"name": name,
-		}
-
-		if description.Valid {
-			option["description"] = description.String
-		}
-		if estimatedCost.Valid {
-			option["estimated_cost"] = estimatedCost.Float64
-		}
-
-		options = append(options, option)
-	}
-
-	return options, nil
-}
-
-func (h *EvaluationHandler) getEvaluationStats(decisionID uuid.UUID) (map[string]interface{}, error) {
-	var totalEvaluations int
-	var avgConfidence sql.NullFloat64
-
-	err := h.db.QueryRow(`
-		SELECT COUNT(*), AVG(overall_confidence)
-		FROM evaluations
-		WHERE decision_id = $1
-	`, decisionID).Scan(&totalEvaluations, &avgConfidence)
-	if err != nil {
-		return nil, err
-	}
-
-	stats := map[string]interface{}{
-		"total_evaluations": totalEvaluations,
-	}
-
-	if avgConfidence.Valid {
-		stats["average_confidence"] = avgConfidence.Float64
-	}
-
-	return stats, nil
-}
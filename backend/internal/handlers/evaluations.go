@@ -1,31 +1,44 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"log"
 	"math"
 	"net/http"
+	"strconv"
 	"time"
 
+	"choseby-backend/internal/audit"
 	"choseby-backend/internal/auth"
 	"choseby-backend/internal/database"
+	"choseby-backend/internal/evaluation"
 	"choseby-backend/internal/models"
+	"choseby-backend/internal/realtime"
+	"choseby-backend/internal/workflow"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // EvaluationsHandler handles anonymous team evaluation operations
 type EvaluationsHandler struct {
 	db          *database.DB
 	authService *auth.Service
+	hub         *realtime.Hub
 }
 
-func NewEvaluationsHandler(db *database.DB, authService *auth.Service) *EvaluationsHandler {
+func NewEvaluationsHandler(db *database.DB, authService *auth.Service, hub *realtime.Hub) *EvaluationsHandler {
 	return &EvaluationsHandler{
 		db:          db,
 		authService: authService,
+		hub:         hub,
 	}
 }
 
-// SubmitEvaluation handles anonymous evaluation submission
+// SubmitEvaluation saves a member's anonymous scores for a decision.
+// Scores are upserted per option and criterion, so a ballot can be saved partially and completed later;
+// the whole request is applied in one transaction and is rejected once evaluation has closed.
 func (h *EvaluationsHandler) SubmitEvaluation(c *gin.Context) {
 	decisionID := c.Param("id")
 	userID, exists := c.Get("user_id")
@@ -39,59 +52,131 @@ func (h *EvaluationsHandler) SubmitEvaluation(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
 		return
 	}
+	if len(req.Evaluations) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one evaluation is required"})
+		return
+	}
 
-	// Verify user can access this decision
-	var teamID uuid.UUID
-	err := h.db.GetContext(c, &teamID, `
-		SELECT cd.team_id FROM customer_decisions cd
+	// Each option/criterion cell may appear once per request
+	optionIDs := make([]string, 0, len(req.Evaluations))
+	criteriaIDs := make([]string, 0, len(req.Evaluations))
+	seen := make(map[[2]uuid.UUID]bool, len(req.Evaluations))
+	for i, eval := range req.Evaluations {
+		key := [2]uuid.UUID{eval.OptionID, eval.CriteriaID}
+		if seen[key] {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":       "Duplicate evaluation for the same option and criterion",
+				"eval_index":  i,
+				"option_id":   eval.OptionID,
+				"criteria_id": eval.CriteriaID,
+			})
+			return
+		}
+		seen[key] = true
+		optionIDs = append(optionIDs, eval.OptionID.String())
+		criteriaIDs = append(criteriaIDs, eval.CriteriaID.String())
+	}
+
+	tx, err := h.db.BeginTxx(c, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Verify user can access this decision; the share lock holds off a concurrent close until we commit
+	var decision models.CustomerDecision
+	err = tx.GetContext(c, &decision, `
+		SELECT cd.* FROM customer_decisions cd
 		JOIN team_members tm ON cd.team_id = tm.team_id
-		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true AND cd.deleted_at IS NULL
+		FOR SHARE OF cd
 	`, decisionID, userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found or access denied"})
 		return
 	}
 
-	// Check if user has already submitted evaluations for this decision
-	var existingCount int
-	err = h.db.GetContext(c, &existingCount, `
-		SELECT COUNT(*) FROM evaluations WHERE decision_id = $1 AND evaluator_id = $2
-	`, decisionID, userID)
+	state := workflow.StateOf(decision.Status, decision.CurrentPhase)
+	if decision.EvaluationClosedAt != nil || state == workflow.StateResolved || state == workflow.StateCancelled {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     "Evaluation is closed for this decision",
+			"state":     state,
+			"closed_at": decision.EvaluationClosedAt,
+		})
+		return
+	}
+
+	// Every option and criterion must belong to this decision
+	var invalid struct {
+		Options  pq.StringArray `db:"options"`
+		Criteria pq.StringArray `db:"criteria"`
+	}
+	err = tx.GetContext(c, &invalid, `
+		SELECT
+			ARRAY(
+				SELECT DISTINCT id FROM unnest($2::uuid[]) AS id
+				WHERE id NOT IN (SELECT ro.id FROM response_options ro WHERE ro.decision_id = $1)
+			)::text[] AS options,
+			ARRAY(
+				SELECT DISTINCT id FROM unnest($3::uuid[]) AS id
+				WHERE id NOT IN (SELECT dc.id FROM decision_criteria dc WHERE dc.decision_id = $1)
+			)::text[] AS criteria
+	`, decision.ID, pq.Array(optionIDs), pq.Array(criteriaIDs))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check existing evaluations"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate evaluation", "details": err.Error()})
+		return
+	}
+	if len(invalid.Options) > 0 || len(invalid.Criteria) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":            "Options and criteria must belong to this decision",
+			"invalid_options":  invalid.Options,
+			"invalid_criteria": invalid.Criteria,
+		})
 		return
 	}
 
-	// Delete existing evaluations for this user and decision (allow re-evaluation)
-	if existingCount > 0 {
-		_, err = h.db.ExecContext(c, `
-			DELETE FROM evaluations WHERE decision_id = $1 AND evaluator_id = $2
-		`, decisionID, userID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update existing evaluation"})
-			return
-		}
+	// Check if user has already submitted evaluations for this decision, in this round when it runs in Delphi rounds
+	round := evaluation.CurrentRound(&decision)
+	var existingCount int
+	err = tx.GetContext(c, &existingCount, `
+		SELECT COUNT(*) FROM evaluations WHERE decision_id = $1 AND evaluator_id = $2 AND round_number = $3
+	`, decision.ID, userID, round)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check existing evaluations"})
+		return
 	}
 
-	// Insert new evaluations
-	evaluationCount := 0
+	now := time.Now()
+	created, updated := 0, 0
 	for i, eval := range req.Evaluations {
-		evaluation := models.Evaluation{
+		row := models.Evaluation{
 			ID:               uuid.New(),
-			DecisionID:       uuid.MustParse(decisionID),
+			DecisionID:       decision.ID,
 			EvaluatorID:      userID.(uuid.UUID),
 			OptionID:         eval.OptionID,
 			CriteriaID:       eval.CriteriaID,
 			Score:            eval.Score,
 			Confidence:       eval.Confidence,
 			AnonymousComment: eval.Comment,
-			CreatedAt:        time.Now(),
+			CreatedAt:        now,
 		}
 
-		result, err := h.db.NamedExecContext(c, `
-			INSERT INTO evaluations (id, decision_id, evaluator_id, option_id, criteria_id, score, confidence, anonymous_comment, created_at)
-			VALUES (:id, :decision_id, :evaluator_id, :option_id, :criteria_id, :score, :confidence, :anonymous_comment, :created_at)
-		`, evaluation)
+		// xmax is zero only for a freshly inserted row. A Delphi re-score moves the cell into the open round;
+		// cells left alone keep the round they were last scored in.
+		var inserted bool
+		err := tx.QueryRowxContext(c, `
+			INSERT INTO evaluations (id, decision_id, evaluator_id, option_id, criteria_id, score, confidence, anonymous_comment, round_number, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			ON CONFLICT (decision_id, evaluator_id, option_id, criteria_id) DO UPDATE SET
+				score = EXCLUDED.score,
+				confidence = EXCLUDED.confidence,
+				anonymous_comment = EXCLUDED.anonymous_comment,
+				round_number = EXCLUDED.round_number,
+				updated_at = EXCLUDED.created_at
+			RETURNING (xmax = 0) AS inserted
+		`, row.ID, row.DecisionID, row.EvaluatorID, row.OptionID, row.CriteriaID,
+			row.Score, row.Confidence, row.AnonymousComment, round, row.CreatedAt).Scan(&inserted)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error":       "Failed to submit evaluation",
@@ -102,27 +187,90 @@ func (h *EvaluationsHandler) SubmitEvaluation(c *gin.Context) {
 			})
 			return
 		}
-
-		rowsAffected, _ := result.RowsAffected()
-		if rowsAffected == 0 {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":         "Evaluation not inserted",
-				"eval_index":    i,
-				"rows_affected": rowsAffected,
-			})
-			return
+		if inserted {
+			created++
+		} else {
+			updated++
 		}
+	}
+
+	// How much of the option x criterion ballot this member has filled in this round
+	var ballot struct {
+		Scored int `db:"scored"`
+		Total  int `db:"total"`
+	}
+	err = tx.GetContext(c, &ballot, `
+		SELECT
+			(SELECT COUNT(*) FROM evaluations WHERE decision_id = $1 AND evaluator_id = $2 AND round_number = $3) AS scored,
+			(SELECT COUNT(*) FROM response_options WHERE decision_id = $1)
+				* (SELECT COUNT(*) FROM decision_criteria WHERE decision_id = $1) AS total
+	`, decision.ID, userID, round)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check ballot", "details": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit evaluation", "details": err.Error()})
+		return
+	}
 
-		evaluationCount++
+	evaluationCount := created + updated
+
+	// Scores stay out of the audit trail to preserve evaluation anonymity
+	audit.RecordDecision(c, audit.ActionEvaluationSubmit, decision.ID, models.AuditDetails{
+		"evaluations_count": evaluationCount,
+		"resubmission":      existingCount > 0,
+	})
+	publishDecisionEvent(c, h.hub, realtime.EventEvaluationSubmitted, decision.ID, gin.H{
+		"resubmission": existingCount > 0,
+	})
+	if existingCount == 0 {
+		h.publishParticipation(c, decisionID, decision.TeamID, round)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":           "Evaluation submitted successfully",
+		"round":             round,
 		"evaluations_count": evaluationCount,
+		"created":           created,
+		"updated":           updated,
+		"ballot_scored":     ballot.Scored,
+		"ballot_total":      ballot.Total,
+		"ballot_complete":   ballot.Total > 0 && ballot.Scored >= ballot.Total,
+	})
+}
+
+// publishParticipation tells the team how many members have evaluated the decision so far in the round
+func (h *EvaluationsHandler) publishParticipation(c *gin.Context, decisionID string, teamID uuid.UUID, round int) {
+	var participation struct {
+		Evaluators  int `db:"evaluators"`
+		TeamMembers int `db:"team_members"`
+	}
+	err := h.db.GetContext(c, &participation, `
+		SELECT
+			(SELECT COUNT(DISTINCT evaluator_id) FROM evaluations WHERE decision_id = $1 AND round_number = $3) AS evaluators,
+			(SELECT COUNT(*) FROM team_members WHERE team_id = $2 AND is_active = true) AS team_members
+	`, decisionID, teamID, round)
+	if err != nil || participation.TeamMembers == 0 {
+		return
+	}
+
+	decisionUUID := uuid.MustParse(decisionID)
+	h.hub.Publish(teamID, realtime.Event{
+		Type:       realtime.EventParticipationChanged,
+		DecisionID: &decisionUUID,
+		Data: gin.H{
+			"evaluators":         participation.Evaluators,
+			"team_members":       participation.TeamMembers,
+			"participation_rate": float64(participation.Evaluators) / float64(participation.TeamMembers),
+			"round":              round,
+		},
 	})
 }
 
-// GetResults calculates and returns evaluation results with consensus analysis
+// GetResults calculates and returns evaluation results with consensus analysis.
+// Once evaluation has closed the frozen results are returned instead.
 func (h *EvaluationsHandler) GetResults(c *gin.Context) {
 	decisionID := c.Param("id")
 	userID, exists := c.Get("user_id")
@@ -132,146 +280,59 @@ func (h *EvaluationsHandler) GetResults(c *gin.Context) {
 	}
 
 	// Verify user can access this decision
-	var teamID uuid.UUID
-	err := h.db.GetContext(c, &teamID, `
-		SELECT cd.team_id FROM customer_decisions cd
+	var decision models.CustomerDecision
+	err := h.db.GetContext(c, &decision, `
+		SELECT cd.* FROM customer_decisions cd
 		JOIN team_members tm ON cd.team_id = tm.team_id
-		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true AND cd.deleted_at IS NULL
 	`, decisionID, userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found or access denied"})
 		return
 	}
 
-	// Get all team members for participation calculation
-	var totalMembers int
-	err = h.db.GetContext(c, &totalMembers, `
-		SELECT COUNT(*) FROM team_members WHERE team_id = $1 AND is_active = true
-	`, teamID)
-	if err != nil {
-		totalMembers = 1 // fallback
-	}
-
-	// Get evaluators who have completed evaluations
-	var completedEvaluators []string
-	err = h.db.SelectContext(c, &completedEvaluators, `
-		SELECT DISTINCT tm.role
-		FROM evaluations e
-		JOIN team_members tm ON e.evaluator_id = tm.id
-		WHERE e.decision_id = $1
-	`, decisionID)
-	if err != nil {
-		completedEvaluators = []string{}
-	}
-
-	// Get pending evaluators
-	var pendingEvaluators []string
-	err = h.db.SelectContext(c, &pendingEvaluators, `
-		SELECT DISTINCT tm.role
-		FROM team_members tm
-		WHERE tm.team_id = $1 AND tm.is_active = true
-		AND tm.id NOT IN (
-			SELECT DISTINCT evaluator_id
-			FROM evaluations
-			WHERE decision_id = $2
-		)
-	`, teamID, decisionID)
-	if err != nil {
-		pendingEvaluators = []string{}
-	}
-
-	// Calculate option scores with weighted analysis
-	type OptionAnalysis struct {
-		OptionID      uuid.UUID `db:"option_id"`
-		OptionTitle   string    `db:"option_title"`
-		AverageScore  float64   `db:"avg_score"`
-		WeightedScore float64   `db:"weighted_score"`
-		Evaluators    int       `db:"evaluators"`
-		ScoreVariance float64   `db:"score_variance"`
-	}
-
-	var optionAnalyses []OptionAnalysis
-	err = h.db.SelectContext(c, &optionAnalyses, `
-		SELECT
-			ro.id as option_id,
-			ro.title as option_title,
-			COALESCE(AVG(e.score::float), 0) as avg_score,
-			COALESCE(
-				COALESCE(
-					SUM(e.score::float * dc.weight) / NULLIF(SUM(dc.weight), 0),
-					AVG(e.score::float)
-				),
-				0
-			) as weighted_score,
-			COUNT(DISTINCT e.evaluator_id) as evaluators,
-			COALESCE(VARIANCE(e.score::float), 0) as score_variance
-		FROM response_options ro
-		LEFT JOIN evaluations e ON ro.id = e.option_id
-		LEFT JOIN decision_criteria dc ON e.criteria_id = dc.id
-		WHERE ro.decision_id = $1
-		GROUP BY ro.id, ro.title
-		ORDER BY weighted_score DESC
-	`, decisionID)
+	results, err := evaluation.Load(c, h.db, &decision)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate results", "details": err.Error()})
 		return
 	}
 
-	// Convert to response format with consensus analysis
-	var optionScores []models.OptionScore
-	var recommendedOptionID *uuid.UUID
-	highestScore := 0.0
-
-	for _, analysis := range optionAnalyses {
-		consensus, conflictLevel := h.calculateConsensusMetrics(analysis.ScoreVariance)
-
-		optionScore := models.OptionScore{
-			OptionID:      analysis.OptionID,
-			OptionTitle:   analysis.OptionTitle,
-			AverageScore:  analysis.AverageScore,
-			WeightedScore: analysis.WeightedScore,
-			Evaluators:    analysis.Evaluators,
-			Consensus:     consensus,
-			ConflictLevel: conflictLevel,
-		}
-
-		optionScores = append(optionScores, optionScore)
-
-		// Track highest scoring option for recommendation
-		if analysis.WeightedScore > highestScore && analysis.Evaluators > 0 {
-			highestScore = analysis.WeightedScore
-			optionID := analysis.OptionID
-			recommendedOptionID = &optionID
+	var comments []models.AnonymousComment
+	var progress *models.DelphiProgress
+	if decision.EvaluationMode == evaluation.ModeDelphi {
+		if progress, err = evaluation.LoadProgress(c, h.db, &decision); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load Delphi rounds", "details": err.Error()})
+			return
 		}
-	}
-
-	// Calculate overall team consensus
-	participationRate := float64(len(completedEvaluators)) / float64(totalMembers)
-
-	// Calculate team consensus based on score alignment across options
-	teamConsensus := 0.0
-	if len(optionScores) > 0 {
-		totalConsensus := 0.0
-		for _, score := range optionScores {
-			totalConsensus += score.Consensus
+		// While a later round is open members re-score against the round before it, not the re-scoring in progress
+		if decision.EvaluationClosedAt == nil {
+			feedback, feedbackComments, err := evaluation.LoadFeedback(c, h.db, &decision)
+			switch {
+			case err == nil:
+				results, comments = feedback, feedbackComments
+				progress.FeedbackRound = evaluation.CurrentRound(&decision) - 1
+			case !errors.Is(err, evaluation.ErrNoFeedbackRound):
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load Delphi feedback", "details": err.Error()})
+				return
+			}
 		}
-		teamConsensus = totalConsensus / float64(len(optionScores))
 	}
 
-	results := models.EvaluationResults{
-		OptionScores:      optionScores,
-		ParticipationRate: participationRate,
-		CompletedBy:       completedEvaluators,
-		PendingFrom:       pendingEvaluators,
-		RecommendedOption: recommendedOptionID,
-		TeamConsensus:     teamConsensus,
+	if _, err := h.anonymize(c, &decision, results, comments); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate results", "details": err.Error()})
+		return
 	}
+	results.Delphi = progress
 
 	c.JSON(http.StatusOK, results)
 }
 
-// GetEvaluationStatus returns the current evaluation status for a decision
-func (h *EvaluationsHandler) GetEvaluationStatus(c *gin.Context) {
+// maxSensitivitySteps bounds the work a single sensitivity request can ask for
+const maxSensitivitySteps = 1000
+
+// GetSensitivity reports, for each criterion, the weight at which the recommended option would change,
+// and breaks each option's score down by criterion. It is computed from the stored evaluations.
+func (h *EvaluationsHandler) GetSensitivity(c *gin.Context) {
 	decisionID := c.Param("id")
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -279,23 +340,87 @@ func (h *EvaluationsHandler) GetEvaluationStatus(c *gin.Context) {
 		return
 	}
 
+	weights := evaluation.WeightRange{
+		Min:  evaluation.MinCriterionWeight,
+		Max:  evaluation.MaxCriterionWeight,
+		Step: 0.1,
+	}
+	for name, dst := range map[string]*float64{"min_weight": &weights.Min, "max_weight": &weights.Max, "step": &weights.Step} {
+		if value := c.Query(name); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil || math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": name + " must be a finite number"})
+				return
+			}
+			*dst = parsed
+		}
+	}
+	if weights.Min <= 0 || weights.Max < weights.Min || weights.Step <= 0 ||
+		(weights.Max-weights.Min)/weights.Step > maxSensitivitySteps {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     "Invalid weight range",
+			"details":   "need 0 < min_weight <= max_weight and step > 0",
+			"max_steps": maxSensitivitySteps,
+		})
+		return
+	}
+
 	// Verify user can access this decision
-	var teamID uuid.UUID
-	err := h.db.GetContext(c, &teamID, `
-		SELECT cd.team_id FROM customer_decisions cd
+	var decision models.CustomerDecision
+	err := h.db.GetContext(c, &decision, `
+		SELECT cd.* FROM customer_decisions cd
 		JOIN team_members tm ON cd.team_id = tm.team_id
-		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true AND cd.deleted_at IS NULL
 	`, decisionID, userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found or access denied"})
 		return
 	}
 
-	// Check if current user has submitted evaluation
+	privacy, err := evaluation.LoadPrivacy(c, h.db, &decision)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load evaluations", "details": err.Error()})
+		return
+	}
+
+	ballots, err := evaluation.LoadBallots(c, h.db, decision.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load evaluations", "details": err.Error()})
+		return
+	}
+
+	report, err := evaluation.Sensitivity(c, ballots, &decision, weights)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run sensitivity analysis", "details": err.Error()})
+		return
+	}
+	if !privacy.Sensitivity(report, ballots) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":          "Too few evaluators to show a sensitivity analysis without revealing individual scores",
+			"min_evaluators": privacy.MinEvaluators,
+			"evaluators":     privacy.Evaluators,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetEvaluationStatus returns how far the team has got with evaluating a decision,
+// and whether evaluators disagree on any criterion
+func (h *EvaluationsHandler) GetEvaluationStatus(c *gin.Context) {
+	decision, ok := h.loadDecision(c)
+	if !ok {
+		return
+	}
+	userID := c.MustGet("user_id")
+
+	// Check if current user has submitted evaluation in the open round
+	round := evaluation.CurrentRound(decision)
 	var userEvaluated bool
-	err = h.db.GetContext(c, &userEvaluated, `
-		SELECT EXISTS(SELECT 1 FROM evaluations WHERE decision_id = $1 AND evaluator_id = $2)
-	`, decisionID, userID)
+	err := h.db.GetContext(c, &userEvaluated, `
+		SELECT EXISTS(SELECT 1 FROM evaluations WHERE decision_id = $1 AND evaluator_id = $2 AND round_number = $3)
+	`, decision.ID, userID, round)
 	if err != nil {
 		userEvaluated = false
 	}
@@ -306,53 +431,224 @@ func (h *EvaluationsHandler) GetEvaluationStatus(c *gin.Context) {
 
 	err = h.db.GetContext(c, &totalMembers, `
 		SELECT COUNT(*) FROM team_members WHERE team_id = $1 AND is_active = true
-	`, teamID)
-	if err != nil {
+	`, decision.TeamID)
+	if err != nil || totalMembers == 0 {
 		totalMembers = 1
 	}
 
 	err = h.db.GetContext(c, &completedEvaluations, `
-		SELECT COUNT(DISTINCT evaluator_id) FROM evaluations WHERE decision_id = $1
-	`, decisionID)
+		SELECT COUNT(DISTINCT evaluator_id) FROM evaluations WHERE decision_id = $1 AND round_number = $2
+	`, decision.ID, round)
 	if err != nil {
 		completedEvaluations = 0
 	}
 
-	participationRate := float64(completedEvaluations) / float64(totalMembers)
+	ballots, err := evaluation.LoadBallots(c, h.db, decision.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load evaluations", "details": err.Error()})
+		return
+	}
+	privacy, err := evaluation.LoadPrivacy(c, h.db, decision)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load evaluations", "details": err.Error()})
+		return
+	}
+	conflicts := privacy.Conflicts(evaluation.CriterionConflicts(ballots))
 
+	state := workflow.StateOf(decision.Status, decision.CurrentPhase)
 	status := gin.H{
+		"decision_id":           decision.ID,
+		"state":                 state,
+		"evaluation_open":       state == workflow.StateEvaluation && decision.EvaluationClosedAt == nil,
+		"evaluation_deadline":   decision.EvaluationDeadline,
+		"evaluation_closed_at":  decision.EvaluationClosedAt,
+		"evaluation_mode":       decision.EvaluationMode,
+		"round":                 round,
 		"user_evaluated":        userEvaluated,
 		"total_members":         totalMembers,
 		"completed_evaluations": completedEvaluations,
-		"participation_rate":    participationRate,
+		"participation_rate":    float64(completedEvaluations) / float64(totalMembers),
 		"can_view_results":      completedEvaluations > 0,
+		"conflicts_detected":    len(conflicts) > 0,
+		"conflict_level":        evaluation.HighestConflictLevel(conflicts),
 	}
 
 	c.JSON(http.StatusOK, status)
 }
 
-// ExportEvaluations exports evaluation data (placeholder for future implementation)
+// GetEvaluationSummary returns the anonymous results together with the criteria evaluators disagree on
+func (h *EvaluationsHandler) GetEvaluationSummary(c *gin.Context) {
+	decision, ok := h.loadDecision(c)
+	if !ok {
+		return
+	}
+
+	results, ballots, err := h.loadResults(c, decision)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get evaluation summary", "details": err.Error()})
+		return
+	}
+	privacy, err := h.anonymize(c, decision, results, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get evaluation summary", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"decision_id":       decision.ID,
+		"results":           results,
+		"conflicts":         privacy.Conflicts(evaluation.CriterionConflicts(ballots)),
+		"statistics":        evaluationStatistics(ballots),
+		"summary_generated": "anonymous aggregation only",
+	})
+}
+
+// ExportEvaluations downloads a decision's evaluations as ?format=csv, xlsx or json (the default).
+// Scores are anonymous; ?identified=true names the evaluators and needs the evaluation.export_identified permission.
 func (h *EvaluationsHandler) ExportEvaluations(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Export functionality not implemented yet"})
+	format := c.DefaultQuery("format", evaluation.FormatJSON)
+	contentType, ok := evaluation.ExportFormats()[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported export format", "formats": []string{evaluation.FormatCSV, evaluation.FormatXLSX, evaluation.FormatJSON}})
+		return
+	}
+
+	identified := c.Query("identified") == "true"
+	if identified && !auth.HasPermission(c.GetString("user_role"), auth.PermEvaluationExportIdentified) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":              "Permission denied: " + string(auth.PermEvaluationExportIdentified),
+			"missing_permission": auth.PermEvaluationExportIdentified,
+		})
+		return
+	}
+
+	decision, ok := h.loadDecision(c)
+	if !ok {
+		return
+	}
+
+	results, ballots, err := h.loadResults(c, decision)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate export", "details": err.Error()})
+		return
+	}
+
+	// An identified export names everyone anyway; an anonymous one keeps to the team's threshold
+	var identities map[uuid.UUID]evaluation.EvaluatorIdentity
+	if identified {
+		identities, err = h.evaluatorIdentities(c, decision.ID)
+	} else {
+		_, err = h.anonymize(c, decision, results, nil)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate export", "details": err.Error()})
+		return
+	}
+
+	audit.RecordDecision(c, audit.ActionEvaluationExported, decision.ID, models.AuditDetails{
+		"format":     format,
+		"identified": identified,
+		"options":    len(ballots.Options),
+		"criteria":   len(ballots.Criteria),
+	})
+
+	export := evaluation.NewExport(decision, ballots, results, identities)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="decision-%s-evaluations.%s"`, decision.ID, format))
+	c.Status(http.StatusOK)
+	if err := export.Write(c.Writer, format); err != nil {
+		// Headers are already sent, so all that is left is to cut the download short
+		log.Printf("Evaluation export of decision %s failed: %v", decision.ID, err)
+		_ = c.Error(err)
+	}
 }
 
-// calculateConsensusMetrics determines consensus level and conflict based on score variance
-func (h *EvaluationsHandler) calculateConsensusMetrics(scoreVariance float64) (float64, string) {
-	// Calculate consensus level (inverse of variance, normalized)
-	consensus := 1.0
-	if scoreVariance > 0 {
-		consensus = math.Max(0, 1.0-(scoreVariance/10.0))
+// evaluatorIdentities names everyone who has scored the decision
+func (h *EvaluationsHandler) evaluatorIdentities(c *gin.Context, decisionID uuid.UUID) (map[uuid.UUID]evaluation.EvaluatorIdentity, error) {
+	var rows []struct {
+		ID uuid.UUID `db:"id"`
+		evaluation.EvaluatorIdentity
+	}
+	err := h.db.SelectContext(c, &rows, `
+		SELECT tm.id, tm.name, tm.email, tm.role FROM team_members tm
+		WHERE tm.id IN (SELECT DISTINCT evaluator_id FROM evaluations WHERE decision_id = $1)
+	`, decisionID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Determine conflict level based on variance
-	conflictLevel := models.PriorityNone
-	if scoreVariance > 4.0 {
-		conflictLevel = models.PriorityHigh
-	} else if scoreVariance > 2.0 {
-		conflictLevel = models.PriorityMedium
-	} else if scoreVariance > 1.0 {
-		conflictLevel = models.PriorityLow
+	identities := make(map[uuid.UUID]evaluation.EvaluatorIdentity, len(rows))
+	for _, row := range rows {
+		identities[row.ID] = row.EvaluatorIdentity
 	}
+	return identities, nil
+}
 
-	return consensus, conflictLevel
+// loadDecision fetches a decision the member can access, writing the error response if not
+func (h *EvaluationsHandler) loadDecision(c *gin.Context) (*models.CustomerDecision, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+
+	var decision models.CustomerDecision
+	err := h.db.GetContext(c, &decision, `
+		SELECT cd.* FROM customer_decisions cd
+		JOIN team_members tm ON cd.team_id = tm.team_id
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true AND cd.deleted_at IS NULL
+	`, c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found or access denied"})
+		return nil, false
+	}
+	return &decision, true
+}
+
+// loadResults returns the decision's results, frozen once evaluation has closed, and the ballots behind them
+func (h *EvaluationsHandler) loadResults(c *gin.Context, decision *models.CustomerDecision) (*models.EvaluationResults, *evaluation.Ballots, error) {
+	results, err := evaluation.Load(c, h.db, decision)
+	if err != nil {
+		return nil, nil, err
+	}
+	ballots, err := evaluation.LoadBallots(c, h.db, decision.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return results, ballots, nil
+}
+
+// anonymize applies the team's anonymity threshold to the results and adds the comments it allows.
+// Without comments the decision's current ones are loaded.
+func (h *EvaluationsHandler) anonymize(c *gin.Context, decision *models.CustomerDecision, results *models.EvaluationResults, comments []models.AnonymousComment) (*evaluation.Privacy, error) {
+	privacy, err := evaluation.LoadPrivacy(c, h.db, decision)
+	if err != nil {
+		return nil, err
+	}
+	if comments == nil {
+		if comments, err = evaluation.LoadComments(c, h.db, decision.ID); err != nil {
+			return nil, err
+		}
+	}
+	privacy.Apply(results, comments)
+	return privacy, nil
+}
+
+// evaluationStatistics counts what was submitted without tying anything to an evaluator
+func evaluationStatistics(ballots *evaluation.Ballots) gin.H {
+	evaluators := make(map[uuid.UUID]bool)
+	confidence := 0.0
+	for _, s := range ballots.Scores {
+		evaluators[s.EvaluatorID] = true
+		confidence += float64(s.Confidence)
+	}
+
+	stats := gin.H{
+		"evaluators": len(evaluators),
+		"scores":     len(ballots.Scores),
+	}
+	if len(ballots.Scores) > 0 {
+		stats["average_confidence"] = confidence / float64(len(ballots.Scores))
+	}
+	return stats
 }
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"choseby-backend/internal/testutil"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type EvaluationsHandlerSuite struct {
+	testutil.TestSuite
+	router     *gin.Engine
+	optionID   uuid.UUID
+	criteriaID uuid.UUID
+}
+
+func (s *EvaluationsHandlerSuite) SetupTest() {
+	s.TestSuite.SetupTest()
+	gin.SetMode(gin.TestMode)
+	s.optionID, s.criteriaID = uuid.New(), uuid.New()
+
+	handler := NewEvaluationsHandler(s.DB, s.AuthService, nil)
+	s.router = gin.New()
+	s.router.Use(func(c *gin.Context) {
+		c.Set("user_id", testutil.MockJWTClaims().UserID)
+	})
+	s.router.POST("/decisions/:id/evaluate", handler.SubmitEvaluation)
+	s.router.GET("/decisions/:id/sensitivity", handler.GetSensitivity)
+}
+
+func (s *EvaluationsHandlerSuite) submit(score int) *httptest.ResponseRecorder {
+	body := fmt.Sprintf(`{"evaluations":[{"option_id":%q,"criteria_id":%q,"score":%d,"confidence":4}]}`,
+		s.optionID, s.criteriaID, score)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/decisions/"+testDecisionID+"/evaluate", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+// expectDecision queues the transaction start and the share-locked decision lookup
+func (s *EvaluationsHandlerSuite) expectDecision(status string, phase int, closedAt *time.Time) {
+	s.Mock.ExpectBegin()
+	s.Mock.ExpectQuery("SELECT cd.\\* FROM customer_decisions cd[\\s\\S]*FOR SHARE OF cd").
+		WithArgs(testDecisionID, testutil.MockJWTClaims().UserID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "team_id", "status", "current_phase", "evaluation_closed_at"}).
+			AddRow(testDecisionID, testutil.MockJWTClaims().TeamID, status, phase, closedAt))
+}
+
+func (s *EvaluationsHandlerSuite) TestRejectsOptionFromAnotherDecision() {
+	s.expectDecision("evaluating", 4, nil)
+	s.Mock.ExpectQuery("unnest\\(\\$2::uuid\\[\\]\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"options", "criteria"}).AddRow("{"+s.optionID.String()+"}", "{}"))
+	s.Mock.ExpectRollback()
+
+	w := s.submit(7)
+	s.Equal(http.StatusBadRequest, w.Code)
+
+	var body struct {
+		InvalidOptions []string `json:"invalid_options"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &body))
+	s.Equal([]string{s.optionID.String()}, body.InvalidOptions)
+}
+
+func (s *EvaluationsHandlerSuite) TestRejectsClosedDecision() {
+	closedAt := time.Date(2025, 10, 24, 12, 0, 0, 0, time.UTC)
+	s.expectDecision("evaluating", 5, &closedAt)
+	s.Mock.ExpectRollback()
+
+	w := s.submit(7)
+	s.Equal(http.StatusConflict, w.Code)
+	s.Contains(w.Body.String(), "Evaluation is closed")
+}
+
+func (s *EvaluationsHandlerSuite) TestResubmissionOverwritesEarlierBallot() {
+	s.expectDecision("evaluating", 4, nil)
+	s.Mock.ExpectQuery("unnest\\(\\$2::uuid\\[\\]\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"options", "criteria"}).AddRow("{}", "{}"))
+	s.Mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM evaluations").
+		WithArgs(testutil.MustParseUUID(testDecisionID), testutil.MockJWTClaims().UserID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	// The cell already exists, so the upsert updates it in place
+	s.Mock.ExpectQuery("INSERT INTO evaluations[\\s\\S]*ON CONFLICT \\(decision_id, evaluator_id, option_id, criteria_id\\) DO UPDATE").
+		WithArgs(sqlmock.AnyArg(), testutil.MustParseUUID(testDecisionID), testutil.MockJWTClaims().UserID,
+			s.optionID, s.criteriaID, 9, 4, nil, 1, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"inserted"}).AddRow(false))
+	s.Mock.ExpectQuery("AS scored").
+		WillReturnRows(sqlmock.NewRows([]string{"scored", "total"}).AddRow(1, 1))
+	s.Mock.ExpectCommit()
+
+	w := s.submit(9)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var body struct {
+		Created        int  `json:"created"`
+		Updated        int  `json:"updated"`
+		BallotComplete bool `json:"ballot_complete"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &body))
+	s.Equal(0, body.Created)
+	s.Equal(1, body.Updated)
+	s.True(body.BallotComplete)
+}
+
+func (s *EvaluationsHandlerSuite) TestSensitivityRejectsNonFiniteRange() {
+	for _, query := range []string{"step=NaN", "min_weight=nan", "max_weight=Inf", "step=-Inf"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/decisions/"+testDecisionID+"/sensitivity?"+query, nil)
+		s.router.ServeHTTP(w, req)
+		s.Equal(http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func (s *EvaluationsHandlerSuite) TestSensitivityRefusedBelowAnonymityThreshold() {
+	s.Mock.ExpectQuery("SELECT cd.\\* FROM customer_decisions cd").
+		WithArgs(testDecisionID, testutil.MockJWTClaims().UserID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "team_id", "status", "current_phase"}).
+			AddRow(testDecisionID, testutil.MockJWTClaims().TeamID, "evaluating", 4))
+	s.Mock.ExpectQuery("SELECT min_anonymous_evaluators FROM teams").
+		WillReturnRows(sqlmock.NewRows([]string{"min_anonymous_evaluators"}).AddRow(3))
+	s.Mock.ExpectQuery("SELECT COUNT\\(DISTINCT evaluator_id\\) FROM evaluations").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	s.Mock.ExpectQuery("SELECT role, COUNT\\(\\*\\) AS members FROM team_members").
+		WillReturnRows(sqlmock.NewRows([]string{"role", "members"}).AddRow("support_manager", 4))
+	s.Mock.ExpectQuery("FROM response_options").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(s.optionID, "Full refund"))
+	s.Mock.ExpectQuery("FROM decision_criteria").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "weight"}).AddRow(s.criteriaID, "Cost", 1.0))
+	scores := sqlmock.NewRows([]string{"evaluator_id", "option_id", "criteria_id", "score", "confidence", "evaluator_role", "escalation_authority"})
+	for _, score := range []float64{9, 3} {
+		scores.AddRow(uuid.New(), s.optionID, s.criteriaID, score, 5, "support_manager", 1)
+	}
+	s.Mock.ExpectQuery("FROM evaluations e").WillReturnRows(scores)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/decisions/"+testDecisionID+"/sensitivity", nil)
+	s.router.ServeHTTP(w, req)
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+	s.NotContains(w.Body.String(), "contributions")
+}
+
+func TestEvaluationsHandlerSuite(t *testing.T) {
+	suite.Run(t, new(EvaluationsHandlerSuite))
+}
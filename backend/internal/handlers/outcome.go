@@ -4,9 +4,11 @@ import (
 	"net/http"
 	"time"
 
+	"choseby-backend/internal/audit"
 	"choseby-backend/internal/auth"
 	"choseby-backend/internal/database"
 	"choseby-backend/internal/models"
+	"choseby-backend/internal/realtime"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -15,12 +17,14 @@ import (
 type OutcomeHandler struct {
 	db          *database.DB
 	authService *auth.Service
+	hub         *realtime.Hub
 }
 
-func NewOutcomeHandler(db *database.DB, authService *auth.Service) *OutcomeHandler {
+func NewOutcomeHandler(db *database.DB, authService *auth.Service, hub *realtime.Hub) *OutcomeHandler {
 	return &OutcomeHandler{
 		db:          db,
 		authService: authService,
+		hub:         hub,
 	}
 }
 
@@ -80,7 +84,7 @@ func (h *OutcomeHandler) RecordOutcome(c *gin.Context) {
 	err := h.db.GetContext(c, &decision, `
 		SELECT cd.* FROM customer_decisions cd
 		JOIN team_members tm ON cd.team_id = tm.team_id
-		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true AND cd.deleted_at IS NULL
 	`, decisionID, userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found or access denied"})
@@ -132,6 +136,12 @@ func (h *OutcomeHandler) RecordOutcome(c *gin.Context) {
 			return
 		}
 
+		audit.RecordDecision(c, audit.ActionOutcomeRecorded, decision.ID, models.AuditDetails{
+			"outcome_id": outcomeID,
+			"created":    true,
+		})
+		publishDecisionEvent(c, h.hub, realtime.EventOutcomeRecorded, decision.ID, gin.H{"outcome_id": outcomeID, "created": true})
+
 		c.JSON(http.StatusCreated, gin.H{
 			"message": "Outcome recorded successfully",
 			"data": gin.H{
@@ -181,6 +191,12 @@ func (h *OutcomeHandler) RecordOutcome(c *gin.Context) {
 			return
 		}
 
+		audit.RecordDecision(c, audit.ActionOutcomeRecorded, decision.ID, models.AuditDetails{
+			"outcome_id": existingID,
+			"created":    false,
+		})
+		publishDecisionEvent(c, h.hub, realtime.EventOutcomeRecorded, decision.ID, gin.H{"outcome_id": existingID, "created": false})
+
 		c.JSON(http.StatusOK, gin.H{
 			"message": "Outcome updated successfully",
 			"data": gin.H{
@@ -205,7 +221,7 @@ func (h *OutcomeHandler) GetOutcome(c *gin.Context) {
 	err := h.db.GetContext(c, &teamID, `
 		SELECT cd.team_id FROM customer_decisions cd
 		JOIN team_members tm ON cd.team_id = tm.team_id
-		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true AND cd.deleted_at IS NULL
 	`, decisionID, userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found or access denied"})
@@ -296,7 +312,7 @@ func (h *OutcomeHandler) RecordAIFeedback(c *gin.Context) {
 	err := h.db.GetContext(c, &teamID, `
 		SELECT cd.team_id FROM customer_decisions cd
 		JOIN team_members tm ON cd.team_id = tm.team_id
-		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true AND cd.deleted_at IS NULL
 	`, decisionID, userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found or access denied"})
@@ -344,7 +360,7 @@ func (h *OutcomeHandler) GetAIFeedback(c *gin.Context) {
 	err := h.db.GetContext(c, &teamID, `
 		SELECT cd.team_id FROM customer_decisions cd
 		JOIN team_members tm ON cd.team_id = tm.team_id
-		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true AND cd.deleted_at IS NULL
 	`, decisionID, userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found or access denied"})
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+
+	"choseby-backend/internal/realtime"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// RealtimeHandler upgrades team members to a WebSocket subscribed to their team's events
+type RealtimeHandler struct {
+	hub      *realtime.Hub
+	upgrader websocket.Upgrader
+}
+
+// NewRealtimeHandler creates the WebSocket handler; handshakes are accepted from the CORS origins only
+func NewRealtimeHandler(hub *realtime.Hub, allowedOrigins []string) *RealtimeHandler {
+	origins := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		origins[origin] = true
+	}
+
+	return &RealtimeHandler{
+		hub: hub,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				return origin == "" || origins[origin]
+			},
+		},
+	}
+}
+
+// Connect upgrades the request and streams team events until the client disconnects
+func (h *RealtimeHandler) Connect(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	teamID := c.MustGet("team_id").(uuid.UUID)
+
+	// Refuse over-limit clients before upgrading so they get a normal HTTP error
+	if err := h.hub.CanAccept(userID); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade has already written the HTTP error response
+		return
+	}
+
+	client, err := h.hub.Register(teamID, userID, conn)
+	if err != nil {
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, err.Error()))
+		_ = conn.Close()
+		return
+	}
+
+	client.Send(realtime.Event{Type: realtime.EventConnected, Data: gin.H{"team_id": teamID}})
+	client.Serve()
+}
+
+// publishDecisionEvent pushes a decision event to the requesting member's team
+func publishDecisionEvent(c *gin.Context, hub *realtime.Hub, eventType string, decisionID uuid.UUID, data gin.H) {
+	teamID, ok := c.Get("team_id")
+	if !ok {
+		return
+	}
+	hub.Publish(teamID.(uuid.UUID), realtime.Event{Type: eventType, DecisionID: &decisionID, Data: data})
+}
@@ -1,15 +1,19 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
 
 	"choseby-backend/internal/ai"
+	"choseby-backend/internal/audit"
 	"choseby-backend/internal/auth"
 	"choseby-backend/internal/database"
+	"choseby-backend/internal/evaluation"
 	"choseby-backend/internal/models"
+	"choseby-backend/internal/realtime"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -19,59 +23,174 @@ type ResponseDraftHandler struct {
 	db          *database.DB
 	authService *auth.Service
 	aiService   *ai.Service
+	hub         *realtime.Hub
 }
 
-func NewResponseDraftHandler(db *database.DB, authService *auth.Service, apiKey string) *ResponseDraftHandler {
+func NewResponseDraftHandler(db *database.DB, authService *auth.Service, aiService *ai.Service, hub *realtime.Hub) *ResponseDraftHandler {
 	return &ResponseDraftHandler{
 		db:          db,
 		authService: authService,
-		aiService:   ai.NewAIService(apiKey, db),
+		aiService:   aiService,
+		hub:         hub,
 	}
 }
 
+// draftGeneration holds the validated inputs for generating a response draft
+type draftGeneration struct {
+	decisionID       string
+	userID           uuid.UUID
+	request          models.GenerateResponseDraftRequest
+	evalResults      models.EvaluationResults
+	optionScore      *models.OptionScore
+	selectedOptionID uuid.UUID
+	aiRequest        ai.ResponseDraftRequest
+}
+
 // GenerateResponseDraft generates AI-powered customer response draft
 func (h *ResponseDraftHandler) GenerateResponseDraft(c *gin.Context) {
-	decisionID := c.Param("id")
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+	var req models.GenerateResponseDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		return
+	}
+
+	gen, ok := h.prepareDraftGeneration(c, req)
+	if !ok {
 		return
 	}
 
+	// Generate draft using AI service
+	aiDraft, err := h.aiService.GenerateResponseDraft(c.Request.Context(), gen.aiRequest)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "AI draft generation failed",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	draft, err := h.saveDraft(c.Request.Context(), gen, aiDraft)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to save draft",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.recordDraftGenerated(c, draft)
+
+	c.JSON(http.StatusCreated, draftResponse(draft))
+}
+
+// StreamResponseDraft generates a response draft and streams it to the client as Server-Sent Events.
+// Events: "delta" with each piece of text, then "done" with the saved draft, or "error".
+func (h *ResponseDraftHandler) StreamResponseDraft(c *gin.Context) {
 	var req models.GenerateResponseDraftRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	var err error
+	if c.Request.Method == http.MethodGet {
+		// EventSource can only issue GET requests, so the request is read from the query string
+		err = c.ShouldBindQuery(&req)
+	} else {
+		err = c.ShouldBindJSON(&req)
+	}
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
 		return
 	}
 
+	gen, ok := h.prepareDraftGeneration(c, req)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	ctx := c.Request.Context()
+	aiDraft, err := h.aiService.StreamResponseDraft(ctx, gen.aiRequest, func(delta string) error {
+		c.SSEvent("delta", gin.H{"content": delta})
+		c.Writer.Flush()
+		return ctx.Err()
+	})
+	if err != nil {
+		c.SSEvent("error", gin.H{"error": "AI draft generation failed", "details": err.Error()})
+		c.Writer.Flush()
+		return
+	}
+
+	// Keep the completed draft even if the client disconnected while it was being saved
+	draft, err := h.saveDraft(context.WithoutCancel(ctx), gen, aiDraft)
+	if err != nil {
+		c.SSEvent("error", gin.H{"error": "Failed to save draft", "details": err.Error()})
+		c.Writer.Flush()
+		return
+	}
+
+	h.recordDraftGenerated(c, draft)
+
+	c.SSEvent("done", draftResponse(draft))
+	c.Writer.Flush()
+}
+
+// recordDraftGenerated audits a saved draft and notifies the team; the content itself is not included
+func (h *ResponseDraftHandler) recordDraftGenerated(c *gin.Context, draft *models.ResponseDraft) {
+	audit.RecordDecision(c, audit.ActionDraftGenerated, draft.DecisionID, models.AuditDetails{
+		"draft_id":           draft.ID,
+		"version":            draft.Version,
+		"tone":               draft.Tone,
+		"based_on_option_id": draft.BasedOnOptionID,
+	})
+	publishDecisionEvent(c, h.hub, realtime.EventDraftGenerated, draft.DecisionID, gin.H{
+		"draft_id": draft.ID,
+		"version":  draft.Version,
+	})
+}
+
+// prepareDraftGeneration verifies access, evaluation data and the selected option,
+// writing the error response itself when validation fails
+func (h *ResponseDraftHandler) prepareDraftGeneration(c *gin.Context, req models.GenerateResponseDraftRequest) (*draftGeneration, bool) {
+	decisionID := c.Param("id")
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+
 	// Verify user can access this decision
 	var decision models.CustomerDecision
 	err := h.db.GetContext(c, &decision, `
 		SELECT cd.* FROM customer_decisions cd
 		JOIN team_members tm ON cd.team_id = tm.team_id
-		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true AND cd.deleted_at IS NULL
 	`, decisionID, userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found or access denied"})
-		return
+		return nil, false
 	}
 
-	// Get evaluation results to extract team consensus and recommended option
-	var evalResults models.EvaluationResults
-	err = h.getEvaluationResults(c, decisionID, decision.TeamID, &evalResults)
+	// Get evaluation results (frozen once evaluation closed) to extract team consensus and recommended option
+	evalResults, err := evaluation.Load(c, h.db, &decision)
+	if err == nil && len(evalResults.OptionScores) == 0 {
+		err = fmt.Errorf("no evaluation data found")
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Team evaluations required before generating response draft",
 			"details": "Please ensure team members have completed their evaluations",
 		})
-		return
+		return nil, false
 	}
 
 	// Parse selected option ID
 	selectedOptionID, err := uuid.Parse(req.DecisionOutcome.SelectedOptionID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid option ID format"})
-		return
+		return nil, false
 	}
 
 	// Get selected option details
@@ -81,7 +200,7 @@ func (h *ResponseDraftHandler) GenerateResponseDraft(c *gin.Context) {
 	`, selectedOptionID, decisionID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Selected option not found"})
-		return
+		return nil, false
 	}
 
 	// Find option score for consensus and weighted score
@@ -95,55 +214,55 @@ func (h *ResponseDraftHandler) GenerateResponseDraft(c *gin.Context) {
 
 	if optionScore == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "No evaluation data found for selected option"})
-		return
+		return nil, false
 	}
 
+	return &draftGeneration{
+		decisionID:       decisionID,
+		userID:           userID.(uuid.UUID),
+		request:          req,
+		evalResults:      *evalResults,
+		optionScore:      optionScore,
+		selectedOptionID: selectedOptionID,
+		aiRequest: ai.ResponseDraftRequest{
+			DecisionOutcome: ai.DecisionOutcome{
+				SelectedOptionTitle: selectedOption.Title,
+				Reasoning:           req.DecisionOutcome.Reasoning,
+				TeamConsensus:       evalResults.TeamConsensus,
+				WeightedScore:       optionScore.WeightedScore,
+			},
+			CustomerContext: decision,
+			CommunicationPreferences: ai.CommunicationPreferences{
+				Tone:    req.CommunicationPreferences.Tone,
+				Channel: req.CommunicationPreferences.Channel,
+				Urgency: req.CommunicationPreferences.Urgency,
+			},
+			SelectedOption: &selectedOption,
+		},
+	}, true
+}
+
+// saveDraft stores a generated draft as the decision's next version
+func (h *ResponseDraftHandler) saveDraft(ctx context.Context, gen *draftGeneration, aiDraft *ai.ResponseDraft) (*models.ResponseDraft, error) {
 	// Determine next version number
 	var latestVersion int
-	err = h.db.GetContext(c, &latestVersion, `
+	err := h.db.GetContext(ctx, &latestVersion, `
 		SELECT COALESCE(MAX(version), 0) FROM response_drafts WHERE decision_id = $1
-	`, decisionID)
+	`, gen.decisionID)
 	if err != nil {
 		latestVersion = 0
 	}
-	nextVersion := latestVersion + 1
-
-	// Build AI request
-	draftRequest := ai.ResponseDraftRequest{
-		DecisionOutcome: ai.DecisionOutcome{
-			SelectedOptionTitle: selectedOption.Title,
-			Reasoning:           req.DecisionOutcome.Reasoning,
-			TeamConsensus:       evalResults.TeamConsensus,
-			WeightedScore:       optionScore.WeightedScore,
-		},
-		CustomerContext: decision,
-		CommunicationPreferences: ai.CommunicationPreferences{
-			Tone:    req.CommunicationPreferences.Tone,
-			Channel: req.CommunicationPreferences.Channel,
-			Urgency: req.CommunicationPreferences.Urgency,
-		},
-		SelectedOption: &selectedOption,
-	}
-
-	// Generate draft using AI service
-	aiDraft, err := h.aiService.GenerateResponseDraft(c.Request.Context(), draftRequest)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "AI draft generation failed",
-			"details": err.Error(),
-		})
-		return
-	}
 
 	// Build generation metadata
 	metadata := map[string]interface{}{
-		"ai_model":                  "deepseek-chat",
-		"team_consensus":            evalResults.TeamConsensus,
-		"option_weighted_score":     optionScore.WeightedScore,
-		"option_conflict_level":     optionScore.ConflictLevel,
-		"participation_rate":        evalResults.ParticipationRate,
-		"communication_preferences": req.CommunicationPreferences,
-		"regenerated_from_version":  req.RegenerateFromVersion,
+		"ai_model":                  aiDraft.Model,
+		"ai_provider":               aiDraft.Provider,
+		"team_consensus":            gen.evalResults.TeamConsensus,
+		"option_weighted_score":     gen.optionScore.WeightedScore,
+		"option_conflict_level":     gen.optionScore.ConflictLevel,
+		"participation_rate":        gen.evalResults.ParticipationRate,
+		"communication_preferences": gen.request.CommunicationPreferences,
+		"regenerated_from_version":  gen.request.RegenerateFromVersion,
 	}
 	metadataJSON, _ := json.Marshal(metadata)
 	metadataStr := string(metadataJSON)
@@ -151,22 +270,22 @@ func (h *ResponseDraftHandler) GenerateResponseDraft(c *gin.Context) {
 	// Save draft to database
 	draft := models.ResponseDraft{
 		ID:                          uuid.New(),
-		DecisionID:                  uuid.MustParse(decisionID),
+		DecisionID:                  uuid.MustParse(gen.decisionID),
 		DraftContent:                aiDraft.DraftContent,
 		Tone:                        aiDraft.Tone,
 		KeyPoints:                   aiDraft.KeyPoints,
 		EstimatedSatisfactionImpact: &aiDraft.EstimatedSatisfactionImpact,
 		FollowUpRecommendations:     aiDraft.FollowUpRecommendations,
-		Version:                     nextVersion,
-		CreatedBy:                   userID.(uuid.UUID),
+		Version:                     latestVersion + 1,
+		CreatedBy:                   gen.userID,
 		CreatedAt:                   time.Now(),
 		UpdatedAt:                   time.Now(),
 		GenerationMetadata:          &metadataStr,
-		BasedOnOptionID:             &selectedOptionID,
-		TeamConsensusScore:          &evalResults.TeamConsensus,
+		BasedOnOptionID:             &gen.selectedOptionID,
+		TeamConsensusScore:          &gen.evalResults.TeamConsensus,
 	}
 
-	_, err = h.db.NamedExecContext(c, `
+	_, err = h.db.NamedExecContext(ctx, `
 		INSERT INTO response_drafts (
 			id, decision_id, draft_content, tone, key_points,
 			estimated_satisfaction_impact, follow_up_recommendations,
@@ -180,15 +299,15 @@ func (h *ResponseDraftHandler) GenerateResponseDraft(c *gin.Context) {
 		)
 	`, draft)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to save draft",
-			"details": err.Error(),
-		})
-		return
+		return nil, err
 	}
 
-	// Return draft with metadata
-	response := gin.H{
+	return &draft, nil
+}
+
+// draftResponse builds the API representation of a saved draft
+func draftResponse(draft *models.ResponseDraft) gin.H {
+	return gin.H{
 		"id":                            draft.ID,
 		"decision_id":                   draft.DecisionID,
 		"draft_content":                 draft.DraftContent,
@@ -200,8 +319,6 @@ func (h *ResponseDraftHandler) GenerateResponseDraft(c *gin.Context) {
 		"team_consensus_score":          draft.TeamConsensusScore,
 		"created_at":                    draft.CreatedAt,
 	}
-
-	c.JSON(http.StatusCreated, response)
 }
 
 // GetDrafts retrieves all drafts for a decision
@@ -218,7 +335,7 @@ func (h *ResponseDraftHandler) GetDrafts(c *gin.Context) {
 	err := h.db.GetContext(c, &teamID, `
 		SELECT cd.team_id FROM customer_decisions cd
 		JOIN team_members tm ON cd.team_id = tm.team_id
-		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true AND cd.deleted_at IS NULL
 	`, decisionID, userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found or access denied"})
@@ -242,116 +359,3 @@ func (h *ResponseDraftHandler) GetDrafts(c *gin.Context) {
 		"total":  len(drafts),
 	})
 }
-
-// getEvaluationResults is a helper to fetch evaluation results
-func (h *ResponseDraftHandler) getEvaluationResults(c *gin.Context, decisionID string, teamID uuid.UUID, results *models.EvaluationResults) error {
-	// Get all team members for participation calculation
-	var totalMembers int
-	err := h.db.GetContext(c, &totalMembers, `
-		SELECT COUNT(*) FROM team_members WHERE team_id = $1 AND is_active = true
-	`, teamID)
-	if err != nil {
-		return fmt.Errorf("failed to count team members: %w", err)
-	}
-
-	// Calculate option scores with weighted analysis
-	type OptionAnalysis struct {
-		OptionID      uuid.UUID `db:"option_id"`
-		OptionTitle   string    `db:"option_title"`
-		AverageScore  float64   `db:"avg_score"`
-		WeightedScore float64   `db:"weighted_score"`
-		Evaluators    int       `db:"evaluators"`
-		ScoreVariance float64   `db:"score_variance"`
-	}
-
-	var optionAnalyses []OptionAnalysis
-	err = h.db.SelectContext(c, &optionAnalyses, `
-		SELECT
-			ro.id as option_id,
-			ro.title as option_title,
-			COALESCE(AVG(e.score::float), 0) as avg_score,
-			COALESCE(
-				SUM(e.score::float * dc.weight) / NULLIF(SUM(dc.weight), 0),
-				AVG(e.score::float)
-			) as weighted_score,
-			COUNT(DISTINCT e.evaluator_id) as evaluators,
-			COALESCE(VARIANCE(e.score::float), 0) as score_variance
-		FROM response_options ro
-		LEFT JOIN evaluations e ON ro.id = e.option_id
-		LEFT JOIN decision_criteria dc ON e.criteria_id = dc.id
-		WHERE ro.decision_id = $1
-		GROUP BY ro.id, ro.title
-		ORDER BY weighted_score DESC
-	`, decisionID)
-	if err != nil {
-		return fmt.Errorf("failed to calculate option scores: %w", err)
-	}
-
-	if len(optionAnalyses) == 0 {
-		return fmt.Errorf("no evaluation data found")
-	}
-
-	// Convert to OptionScore models
-	var optionScores []models.OptionScore
-	for _, analysis := range optionAnalyses {
-		consensus := 1.0
-		if analysis.ScoreVariance > 0 {
-			consensus = max(0, 1.0-(analysis.ScoreVariance/10.0))
-		}
-
-		conflictLevel := "none"
-		if analysis.ScoreVariance > 4.0 {
-			conflictLevel = "high"
-		} else if analysis.ScoreVariance > 2.0 {
-			conflictLevel = "medium"
-		} else if analysis.ScoreVariance > 1.0 {
-			conflictLevel = "low"
-		}
-
-		optionScores = append(optionScores, models.OptionScore{
-			OptionID:      analysis.OptionID,
-			OptionTitle:   analysis.OptionTitle,
-			AverageScore:  analysis.AverageScore,
-			WeightedScore: analysis.WeightedScore,
-			Evaluators:    analysis.Evaluators,
-			Consensus:     consensus,
-			ConflictLevel: conflictLevel,
-		})
-	}
-
-	// Calculate team consensus
-	teamConsensus := 0.0
-	if len(optionScores) > 0 {
-		totalConsensus := 0.0
-		for _, score := range optionScores {
-			totalConsensus += score.Consensus
-		}
-		teamConsensus = totalConsensus / float64(len(optionScores))
-	}
-
-	// Get completed evaluators count
-	var completedCount int
-	err = h.db.GetContext(c, &completedCount, `
-		SELECT COUNT(DISTINCT evaluator_id)
-		FROM evaluations
-		WHERE decision_id = $1
-	`, decisionID)
-	if err != nil {
-		completedCount = 0
-	}
-
-	participationRate := float64(completedCount) / float64(totalMembers)
-
-	results.OptionScores = optionScores
-	results.TeamConsensus = teamConsensus
-	results.ParticipationRate = participationRate
-
-	return nil
-}
-
-func max(a, b float64) float64 {
-	if a > b {
-		return a
-	}
-	return b
-}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"choseby-backend/internal/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type ResponseDraftHandlerSuite struct {
+	testutil.TestSuite
+	router *gin.Engine
+}
+
+func (s *ResponseDraftHandlerSuite) SetupTest() {
+	s.TestSuite.SetupTest()
+	gin.SetMode(gin.TestMode)
+
+	handler := NewResponseDraftHandler(s.DB, s.AuthService, nil, nil)
+	s.router = gin.New()
+	s.router.Use(func(c *gin.Context) {
+		c.Set("user_id", testutil.MockJWTClaims().UserID)
+	})
+	s.router.GET("/decisions/:id/generate-response-draft/stream", handler.StreamResponseDraft)
+}
+
+func (s *ResponseDraftHandlerSuite) stream(query string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/decisions/550e8400-e29b-41d4-a716-446655440002/generate-response-draft/stream?"+query, nil)
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func (s *ResponseDraftHandlerSuite) TestStreamQueryRequiresReasoning() {
+	w := s.stream("selected_option_id=550e8400-e29b-41d4-a716-446655440004")
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Contains(w.Body.String(), "Reasoning")
+}
+
+func (s *ResponseDraftHandlerSuite) TestStreamQueryRejectsUnknownTone() {
+	w := s.stream("selected_option_id=550e8400-e29b-41d4-a716-446655440004&reasoning=Refund+approved&tone=sarcastic")
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *ResponseDraftHandlerSuite) TestStreamQueryDefaultsPreferences() {
+	// Valid input without tone, channel or urgency gets as far as the decision lookup
+	s.Mock.ExpectQuery("SELECT cd.\\* FROM customer_decisions").WillReturnError(sql.ErrNoRows)
+
+	w := s.stream("selected_option_id=550e8400-e29b-41d4-a716-446655440004&reasoning=Refund+approved")
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func TestResponseDraftHandlerSuite(t *testing.T) {
+	suite.Run(t, new(ResponseDraftHandlerSuite))
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+
+	"choseby-backend/internal/audit"
+	"choseby-backend/internal/auth"
+	"choseby-backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Where stakeholder role weights come from
+const (
+	weightingSourceAI     = "ai"
+	weightingSourceManual = "manual"
+)
+
+// StakeholderWeightingRequest sets how evaluators are weighted in the results.
+// With source "ai" the role weights are seeded from the decision's AI stakeholder recommendations
+// and any role_weights given override them.
+type StakeholderWeightingRequest struct {
+	Source                 string             `json:"source" binding:"required,oneof=ai manual"`
+	RoleWeights            map[string]float64 `json:"role_weights,omitempty"`
+	DefaultWeight          *float64           `json:"default_weight,omitempty"`
+	UseEscalationAuthority bool               `json:"use_escalation_authority"`
+}
+
+// GetStakeholderWeighting returns the decision's stakeholder weighting and the AI-recommended weights
+func (h *DecisionsHandler) GetStakeholderWeighting(c *gin.Context) {
+	decision, ok := h.loadDecisionForWeighting(c)
+	if !ok {
+		return
+	}
+
+	recommended := []models.RecommendedStakeholder{}
+	if decision.AIRecommendations != nil && decision.AIRecommendations.RecommendedStakeholders != nil {
+		recommended = decision.AIRecommendations.RecommendedStakeholders
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"stakeholder_weighting":    decision.StakeholderWeighting,
+		"recommended_stakeholders": recommended,
+	})
+}
+
+// UpdateStakeholderWeighting turns on role-weighted evaluation for a decision, or changes its weights
+func (h *DecisionsHandler) UpdateStakeholderWeighting(c *gin.Context) {
+	var req StakeholderWeightingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		return
+	}
+
+	decision, ok := h.loadDecisionForWeighting(c)
+	if !ok {
+		return
+	}
+	if decision.EvaluationClosedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Evaluation is closed; weights can no longer change the results"})
+		return
+	}
+
+	weighting := models.StakeholderWeighting{
+		Source:                 req.Source,
+		RoleWeights:            map[string]float64{},
+		DefaultWeight:          1,
+		UseEscalationAuthority: req.UseEscalationAuthority,
+	}
+
+	if req.Source == weightingSourceAI {
+		if decision.AIRecommendations == nil || len(decision.AIRecommendations.RecommendedStakeholders) == 0 {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Decision has no AI stakeholder recommendations to seed weights from"})
+			return
+		}
+		// Roles the AI did not recommend count no more than the least recommended one
+		weighting.DefaultWeight = -1
+		for _, stakeholder := range decision.AIRecommendations.RecommendedStakeholders {
+			if !auth.IsValidRole(stakeholder.Role) || math.IsNaN(stakeholder.Weight) {
+				continue
+			}
+			// AI output is untrusted; hold it to the same 0..10 range as manual weights
+			weight := math.Min(math.Max(stakeholder.Weight, 0), 10)
+			weighting.RoleWeights[stakeholder.Role] = weight
+			if weighting.DefaultWeight < 0 || weight < weighting.DefaultWeight {
+				weighting.DefaultWeight = weight
+			}
+		}
+		if weighting.DefaultWeight < 0 {
+			weighting.DefaultWeight = 1
+		}
+	}
+
+	for role, weight := range req.RoleWeights {
+		if !auth.IsValidRole(role) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role in role_weights", "role": role, "valid_roles": auth.ValidRoles()})
+			return
+		}
+		if weight < 0 || weight > 10 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Role weights must be between 0 and 10", "role": role})
+			return
+		}
+		weighting.RoleWeights[role] = weight
+	}
+	if req.DefaultWeight != nil {
+		if *req.DefaultWeight < 0 || *req.DefaultWeight > 10 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "default_weight must be between 0 and 10"})
+			return
+		}
+		weighting.DefaultWeight = *req.DefaultWeight
+	}
+
+	_, err := h.db.ExecContext(c, `
+		UPDATE customer_decisions SET stakeholder_weighting = $1, updated_at = NOW() WHERE id = $2
+	`, weighting, decision.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update stakeholder weighting", "details": err.Error()})
+		return
+	}
+
+	audit.RecordDecision(c, audit.ActionWeightingUpdated, decision.ID, models.AuditDetails{
+		"source":                   weighting.Source,
+		"role_weights":             weighting.RoleWeights,
+		"default_weight":           weighting.DefaultWeight,
+		"use_escalation_authority": weighting.UseEscalationAuthority,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"stakeholder_weighting": weighting})
+}
+
+// DeleteStakeholderWeighting goes back to counting every evaluator equally
+func (h *DecisionsHandler) DeleteStakeholderWeighting(c *gin.Context) {
+	decision, ok := h.loadDecisionForWeighting(c)
+	if !ok {
+		return
+	}
+	if decision.EvaluationClosedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Evaluation is closed; weights can no longer change the results"})
+		return
+	}
+
+	_, err := h.db.ExecContext(c, `
+		UPDATE customer_decisions SET stakeholder_weighting = NULL, updated_at = NOW() WHERE id = $1
+	`, decision.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove stakeholder weighting", "details": err.Error()})
+		return
+	}
+
+	audit.RecordDecision(c, audit.ActionWeightingUpdated, decision.ID, models.AuditDetails{"source": nil})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Stakeholder weighting removed"})
+}
+
+// loadDecisionForWeighting fetches a decision the member can access, writing the error response if not
+func (h *DecisionsHandler) loadDecisionForWeighting(c *gin.Context) (*models.CustomerDecision, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+
+	var decision models.CustomerDecision
+	err := h.db.GetContext(c, &decision, `
+		SELECT cd.* FROM customer_decisions cd
+		JOIN team_members tm ON cd.team_id = tm.team_id
+		WHERE cd.id = $1 AND tm.id = $2 AND tm.is_active = true AND cd.deleted_at IS NULL
+	`, c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Decision not found"})
+		return nil, false
+	}
+	return &decision, true
+}
@@ -1,25 +1,39 @@
 package handlers
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 
+	"choseby-backend/internal/analytics"
+	"choseby-backend/internal/audit"
 	"choseby-backend/internal/auth"
 	"choseby-backend/internal/database"
+	"choseby-backend/internal/models"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // TeamHandler handles team management operations
 type TeamHandler struct {
-	db          *database.DB
-	authService *auth.Service
+	db             *database.DB
+	authService    *auth.Service
+	maxTeamMembers int
 }
 
-func NewTeamHandler(db *database.DB, authService *auth.Service) *TeamHandler {
+// invitationTTL is how long an invitation token can be accepted
+const invitationTTL = 7 * 24 * time.Hour
+
+func NewTeamHandler(db *database.DB, authService *auth.Service, maxTeamMembers int) *TeamHandler {
 	return &TeamHandler{
-		db:          db,
-		authService: authService,
+		db:             db,
+		authService:    authService,
+		maxTeamMembers: maxTeamMembers,
 	}
 }
 
@@ -70,7 +84,7 @@ func (h *TeamHandler) GetMembers(c *gin.Context) {
 	})
 }
 
-// InviteMember invites a new team member (placeholder for email invitation system)
+// InviteMember creates a persisted invitation and returns its one-time token
 func (h *TeamHandler) InviteMember(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -90,25 +104,7 @@ func (h *TeamHandler) InviteMember(c *gin.Context) {
 		return
 	}
 
-	// Validate role
-	validRoles := []string{
-		"customer_success_manager",
-		"support_manager",
-		"account_manager",
-		"sales_manager",
-		"legal_compliance",
-		"operations_manager",
-	}
-
-	roleValid := false
-	for _, role := range validRoles {
-		if req.Role == role {
-			roleValid = true
-			break
-		}
-	}
-
-	if !roleValid {
+	if !auth.IsValidRole(req.Role) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role specified"})
 		return
 	}
@@ -144,25 +140,434 @@ func (h *TeamHandler) InviteMember(c *gin.Context) {
 		return
 	}
 
-	// For now, return a placeholder response
-	// In a real implementation, this would:
-	// 1. Generate an invitation token
-	// 2. Send an email with the invitation link
-	// 3. Store the pending invitation in the database
+	// Existing accounts cannot be invited (emails are unique across teams)
+	var existingAccount int
+	err = h.db.GetContext(c, &existingAccount, `
+		SELECT COUNT(*) FROM team_members WHERE email = $1
+	`, req.Email)
+	if err == nil && existingAccount > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email already registered"})
+		return
+	}
 
-	inviteToken := uuid.New().String()
+	// One open invitation per email; resend rotates its token instead
+	var pendingInvites int
+	err = h.db.GetContext(c, &pendingInvites, `
+		SELECT COUNT(*) FROM team_invitations
+		WHERE team_id = $1 AND LOWER(email) = LOWER($2) AND status = 'pending' AND expires_at > NOW()
+	`, teamID, req.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check invitations", "details": err.Error()})
+		return
+	}
+	if pendingInvites > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Invitation already pending for this email"})
+		return
+	}
+
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate invitation token"})
+		return
+	}
+
+	now := time.Now()
+	invitation := models.TeamInvitation{
+		ID:        uuid.New(),
+		TeamID:    teamID,
+		Email:     req.Email,
+		Name:      req.Name,
+		Role:      req.Role,
+		TokenHash: tokenHash,
+		InvitedBy: userID.(uuid.UUID),
+		Status:    "pending",
+		ExpiresAt: now.Add(invitationTTL),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	tx, err := h.db.BeginTxx(c, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation", "details": err.Error()})
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Expired pending rows would otherwise block the partial unique index on email
+	if err := expireStaleInvitations(c, tx, teamID, req.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation", "details": err.Error()})
+		return
+	}
+
+	_, err = tx.NamedExecContext(c, `
+		INSERT INTO team_invitations (
+			id, team_id, email, name, role, token_hash, invited_by, status,
+			expires_at, resent_count, created_at, updated_at
+		) VALUES (
+			:id, :team_id, :email, :name, :role, :token_hash, :invited_by, :status,
+			:expires_at, :resent_count, :created_at, :updated_at
+		)
+	`, invitation)
+	if isUniqueViolation(err) {
+		// Another invitation for this email was created since the check above
+		c.JSON(http.StatusConflict, gin.H{"error": "Invitation already pending for this email"})
+		return
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation", "details": err.Error()})
+		return
+	}
+
+	audit.Record(c, audit.Entry{
+		Action:  audit.ActionMemberInvited,
+		TeamID:  &teamID,
+		Details: models.AuditDetails{"invitation_id": invitation.ID, "email": invitation.Email, "role": invitation.Role},
+	})
 
 	c.JSON(http.StatusCreated, gin.H{
-		"message":      "Invitation sent successfully",
-		"invite_token": inviteToken,
-		"email":        req.Email,
-		"name":         req.Name,
-		"role":         req.Role,
+		"message":      "Invitation created successfully",
+		"invitation":   invitation,
+		"invite_token": token,
 		"expires_in":   "7 days",
 	})
 }
 
-// GetTeam retrieves team information (placeholder)
+// ListInvitations lists the team's invitations, optionally filtered by ?status=
+func (h *TeamHandler) ListInvitations(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	teamID, err := h.memberTeamID(c, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		return
+	}
+
+	// Pending invitations past their expiry are reported as expired
+	query := `
+		SELECT id, team_id, email, name, role, token_hash, invited_by,
+			   CASE WHEN status = 'pending' AND expires_at <= NOW() THEN 'expired' ELSE status END AS status,
+			   expires_at, accepted_at, accepted_member_id, resent_count, created_at, updated_at
+		FROM team_invitations
+		WHERE team_id = $1
+	`
+	args := []interface{}{teamID}
+	if status := c.Query("status"); status != "" {
+		query = `SELECT * FROM (` + query + `) invitations WHERE status = $2`
+		args = append(args, status)
+	}
+	query += ` ORDER BY created_at DESC`
+
+	invitations := []models.TeamInvitation{}
+	if err := h.db.SelectContext(c, &invitations, query, args...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitations", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"invitations": invitations,
+	})
+}
+
+// ResendInvitation issues a fresh token for a pending or expired invitation and extends its expiry
+func (h *TeamHandler) ResendInvitation(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	invitationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	teamID, err := h.memberTeamID(c, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		return
+	}
+
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate invitation token"})
+		return
+	}
+
+	tx, err := h.db.BeginTxx(c, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resend invitation", "details": err.Error()})
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Reviving an expired invitation must not collide with other stale rows for the same email
+	_, err = tx.ExecContext(c, `
+		UPDATE team_invitations SET status = 'expired', updated_at = NOW()
+		WHERE team_id = $1 AND id <> $2 AND status = 'pending' AND expires_at <= NOW()
+		AND LOWER(email) = (SELECT LOWER(email) FROM team_invitations WHERE id = $2)
+	`, teamID, invitationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resend invitation", "details": err.Error()})
+		return
+	}
+
+	var invitation models.TeamInvitation
+	err = tx.GetContext(c, &invitation, `
+		UPDATE team_invitations
+		SET token_hash = $1, status = 'pending', expires_at = $2,
+			resent_count = resent_count + 1, updated_at = NOW()
+		WHERE id = $3 AND team_id = $4 AND status IN ('pending', 'expired')
+		RETURNING *
+	`, tokenHash, time.Now().Add(invitationTTL), invitationID, teamID)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "No open invitation found"})
+		case isUniqueViolation(err):
+			c.JSON(http.StatusConflict, gin.H{"error": "Another invitation is already pending for this email"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resend invitation", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Invitation resent successfully",
+		"invitation":   invitation,
+		"invite_token": token,
+		"expires_in":   "7 days",
+	})
+}
+
+// RevokeInvitation cancels a pending invitation so its token can no longer be accepted
+func (h *TeamHandler) RevokeInvitation(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	invitationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	teamID, err := h.memberTeamID(c, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		return
+	}
+
+	result, err := h.db.ExecContext(c, `
+		UPDATE team_invitations SET status = 'revoked', updated_at = NOW()
+		WHERE id = $1 AND team_id = $2 AND status IN ('pending', 'expired')
+	`, invitationID, teamID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invitation", "details": err.Error()})
+		return
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No open invitation found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked successfully"})
+}
+
+// AcceptInvitation creates the invitee's team member account. The :id path segment carries the
+// invitation token (not the invitation ID) because the invitee is not authenticated.
+func (h *TeamHandler) AcceptInvitation(c *gin.Context) {
+	var req models.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		return
+	}
+	if len(req.Password) < 8 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password must be at least 8 characters"})
+		return
+	}
+
+	tx, err := h.db.BeginTxx(c, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var invitation models.TeamInvitation
+	err = tx.GetContext(c, &invitation, `
+		SELECT * FROM team_invitations WHERE token_hash = $1 FOR UPDATE
+	`, auth.HashToken(c.Param("id")))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load invitation", "details": err.Error()})
+		}
+		return
+	}
+
+	if invitation.Status != "pending" {
+		c.JSON(http.StatusGone, gin.H{"error": "Invitation is no longer valid", "status": invitation.Status})
+		return
+	}
+	if time.Now().After(invitation.ExpiresAt) {
+		_, _ = tx.ExecContext(c, `UPDATE team_invitations SET status = 'expired', updated_at = NOW() WHERE id = $1`, invitation.ID)
+		_ = tx.Commit()
+		c.JSON(http.StatusGone, gin.H{"error": "Invitation has expired", "status": "expired"})
+		return
+	}
+
+	// Lock the team row so concurrent acceptances cannot both squeeze under the member limit
+	var team models.Team
+	err = tx.GetContext(c, &team, `
+		SELECT id, name, company_name, industry, team_size, subscription_tier, owner_id, created_at, updated_at
+		FROM teams WHERE id = $1 FOR UPDATE
+	`, invitation.TeamID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		return
+	}
+
+	var memberCount int
+	err = tx.GetContext(c, &memberCount, `
+		SELECT COUNT(*) FROM team_members WHERE team_id = $1 AND is_active = true
+	`, team.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count team members", "details": err.Error()})
+		return
+	}
+	if h.maxTeamMembers > 0 && memberCount >= h.maxTeamMembers {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":       "Team has reached its member limit",
+			"max_members": h.maxTeamMembers,
+		})
+		return
+	}
+
+	var existingAccount int
+	err = tx.GetContext(c, &existingAccount, `
+		SELECT COUNT(*) FROM team_members WHERE email = $1
+	`, invitation.Email)
+	if err == nil && existingAccount > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email already registered"})
+		return
+	}
+
+	passwordHash, err := h.authService.HashPassword(req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to secure password"})
+		return
+	}
+
+	name := invitation.Name
+	if req.Name != "" {
+		name = req.Name
+	}
+
+	now := time.Now()
+	member := models.TeamMember{
+		ID:                      uuid.New(),
+		TeamID:                  team.ID,
+		Email:                   invitation.Email,
+		Name:                    name,
+		PasswordHash:            passwordHash,
+		Role:                    invitation.Role,
+		EscalationAuthority:     1,
+		NotificationPreferences: models.NotificationPrefs{Email: true, SMS: false, Push: true},
+		IsActive:                true,
+		CreatedAt:               now,
+		UpdatedAt:               now,
+	}
+
+	_, err = tx.NamedExecContext(c, `
+		INSERT INTO team_members (
+			id, team_id, email, name, password_hash, role, escalation_authority,
+			notification_preferences, is_active, created_at, updated_at
+		) VALUES (
+			:id, :team_id, :email, :name, :password_hash, :role, :escalation_authority,
+			:notification_preferences, :is_active, :created_at, :updated_at
+		)
+	`, member)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create team member", "details": err.Error()})
+		return
+	}
+
+	_, err = tx.ExecContext(c, `
+		UPDATE team_invitations
+		SET status = 'accepted', accepted_at = $1, accepted_member_id = $2, updated_at = $1
+		WHERE id = $3
+	`, now, member.ID, invitation.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation", "details": err.Error()})
+		return
+	}
+
+	team.TeamSize = memberCount + 1
+	_, err = tx.ExecContext(c, `UPDATE teams SET team_size = $1, updated_at = NOW() WHERE id = $2`, team.TeamSize, team.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update team size", "details": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation", "details": err.Error()})
+		return
+	}
+
+	audit.Record(c, audit.Entry{
+		Action:  audit.ActionInvitationAccepted,
+		TeamID:  &team.ID,
+		UserID:  &member.ID,
+		Details: models.AuditDetails{"invitation_id": invitation.ID, "role": member.Role},
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Invitation accepted. Please log in with your new credentials.",
+		"user":    member,
+		"team":    team,
+	})
+}
+
+// expireStaleInvitations marks the email's pending invitations that are past their expiry as expired
+func expireStaleInvitations(ctx context.Context, tx *sqlx.Tx, teamID uuid.UUID, email string) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE team_invitations SET status = 'expired', updated_at = NOW()
+		WHERE team_id = $1 AND LOWER(email) = LOWER($2) AND status = 'pending' AND expires_at <= NOW()
+	`, teamID, email)
+	return err
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// memberTeamID returns the team of an active member
+func (h *TeamHandler) memberTeamID(c *gin.Context, userID interface{}) (uuid.UUID, error) {
+	var teamID uuid.UUID
+	err := h.db.GetContext(c, &teamID, `
+		SELECT team_id FROM team_members
+		WHERE id = $1 AND is_active = true
+	`, userID)
+	return teamID, err
+}
+
+// GetTeam retrieves team information with its current member count
 func (h *TeamHandler) GetTeam(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -172,18 +577,23 @@ func (h *TeamHandler) GetTeam(c *gin.Context) {
 
 	// Get team information
 	type TeamInfo struct {
-		ID               uuid.UUID `json:"id" db:"id"`
-		Name             string    `json:"name" db:"name"`
-		CompanyName      string    `json:"company_name" db:"company_name"`
-		Industry         *string   `json:"industry" db:"industry"`
-		TeamSize         int       `json:"team_size" db:"team_size"`
-		SubscriptionTier string    `json:"subscription_tier" db:"subscription_tier"`
-		CreatedAt        time.Time `json:"created_at" db:"created_at"`
+		ID               uuid.UUID  `json:"id" db:"id"`
+		Name             string     `json:"name" db:"name"`
+		CompanyName      string     `json:"company_name" db:"company_name"`
+		Industry         *string    `json:"industry" db:"industry"`
+		TeamSize         int        `json:"team_size" db:"team_size"`
+		SubscriptionTier string     `json:"subscription_tier" db:"subscription_tier"`
+		OwnerID          *uuid.UUID `json:"owner_id" db:"owner_id"`
+		CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+
+		MinAnonymousEvaluators int    `json:"min_anonymous_evaluators" db:"min_anonymous_evaluators"`
+		ReportFrequency        string `json:"report_frequency" db:"report_frequency"`
 	}
 
 	var team TeamInfo
 	err := h.db.GetContext(c, &team, `
-		SELECT t.id, t.name, t.company_name, t.industry, t.team_size, t.subscription_tier, t.created_at
+		SELECT t.id, t.name, t.company_name, t.industry, t.team_size, t.subscription_tier, t.owner_id, t.created_at,
+			t.min_anonymous_evaluators, t.report_frequency
 		FROM teams t
 		JOIN team_members tm ON t.id = tm.team_id
 		WHERE tm.id = $1 AND tm.is_active = true
@@ -207,9 +617,78 @@ func (h *TeamHandler) GetTeam(c *gin.Context) {
 	c.JSON(http.StatusOK, team)
 }
 
-// UpdateTeam updates team information (placeholder)
+// UpdateTeam renames the team and updates its company details, anonymity threshold and report schedule
 func (h *TeamHandler) UpdateTeam(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Team update not implemented yet"})
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	type UpdateTeamRequest struct {
+		Name        *string `json:"name,omitempty"`
+		CompanyName *string `json:"company_name,omitempty"`
+		Industry    *string `json:"industry,omitempty"`
+
+		// Members who must evaluate a decision before score distributions and comments are shown
+		MinAnonymousEvaluators *int `json:"min_anonymous_evaluators,omitempty"`
+
+		// How often the team's analytics report is emailed: none, weekly or monthly
+		ReportFrequency *string `json:"report_frequency,omitempty"`
+	}
+
+	var req UpdateTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		return
+	}
+	if req.Name == nil && req.CompanyName == nil && req.Industry == nil && req.MinAnonymousEvaluators == nil &&
+		req.ReportFrequency == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No fields to update"})
+		return
+	}
+	if (req.Name != nil && *req.Name == "") || (req.CompanyName != nil && *req.CompanyName == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Team name and company name cannot be empty"})
+		return
+	}
+	if req.MinAnonymousEvaluators != nil && (*req.MinAnonymousEvaluators < 1 || *req.MinAnonymousEvaluators > 20) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "min_anonymous_evaluators must be between 1 and 20"})
+		return
+	}
+	if req.ReportFrequency != nil && *req.ReportFrequency != analytics.FrequencyNone &&
+		*req.ReportFrequency != analytics.FrequencyWeekly && *req.ReportFrequency != analytics.FrequencyMonthly {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "report_frequency must be one of " + strings.Join(analytics.Frequencies(), ", ")})
+		return
+	}
+
+	teamID, err := h.memberTeamID(c, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		return
+	}
+
+	var team models.Team
+	err = h.db.GetContext(c, &team, `
+		UPDATE teams SET
+			name = COALESCE($1, name),
+			company_name = COALESCE($2, company_name),
+			industry = COALESCE($3, industry),
+			min_anonymous_evaluators = COALESCE($4, min_anonymous_evaluators),
+			report_frequency = COALESCE($5, report_frequency),
+			updated_at = NOW()
+		WHERE id = $6
+		RETURNING id, name, company_name, industry, team_size, subscription_tier, owner_id, created_at, updated_at,
+			min_anonymous_evaluators, report_frequency
+	`, req.Name, req.CompanyName, req.Industry, req.MinAnonymousEvaluators, req.ReportFrequency, teamID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update team", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Team updated successfully",
+		"team":    team,
+	})
 }
 
 // CreateTeam creates a new team (placeholder)
@@ -235,10 +714,283 @@ func (h *TeamHandler) AddTeamMember(c *gin.Context) {
 	h.InviteMember(c) // Delegate to InviteMember
 }
 
+// memberUpdate holds the optional changes applied to a team member
+type memberUpdate struct {
+	Role                *string   `json:"role,omitempty"`
+	EscalationAuthority *int      `json:"escalation_authority,omitempty"`
+	ExpertiseAreas      *[]string `json:"expertise_areas,omitempty"`
+	IsActive            *bool     `json:"is_active,omitempty"`
+}
+
+// UpdateTeamMember changes a member's role, escalation authority, expertise areas or active status
 func (h *TeamHandler) UpdateTeamMember(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented yet"})
+	var req memberUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		return
+	}
+	if req.Role == nil && req.EscalationAuthority == nil && req.ExpertiseAreas == nil && req.IsActive == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No fields to update"})
+		return
+	}
+	if req.Role != nil && !auth.IsValidRole(*req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role specified"})
+		return
+	}
+	if req.EscalationAuthority != nil && (*req.EscalationAuthority < 1 || *req.EscalationAuthority > 5) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Escalation authority must be between 1 and 5"})
+		return
+	}
+
+	h.applyMemberUpdate(c, req)
 }
 
+// RemoveTeamMember deactivates a member; their history is kept and they can be reactivated later
 func (h *TeamHandler) RemoveTeamMember(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented yet"})
+	inactive := false
+	h.applyMemberUpdate(c, memberUpdate{IsActive: &inactive})
+}
+
+// applyMemberUpdate updates the member in :id transactionally, refusing changes that would
+// leave the team without an active admin, or deactivate or demote the owner
+func (h *TeamHandler) applyMemberUpdate(c *gin.Context, req memberUpdate) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	memberID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid member ID"})
+		return
+	}
+
+	teamID, err := h.memberTeamID(c, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		return
+	}
+
+	tx, err := h.db.BeginTxx(c, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Lock the team so concurrent changes cannot together remove every admin
+	var ownerID *uuid.UUID
+	if err := tx.GetContext(c, &ownerID, `SELECT owner_id FROM teams WHERE id = $1 FOR UPDATE`, teamID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		return
+	}
+
+	var member models.TeamMember
+	err = tx.GetContext(c, &member, `
+		SELECT id, team_id, email, name, role, expertise_areas, escalation_authority,
+			   notification_preferences, is_active, created_at, updated_at
+		FROM team_members
+		WHERE id = $1 AND team_id = $2
+	`, memberID, teamID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Team member not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load team member", "details": err.Error()})
+		}
+		return
+	}
+
+	newRole := member.Role
+	if req.Role != nil {
+		newRole = *req.Role
+	}
+	newActive := member.IsActive
+	if req.IsActive != nil {
+		newActive = *req.IsActive
+	}
+
+	if ownerID != nil && *ownerID == member.ID {
+		if !newActive {
+			c.JSON(http.StatusConflict, gin.H{"error": "Transfer team ownership before deactivating the owner"})
+			return
+		}
+		// The owner must hold an admin role, as TransferOwnership requires of a new owner
+		if !auth.IsAdminRole(newRole) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Transfer team ownership before changing the owner's role"})
+			return
+		}
+	}
+
+	// Demoting or deactivating an admin must leave at least one other active admin
+	if member.IsActive && auth.IsAdminRole(member.Role) && (!newActive || !auth.IsAdminRole(newRole)) {
+		var otherAdmins int
+		err = tx.GetContext(c, &otherAdmins, `
+			SELECT COUNT(*) FROM team_members
+			WHERE team_id = $1 AND id <> $2 AND is_active = true AND role = ANY($3)
+		`, teamID, member.ID, pq.StringArray(auth.AdminRoles()))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check team admins", "details": err.Error()})
+			return
+		}
+		if otherAdmins == 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Cannot remove the last team admin"})
+			return
+		}
+	}
+
+	// Reactivation counts against the member limit like a new invitation would
+	if newActive && !member.IsActive && h.maxTeamMembers > 0 {
+		var activeMembers int
+		err = tx.GetContext(c, &activeMembers, `
+			SELECT COUNT(*) FROM team_members WHERE team_id = $1 AND is_active = true
+		`, teamID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count team members", "details": err.Error()})
+			return
+		}
+		if activeMembers >= h.maxTeamMembers {
+			c.JSON(http.StatusConflict, gin.H{"error": "Team has reached its member limit", "max_members": h.maxTeamMembers})
+			return
+		}
+	}
+
+	var expertiseAreas interface{}
+	if req.ExpertiseAreas != nil {
+		expertiseAreas = pq.StringArray(*req.ExpertiseAreas)
+	}
+
+	// Role or status changes invalidate the member's existing access tokens, whose claims are now stale
+	invalidateTokens := newRole != member.Role || newActive != member.IsActive
+	err = tx.GetContext(c, &member, `
+		UPDATE team_members SET
+			role = $1,
+			escalation_authority = COALESCE($2, escalation_authority),
+			expertise_areas = COALESCE($3, expertise_areas),
+			is_active = $4,
+			token_generation = CASE WHEN $5 THEN token_generation + 1 ELSE token_generation END,
+			updated_at = NOW()
+		WHERE id = $6
+		RETURNING id, team_id, email, name, role, expertise_areas, escalation_authority,
+				  notification_preferences, is_active, created_at, updated_at
+	`, newRole, req.EscalationAuthority, expertiseAreas, newActive, invalidateTokens, member.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update team member", "details": err.Error()})
+		return
+	}
+
+	if !newActive {
+		_, err = tx.ExecContext(c, `
+			UPDATE auth_tokens SET revoked_at = NOW()
+			WHERE user_id = $1 AND revoked_at IS NULL
+		`, member.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke member sessions", "details": err.Error()})
+			return
+		}
+	}
+
+	_, err = tx.ExecContext(c, `
+		UPDATE teams SET
+			team_size = (SELECT COUNT(*) FROM team_members WHERE team_id = $1 AND is_active = true),
+			updated_at = NOW()
+		WHERE id = $1
+	`, teamID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update team size", "details": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update team member", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Team member updated successfully",
+		"member":  member,
+	})
+}
+
+// TransferOwnership hands team ownership to another active admin; only the current owner may do this
+func (h *TeamHandler) TransferOwnership(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	callerID := userID.(uuid.UUID)
+
+	type TransferRequest struct {
+		MemberID uuid.UUID `json:"member_id" binding:"required"`
+	}
+
+	var req TransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		return
+	}
+
+	teamID, err := h.memberTeamID(c, callerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		return
+	}
+
+	tx, err := h.db.BeginTxx(c, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var ownerID *uuid.UUID
+	if err := tx.GetContext(c, &ownerID, `SELECT owner_id FROM teams WHERE id = $1 FOR UPDATE`, teamID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		return
+	}
+	if ownerID == nil || *ownerID != callerID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the team owner can transfer ownership"})
+		return
+	}
+	if req.MemberID == callerID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You already own this team"})
+		return
+	}
+
+	var newOwnerRole string
+	err = tx.GetContext(c, &newOwnerRole, `
+		SELECT role FROM team_members
+		WHERE id = $1 AND team_id = $2 AND is_active = true
+	`, req.MemberID, teamID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team member not found"})
+		return
+	}
+	if !auth.IsAdminRole(newOwnerRole) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "New owner must hold an admin role"})
+		return
+	}
+
+	var team models.Team
+	err = tx.GetContext(c, &team, `
+		UPDATE teams SET owner_id = $1, updated_at = NOW()
+		WHERE id = $2
+		RETURNING id, name, company_name, industry, team_size, subscription_tier, owner_id, created_at, updated_at
+	`, req.MemberID, teamID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer ownership", "details": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer ownership", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Team ownership transferred successfully",
+		"team":    team,
+	})
 }
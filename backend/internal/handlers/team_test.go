@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"choseby-backend/internal/testutil"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/suite"
+)
+
+type TeamHandlerSuite struct {
+	testutil.TestSuite
+	router *gin.Engine
+}
+
+func (s *TeamHandlerSuite) SetupTest() {
+	s.TestSuite.SetupTest()
+	gin.SetMode(gin.TestMode)
+
+	handler := NewTeamHandler(s.DB, s.AuthService, 50)
+	s.router = gin.New()
+	s.router.Use(func(c *gin.Context) {
+		c.Set("user_id", testutil.MockJWTClaims().UserID)
+	})
+	s.router.POST("/team/invite", handler.InviteMember)
+	s.router.POST("/team/invitations/:id/resend", handler.ResendInvitation)
+	s.router.PUT("/team/members/:id", handler.UpdateTeamMember)
+}
+
+func (s *TeamHandlerSuite) do(method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func (s *TeamHandlerSuite) TestInviteMemberRejectsUnknownRole() {
+	w := s.do(http.MethodPost, "/team/invite", `{"email":"sam@example.com","name":"Sam","role":"intern"}`)
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *TeamHandlerSuite) TestInviteMemberConflictsWithConcurrentInvitation() {
+	teamID := testutil.MustParseUUID("550e8400-e29b-41d4-a716-446655440001")
+	s.Mock.ExpectQuery("SELECT team_id FROM team_members").
+		WillReturnRows(sqlmock.NewRows([]string{"team_id"}).AddRow(teamID))
+	s.Mock.ExpectQuery("SELECT role FROM team_members").
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("support_manager"))
+	s.Mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM team_members WHERE email = \\$1 AND team_id").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	s.Mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM team_members WHERE email = \\$1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	s.Mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM team_invitations").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	s.Mock.ExpectBegin()
+	s.Mock.ExpectExec("UPDATE team_invitations SET status = 'expired'").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.Mock.ExpectExec("INSERT INTO team_invitations").
+		WillReturnError(&pq.Error{Code: "23505"})
+	s.Mock.ExpectRollback()
+
+	w := s.do(http.MethodPost, "/team/invite", `{"email":"sam@example.com","name":"Sam","role":"account_manager"}`)
+	s.Equal(http.StatusConflict, w.Code)
+}
+
+func (s *TeamHandlerSuite) TestResendExpiredInvitationConflictsWithPendingOne() {
+	teamID := testutil.MustParseUUID("550e8400-e29b-41d4-a716-446655440001")
+	invitationID := "550e8400-e29b-41d4-a716-446655440009"
+	s.Mock.ExpectQuery("SELECT team_id FROM team_members").
+		WillReturnRows(sqlmock.NewRows([]string{"team_id"}).AddRow(teamID))
+	s.Mock.ExpectBegin()
+	s.Mock.ExpectExec("UPDATE team_invitations SET status = 'expired'").
+		WithArgs(teamID, testutil.MustParseUUID(invitationID)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.Mock.ExpectQuery("UPDATE team_invitations\\s+SET token_hash").
+		WillReturnError(&pq.Error{Code: "23505"})
+	s.Mock.ExpectRollback()
+
+	w := s.do(http.MethodPost, "/team/invitations/"+invitationID+"/resend", "")
+	s.Equal(http.StatusConflict, w.Code)
+}
+
+func (s *TeamHandlerSuite) TestUpdateMemberRefusesToDemoteOwner() {
+	teamID := testutil.MustParseUUID("550e8400-e29b-41d4-a716-446655440001")
+	ownerID := testutil.MustParseUUID("550e8400-e29b-41d4-a716-446655440010")
+	s.Mock.ExpectQuery("SELECT team_id FROM team_members").
+		WillReturnRows(sqlmock.NewRows([]string{"team_id"}).AddRow(teamID))
+	s.Mock.ExpectBegin()
+	s.Mock.ExpectQuery("SELECT owner_id FROM teams WHERE id = \\$1 FOR UPDATE").
+		WithArgs(teamID).
+		WillReturnRows(sqlmock.NewRows([]string{"owner_id"}).AddRow(ownerID))
+	s.Mock.ExpectQuery("FROM team_members\\s+WHERE id = \\$1 AND team_id = \\$2").
+		WithArgs(ownerID, teamID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "team_id", "email", "name", "role", "is_active"}).
+			AddRow(ownerID, teamID, "owner@example.com", "Olive Owner", "support_manager", true))
+	s.Mock.ExpectRollback()
+
+	w := s.do(http.MethodPut, "/team/members/"+ownerID.String(), `{"role":"account_manager"}`)
+	s.Equal(http.StatusConflict, w.Code)
+	s.Contains(w.Body.String(), "Transfer team ownership before changing the owner's role")
+}
+
+func TestTeamHandlerSuite(t *testing.T) {
+	suite.Run(t, new(TeamHandlerSuite))
+}
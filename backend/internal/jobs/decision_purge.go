@@ -0,0 +1,42 @@
+// Package jobs contains background maintenance jobs started alongside the API server.
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"choseby-backend/internal/database"
+)
+
+// DecisionPurger hard-deletes soft-deleted decisions once their retention window has passed
+type DecisionPurger struct {
+	db        *database.DB
+	retention time.Duration
+}
+
+// NewDecisionPurger creates a purger for decisions deleted more than retention ago
+func NewDecisionPurger(db *database.DB, retention time.Duration) *DecisionPurger {
+	return &DecisionPurger{db: db, retention: retention}
+}
+
+// Purge deletes expired decisions (criteria, options, evaluations and drafts cascade) and returns how many were removed
+func (p *DecisionPurger) Purge(ctx context.Context) (int64, error) {
+	result, err := p.db.ExecContext(ctx, `
+		DELETE FROM customer_decisions
+		WHERE deleted_at IS NOT NULL AND deleted_at <= NOW() - make_interval(secs => $1)
+	`, p.retention.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Run purges once and logs how many decisions went, for RunEvery
+func (p *DecisionPurger) Run(ctx context.Context) error {
+	purged, err := p.Purge(ctx)
+	if purged > 0 {
+		log.Printf("Purged %d deleted decisions past the %s retention window", purged, p.retention)
+	}
+	return err
+}
@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"choseby-backend/internal/database"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecisionPurgerDeletesPastRetention(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db := &database.DB{DB: sqlx.NewDb(mockDB, "sqlmock")}
+	purger := NewDecisionPurger(db, 30*24*time.Hour)
+
+	mock.ExpectExec("DELETE FROM customer_decisions").
+		WithArgs(float64(30 * 24 * 60 * 60)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	purged, err := purger.Purge(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,356 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"choseby-backend/internal/audit"
+	"choseby-backend/internal/database"
+	"choseby-backend/internal/evaluation"
+	"choseby-backend/internal/models"
+	"choseby-backend/internal/realtime"
+	"choseby-backend/internal/workflow"
+	"github.com/google/uuid"
+)
+
+// EvaluationReminder tells a member who has not evaluated yet that the deadline is near
+type EvaluationReminder struct {
+	DecisionID  uuid.UUID                `db:"decision_id"`
+	TeamID      uuid.UUID                `db:"team_id"`
+	Title       string                   `db:"title"`
+	Deadline    time.Time                `db:"deadline"`
+	MemberID    uuid.UUID                `db:"member_id"`
+	Email       string                   `db:"email"`
+	Name        string                   `db:"name"`
+	Preferences models.NotificationPrefs `db:"notification_preferences"`
+}
+
+// ReminderNotifier delivers evaluation reminders (email, push, ...)
+type ReminderNotifier interface {
+	SendEvaluationReminder(ctx context.Context, reminder EvaluationReminder) error
+}
+
+// LogNotifier writes reminders to the server log; used until a real channel is configured
+type LogNotifier struct{}
+
+// SendEvaluationReminder logs the reminder
+func (LogNotifier) SendEvaluationReminder(_ context.Context, reminder EvaluationReminder) error {
+	log.Printf("Evaluation reminder: %s <%s> has not evaluated %q (deadline %s)",
+		reminder.Name, reminder.Email, reminder.Title, reminder.Deadline.Format(time.RFC3339))
+	return nil
+}
+
+// EvaluationSchedulerConfig controls when evaluation closes early and when reminders go out
+type EvaluationSchedulerConfig struct {
+	Quorum         float64       // fraction of active members whose evaluations close evaluation early; 0 disables
+	ReminderBefore time.Duration // remind pending members this long before the deadline; 0 disables
+	RoundDuration  time.Duration // deadline of each further Delphi round
+	Notifier       ReminderNotifier
+}
+
+// EvaluationScheduler closes evaluation on its deadline or quorum, freezes the results,
+// advances the decision to drafting and reminds members who have not evaluated yet.
+// In Delphi mode the deadline or quorum closes a round, and evaluation only closes once the rounds stop.
+type EvaluationScheduler struct {
+	db    *database.DB
+	hub   *realtime.Hub
+	audit *audit.Logger
+	cfg   EvaluationSchedulerConfig
+}
+
+// NewEvaluationScheduler creates the scheduler; hub and auditLogger may be nil
+func NewEvaluationScheduler(db *database.DB, hub *realtime.Hub, auditLogger *audit.Logger, cfg EvaluationSchedulerConfig) *EvaluationScheduler {
+	if cfg.Notifier == nil {
+		cfg.Notifier = LogNotifier{}
+	}
+	if cfg.RoundDuration <= 0 {
+		cfg.RoundDuration = 72 * time.Hour
+	}
+	return &EvaluationScheduler{db: db, hub: hub, audit: auditLogger, cfg: cfg}
+}
+
+// CloseDue closes evaluation on every decision past its deadline or at quorum and returns how many were closed
+func (s *EvaluationScheduler) CloseDue(ctx context.Context) (int, error) {
+	var due []struct {
+		ID     uuid.UUID `db:"id"`
+		Reason string    `db:"reason"`
+	}
+	err := s.db.SelectContext(ctx, &due, `
+		SELECT cd.id,
+			CASE WHEN cd.evaluation_deadline <= NOW() THEN 'deadline' ELSE 'quorum' END AS reason
+		FROM customer_decisions cd
+		WHERE cd.status = 'evaluating' AND cd.current_phase = 4
+		AND cd.evaluation_closed_at IS NULL AND cd.deleted_at IS NULL
+		AND (
+			cd.evaluation_deadline <= NOW()
+			OR ($1 > 0 AND (
+				SELECT COUNT(DISTINCT e.evaluator_id) FROM evaluations e
+				WHERE e.decision_id = cd.id AND e.round_number = cd.delphi_round
+			) >= GREATEST(1, CEIL($1 * (
+				SELECT COUNT(*) FROM team_members tm WHERE tm.team_id = cd.team_id AND tm.is_active = true
+			))))
+		)
+	`, s.cfg.Quorum)
+	if err != nil {
+		return 0, err
+	}
+
+	closed := 0
+	for _, decision := range due {
+		ok, err := s.closeEvaluation(ctx, decision.ID, decision.Reason)
+		if err != nil {
+			log.Printf("Failed to close evaluation for decision %s: %v", decision.ID, err)
+			continue
+		}
+		if ok {
+			closed++
+		}
+	}
+	return closed, nil
+}
+
+// closeEvaluation freezes the results and moves the decision on to drafting when its guards allow.
+// It reports false when another request closed or moved the decision first.
+func (s *EvaluationScheduler) closeEvaluation(ctx context.Context, decisionID uuid.UUID, reason string) (bool, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var decision models.CustomerDecision
+	err = tx.GetContext(ctx, &decision, `
+		SELECT * FROM customer_decisions
+		WHERE id = $1 AND status = 'evaluating' AND current_phase = 4
+		AND evaluation_closed_at IS NULL AND deleted_at IS NULL
+		FOR UPDATE
+	`, decisionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	var results *models.EvaluationResults
+	if decision.EvaluationMode == evaluation.ModeDelphi {
+		round, roundResults, err := evaluation.CloseRound(ctx, tx, &decision, reason, now, now.Add(s.cfg.RoundDuration))
+		if err != nil {
+			return false, err
+		}
+		if round.StopReason == nil {
+			if err := tx.Commit(); err != nil {
+				return false, err
+			}
+			s.roundClosed(ctx, &decision, round)
+			return true, nil
+		}
+		// The last round's results are the final ones, closed for the reason the rounds stopped
+		results, reason = roundResults, *round.StopReason
+	} else if results, err = evaluation.Compute(ctx, tx, &decision); err != nil {
+		return false, err
+	}
+	if err := evaluation.Freeze(ctx, tx, decision.ID, results, reason, now); err != nil {
+		return false, err
+	}
+
+	// The top-ranked option is selected unless the team already picked one
+	selectedOptionID := decision.SelectedOptionID
+	if selectedOptionID == nil {
+		selectedOptionID = results.RecommendedOption
+	}
+
+	facts := workflow.GuardFacts{SelectedOptionID: selectedOptionID}
+	err = tx.GetContext(ctx, &facts, `
+		SELECT
+			(SELECT COUNT(*) FROM decision_criteria WHERE decision_id = $1) AS criteria_count,
+			(SELECT COUNT(*) FROM response_options WHERE decision_id = $1) AS option_count,
+			(SELECT COUNT(*) FROM evaluations WHERE decision_id = $1) AS evaluation_count,
+			(SELECT COUNT(*) FROM response_drafts WHERE decision_id = $1) AS draft_count
+	`, decision.ID)
+	if err != nil {
+		return false, err
+	}
+
+	// Without evaluations there is nothing to draft from, so the decision stays in evaluation, closed
+	advance := workflow.CheckGuards(workflow.StateEvaluation, workflow.StateDrafting, facts) == nil
+	status, phase := decision.Status, decision.CurrentPhase
+	if advance {
+		status, phase = workflow.StatusAndPhase(workflow.StateDrafting)
+	} else {
+		selectedOptionID = decision.SelectedOptionID
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE customer_decisions SET
+			status = $1,
+			current_phase = $2,
+			selected_option_id = $3,
+			evaluation_closed_at = $4,
+			updated_at = $4
+		WHERE id = $5
+	`, status, phase, selectedOptionID, now, decision.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if advance {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO decision_transitions (id, decision_id, from_state, to_state, actor_id, reason, created_at)
+			VALUES ($1, $2, $3, $4, NULL, $5, $6)
+		`, uuid.New(), decision.ID, string(workflow.StateEvaluation), string(workflow.StateDrafting),
+			"evaluation closed: "+reason, now)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	s.hub.Publish(decision.TeamID, realtime.Event{
+		Type:       realtime.EventEvaluationClosed,
+		DecisionID: &decision.ID,
+		Data: map[string]interface{}{
+			"reason":             reason,
+			"recommended_option": results.RecommendedOption,
+			"participation_rate": results.ParticipationRate,
+		},
+	})
+	if advance {
+		s.hub.Publish(decision.TeamID, realtime.Event{
+			Type:       realtime.EventDecisionStatusChanged,
+			DecisionID: &decision.ID,
+			Data: map[string]interface{}{
+				"from":          workflow.StateEvaluation,
+				"to":            workflow.StateDrafting,
+				"status":        status,
+				"current_phase": phase,
+			},
+		})
+	}
+
+	if s.audit != nil {
+		_, err := s.audit.Record(ctx, audit.Entry{
+			TeamID:     &decision.TeamID,
+			DecisionID: &decision.ID,
+			Action:     audit.ActionEvaluationClosed,
+			Details: models.AuditDetails{
+				"reason":             reason,
+				"advanced":           advance,
+				"selected_option_id": selectedOptionID,
+			},
+		})
+		if err != nil {
+			log.Printf("Failed to audit evaluation close for decision %s: %v", decision.ID, err)
+		}
+	}
+
+	return true, nil
+}
+
+// roundClosed tells the team a Delphi round closed and the next one is open
+func (s *EvaluationScheduler) roundClosed(ctx context.Context, decision *models.CustomerDecision, round *models.DelphiRound) {
+	s.hub.Publish(decision.TeamID, realtime.Event{
+		Type:       realtime.EventDelphiRoundClosed,
+		DecisionID: &decision.ID,
+		Data: map[string]interface{}{
+			"round":          round.RoundNumber,
+			"reason":         round.CloseReason,
+			"team_consensus": round.TeamConsensus,
+			"next_round":     decision.DelphiRound,
+			"deadline":       decision.EvaluationDeadline,
+		},
+	})
+
+	if s.audit != nil {
+		_, err := s.audit.Record(ctx, audit.Entry{
+			TeamID:     &decision.TeamID,
+			DecisionID: &decision.ID,
+			Action:     audit.ActionDelphiRoundClosed,
+			Details: models.AuditDetails{
+				"round":          round.RoundNumber,
+				"reason":         round.CloseReason,
+				"team_consensus": round.TeamConsensus,
+			},
+		})
+		if err != nil {
+			log.Printf("Failed to audit Delphi round close for decision %s: %v", decision.ID, err)
+		}
+	}
+}
+
+// SendReminders notifies members who have not evaluated a decision whose deadline is near, unless they
+// turned email notifications off. Each member is reminded once per deadline; it returns how many reminders were sent.
+func (s *EvaluationScheduler) SendReminders(ctx context.Context) (int, error) {
+	if s.cfg.ReminderBefore <= 0 {
+		return 0, nil
+	}
+
+	var reminders []EvaluationReminder
+	err := s.db.SelectContext(ctx, &reminders, `
+		SELECT cd.id AS decision_id, cd.team_id, cd.title, cd.evaluation_deadline AS deadline,
+			tm.id AS member_id, tm.email, tm.name, tm.notification_preferences
+		FROM customer_decisions cd
+		JOIN team_members tm ON tm.team_id = cd.team_id AND tm.is_active = true
+		WHERE cd.status = 'evaluating' AND cd.current_phase = 4
+		AND cd.evaluation_closed_at IS NULL AND cd.deleted_at IS NULL
+		AND cd.evaluation_deadline > NOW()
+		AND cd.evaluation_deadline <= NOW() + make_interval(secs => $1)
+		AND NOT EXISTS (
+			SELECT 1 FROM evaluations e
+			WHERE e.decision_id = cd.id AND e.evaluator_id = tm.id AND e.round_number = cd.delphi_round
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM evaluation_reminders r
+			WHERE r.decision_id = cd.id AND r.member_id = tm.id AND r.deadline = cd.evaluation_deadline
+		)
+	`, s.cfg.ReminderBefore.Seconds())
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, reminder := range reminders {
+		if !reminder.Preferences.Email {
+			continue
+		}
+		if err := s.cfg.Notifier.SendEvaluationReminder(ctx, reminder); err != nil {
+			log.Printf("Failed to send evaluation reminder to %s: %v", reminder.MemberID, err)
+			continue
+		}
+		_, err := s.db.ExecContext(ctx, `
+			INSERT INTO evaluation_reminders (decision_id, member_id, deadline)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (decision_id, member_id, deadline) DO NOTHING
+		`, reminder.DecisionID, reminder.MemberID, reminder.Deadline)
+		if err != nil {
+			log.Printf("Failed to record evaluation reminder for %s: %v", reminder.MemberID, err)
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// Run closes due evaluations and then sends reminders, for RunEvery. A failed close check
+// does not hold back the reminders.
+func (s *EvaluationScheduler) Run(ctx context.Context) error {
+	closed, closeErr := s.CloseDue(ctx)
+	if closed > 0 {
+		log.Printf("Closed evaluation on %d decisions", closed)
+	}
+	if closeErr != nil {
+		closeErr = fmt.Errorf("close check: %w", closeErr)
+	}
+	_, remindErr := s.SendReminders(ctx)
+	if remindErr != nil {
+		remindErr = fmt.Errorf("reminders: %w", remindErr)
+	}
+	return errors.Join(closeErr, remindErr)
+}
@@ -0,0 +1,188 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"choseby-backend/internal/database"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingNotifier struct {
+	sent []EvaluationReminder
+}
+
+func (n *recordingNotifier) SendEvaluationReminder(_ context.Context, reminder EvaluationReminder) error {
+	n.sent = append(n.sent, reminder)
+	return nil
+}
+
+func TestCloseDueFreezesResultsAndAdvancesToDrafting(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db := &database.DB{DB: sqlx.NewDb(mockDB, "sqlmock")}
+	scheduler := NewEvaluationScheduler(db, nil, nil, EvaluationSchedulerConfig{Quorum: 0.5})
+
+	decisionID, teamID, optionID, criteriaID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectQuery("SELECT cd.id,").
+		WithArgs(0.5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "reason"}).AddRow(decisionID, "quorum"))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT \\* FROM customer_decisions").
+		WithArgs(decisionID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "team_id", "status", "current_phase"}).
+			AddRow(decisionID, teamID, "evaluating", 4))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM team_members").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("SELECT COUNT\\(DISTINCT evaluator_id\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT DISTINCT tm.role\\s+FROM evaluations").
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("csm"))
+	mock.ExpectQuery("SELECT DISTINCT tm.role\\s+FROM team_members").
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("legal_compliance"))
+	mock.ExpectQuery("SELECT id, title FROM response_options").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(optionID, "Refund"))
+	mock.ExpectQuery("FROM decision_criteria").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "weight"}).AddRow(criteriaID, "Cost", 1.0))
+	mock.ExpectQuery("FROM evaluations e\\s+JOIN team_members tm ON tm.id = e.evaluator_id").
+		WillReturnRows(sqlmock.NewRows([]string{"evaluator_id", "option_id", "criteria_id", "score", "confidence"}).
+			AddRow(uuid.New(), optionID, criteriaID, 4, 5))
+	mock.ExpectExec("INSERT INTO evaluation_snapshots").
+		WithArgs(decisionID, sqlmock.AnyArg(), "quorum", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("AS criteria_count").
+		WillReturnRows(sqlmock.NewRows([]string{"criteria_count", "option_count", "evaluation_count", "draft_count"}).
+			AddRow(2, 2, 3, 0))
+	mock.ExpectExec("UPDATE customer_decisions SET").
+		WithArgs("evaluating", 5, &optionID, sqlmock.AnyArg(), decisionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO decision_transitions").
+		WithArgs(sqlmock.AnyArg(), decisionID, "evaluation", "drafting", "evaluation closed: quorum", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	closed, err := scheduler.CloseDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, closed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCloseDueOpensNextDelphiRound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db := &database.DB{DB: sqlx.NewDb(mockDB, "sqlmock")}
+	scheduler := NewEvaluationScheduler(db, nil, nil, EvaluationSchedulerConfig{RoundDuration: 24 * time.Hour})
+
+	decisionID, teamID, optionID, criteriaID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectQuery("SELECT cd.id,").
+		WithArgs(0.0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "reason"}).AddRow(decisionID, "deadline"))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT \\* FROM customer_decisions").
+		WithArgs(decisionID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "team_id", "status", "current_phase",
+			"evaluation_mode", "delphi_round", "delphi_max_rounds", "delphi_consensus_threshold"}).
+			AddRow(decisionID, teamID, "evaluating", 4, "delphi", 1, 3, 0.9))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM team_members").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("SELECT COUNT\\(DISTINCT evaluator_id\\)").
+		WithArgs(decisionID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("SELECT DISTINCT tm.role\\s+FROM evaluations").
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("csm"))
+	mock.ExpectQuery("SELECT DISTINCT tm.role\\s+FROM team_members").
+		WillReturnRows(sqlmock.NewRows([]string{"role"}))
+	mock.ExpectQuery("SELECT id, title FROM response_options").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(optionID, "Refund"))
+	mock.ExpectQuery("FROM decision_criteria").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "weight"}).AddRow(criteriaID, "Cost", 1.0))
+	mock.ExpectQuery("FROM evaluations e\\s+JOIN team_members tm ON tm.id = e.evaluator_id").
+		WillReturnRows(sqlmock.NewRows([]string{"evaluator_id", "option_id", "criteria_id", "score", "confidence"}).
+			AddRow(uuid.New(), optionID, criteriaID, 2, 5).
+			AddRow(uuid.New(), optionID, criteriaID, 9, 5))
+	mock.ExpectQuery("SELECT option_id, criteria_id, anonymous_comment").
+		WillReturnRows(sqlmock.NewRows([]string{"option_id", "criteria_id", "comment"}).AddRow(optionID, criteriaID, "Too generous"))
+	mock.ExpectQuery("SELECT COUNT\\(DISTINCT evaluator_id\\) FROM evaluations WHERE decision_id = \\$1 AND round_number = \\$2").
+		WithArgs(decisionID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectExec("INSERT INTO delphi_rounds").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE customer_decisions SET delphi_round").
+		WithArgs(2, sqlmock.AnyArg(), sqlmock.AnyArg(), decisionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// The round closes but evaluation stays open: no snapshot, no transition
+	closed, err := scheduler.CloseDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, closed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSendRemindersNotifiesOncePerDeadline(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db := &database.DB{DB: sqlx.NewDb(mockDB, "sqlmock")}
+	notifier := &recordingNotifier{}
+	scheduler := NewEvaluationScheduler(db, nil, nil, EvaluationSchedulerConfig{
+		ReminderBefore: 24 * time.Hour,
+		Notifier:       notifier,
+	})
+
+	decisionID, memberID := uuid.New(), uuid.New()
+	deadline := time.Now().Add(6 * time.Hour)
+
+	mock.ExpectQuery("FROM customer_decisions cd\\s+JOIN team_members tm").
+		WithArgs(float64(24 * 60 * 60)).
+		WillReturnRows(sqlmock.NewRows([]string{"decision_id", "team_id", "title", "deadline", "member_id", "email", "name", "notification_preferences"}).
+			AddRow(decisionID, uuid.New(), "Refund request", deadline, memberID, "sam@example.com", "Sam", []byte(`{"email":true}`)))
+	mock.ExpectExec("INSERT INTO evaluation_reminders").
+		WithArgs(decisionID, memberID, deadline).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	sent, err := scheduler.SendReminders(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	require.Len(t, notifier.sent, 1)
+	assert.Equal(t, "sam@example.com", notifier.sent[0].Email)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSendRemindersSkipsMembersWithEmailOff(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db := &database.DB{DB: sqlx.NewDb(mockDB, "sqlmock")}
+	notifier := &recordingNotifier{}
+	scheduler := NewEvaluationScheduler(db, nil, nil, EvaluationSchedulerConfig{
+		ReminderBefore: 24 * time.Hour,
+		Notifier:       notifier,
+	})
+
+	mock.ExpectQuery("FROM customer_decisions cd\\s+JOIN team_members tm").
+		WillReturnRows(sqlmock.NewRows([]string{"decision_id", "team_id", "title", "deadline", "member_id", "email", "name", "notification_preferences"}).
+			AddRow(uuid.New(), uuid.New(), "Refund request", time.Now().Add(6*time.Hour), uuid.New(), "alex@example.com", "Alex", []byte(`{"email":false,"push":true}`)))
+
+	// Nothing is sent, and nothing is recorded as sent
+	sent, err := scheduler.SendReminders(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+	assert.Empty(t, notifier.sent)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
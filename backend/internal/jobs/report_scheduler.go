@@ -0,0 +1,187 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"time"
+
+	"choseby-backend/internal/analytics"
+	"choseby-backend/internal/auth"
+	"choseby-backend/internal/database"
+	"choseby-backend/internal/mailer"
+	"choseby-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// reportClaimTimeout is how long a claimed report is left to the instance sending it before another takes over
+const reportClaimTimeout = 15 * time.Minute
+
+// errReportClaimed means another instance is sending, or has sent, the report
+var errReportClaimed = errors.New("report already claimed")
+
+// ReportScheduler emails teams that asked for one a weekly or monthly analytics report covering the last
+// full period. Reports are stored once generated and each is sent once.
+type ReportScheduler struct {
+	db     *database.DB
+	sender mailer.Sender
+}
+
+// NewReportScheduler creates the scheduler; without a sender reports are only logged
+func NewReportScheduler(db *database.DB, sender mailer.Sender) *ReportScheduler {
+	if sender == nil {
+		sender = mailer.LogSender{}
+	}
+	return &ReportScheduler{db: db, sender: sender}
+}
+
+// SendDue generates and sends every report whose period ended before now and returns how many were sent
+func (s *ReportScheduler) SendDue(ctx context.Context, now time.Time) (int, error) {
+	weekly := analytics.ReportPeriod(analytics.FrequencyWeekly, now)
+	monthly := analytics.ReportPeriod(analytics.FrequencyMonthly, now)
+
+	var due []struct {
+		ID        uuid.UUID `db:"id"`
+		Frequency string    `db:"report_frequency"`
+	}
+	err := s.db.SelectContext(ctx, &due, `
+		SELECT t.id, t.report_frequency
+		FROM teams t
+		WHERE t.report_frequency IN ('weekly', 'monthly')
+		AND NOT EXISTS (
+			SELECT 1 FROM analytics_reports r
+			WHERE r.team_id = t.id AND r.frequency = t.report_frequency AND r.sent_at IS NOT NULL
+			AND r.period_start = CASE t.report_frequency WHEN 'weekly' THEN $1 ELSE $2 END
+		)
+	`, weekly.From, monthly.From)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, team := range due {
+		period := weekly
+		if team.Frequency == analytics.FrequencyMonthly {
+			period = monthly
+		}
+		err := s.sendReport(ctx, team.ID, team.Frequency, period)
+		if errors.Is(err, errReportClaimed) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Failed to send %s analytics report to team %s: %v", team.Frequency, team.ID, err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// sendReport claims the team's report for the period, so that only one instance sends it, then delivers it.
+// A failed send gives the claim back and leaves the report unsent, so the next run retries it.
+func (s *ReportScheduler) sendReport(ctx context.Context, teamID uuid.UUID, frequency string, period analytics.Range) error {
+	// A claim older than reportClaimTimeout was left by an instance that stopped mid-send
+	var reportID uuid.UUID
+	err := s.db.GetContext(ctx, &reportID, `
+		INSERT INTO analytics_reports (team_id, frequency, period_start, period_end, claimed_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (team_id, frequency, period_start) DO UPDATE SET claimed_at = NOW()
+		WHERE analytics_reports.sent_at IS NULL
+		AND (analytics_reports.claimed_at IS NULL OR analytics_reports.claimed_at <= NOW() - make_interval(secs => $5))
+		RETURNING id
+	`, teamID, frequency, period.From, period.To, reportClaimTimeout.Seconds())
+	if errors.Is(err, sql.ErrNoRows) {
+		return errReportClaimed
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.deliverReport(ctx, reportID, teamID, frequency, period); err != nil {
+		if _, releaseErr := s.db.ExecContext(ctx, `UPDATE analytics_reports SET claimed_at = NULL WHERE id = $1`, reportID); releaseErr != nil {
+			log.Printf("Failed to release analytics report %s: %v", reportID, releaseErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// deliverReport stores the claimed report and emails it to active members who can view analytics
+// and have email notifications on
+func (s *ReportScheduler) deliverReport(ctx context.Context, reportID, teamID uuid.UUID, frequency string, period analytics.Range) error {
+	report, err := analytics.Load(ctx, s.db, teamID, period)
+	if err != nil {
+		return err
+	}
+	markdown, err := report.Markdown()
+	if err != nil {
+		return err
+	}
+	html, err := report.HTML()
+	if err != nil {
+		return err
+	}
+
+	var roles []string
+	for role := range auth.RolePermissions() {
+		if auth.HasPermission(role, auth.PermAnalyticsView) {
+			roles = append(roles, role)
+		}
+	}
+	var members []struct {
+		Name        string                   `db:"name"`
+		Email       string                   `db:"email"`
+		Preferences models.NotificationPrefs `db:"notification_preferences"`
+	}
+	err = s.db.SelectContext(ctx, &members, `
+		SELECT name, email, notification_preferences FROM team_members
+		WHERE team_id = $1 AND is_active = true AND role = ANY($2)
+		ORDER BY email
+	`, teamID, pq.Array(roles))
+	if err != nil {
+		return err
+	}
+	recipients := []string{}
+	for _, member := range members {
+		if member.Preferences.Email {
+			recipients = append(recipients, (&mail.Address{Name: member.Name, Address: member.Email}).String())
+		}
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE analytics_reports SET period_end = $2, markdown = $3, html = $4, recipients = $5, generated_at = NOW()
+		WHERE id = $1
+	`, reportID, period.To, markdown, html, len(recipients))
+	if err != nil {
+		return err
+	}
+
+	// A report nobody is subscribed to is still kept, and counts as sent
+	if len(recipients) > 0 {
+		err = s.sender.Send(ctx, mailer.Message{
+			To:      recipients,
+			Subject: fmt.Sprintf("%s %s analytics report: %s", report.TeamName, frequency, period),
+			Text:    markdown,
+			HTML:    html,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = s.db.ExecContext(ctx, `UPDATE analytics_reports SET sent_at = NOW() WHERE id = $1`, reportID)
+	return err
+}
+
+// Run sends the reports due now and logs how many went out, for RunEvery
+func (s *ReportScheduler) Run(ctx context.Context) error {
+	sent, err := s.SendDue(ctx, time.Now())
+	if sent > 0 {
+		log.Printf("Sent %d analytics reports", sent)
+	}
+	return err
+}
@@ -0,0 +1,141 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"choseby-backend/internal/database"
+	"choseby-backend/internal/mailer"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSender struct {
+	sent []mailer.Message
+	err  error
+}
+
+func (s *recordingSender) Send(_ context.Context, msg mailer.Message) error {
+	s.sent = append(s.sent, msg)
+	return s.err
+}
+
+// expectClaim queues the claim on the team's weekly report for the week of 20 October
+func expectClaim(mock sqlmock.Sqlmock, teamID uuid.UUID) *sqlmock.ExpectedQuery {
+	return mock.ExpectQuery("INSERT INTO analytics_reports[\\s\\S]*ON CONFLICT[\\s\\S]*sent_at IS NULL").
+		WithArgs(teamID, "weekly", time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC), time.Date(2025, 10, 27, 0, 0, 0, 0, time.UTC),
+			reportClaimTimeout.Seconds())
+}
+
+// expectReport queues the claim and the queries that build, address and store one team's report
+func expectReport(mock sqlmock.Sqlmock, teamID, reportID uuid.UUID) {
+	expectClaim(mock, teamID).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(reportID))
+	mock.ExpectQuery("SELECT name FROM teams").
+		WithArgs(teamID).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Support"))
+	mock.ExpectQuery("COUNT\\(DISTINCT cd.id\\) AS total_decisions").
+		WillReturnRows(sqlmock.NewRows([]string{"total_decisions", "resolved_decisions", "avg_resolution_hours", "avg_satisfaction", "avg_evaluation_hours"}).
+			AddRow(2, 1, 10.0, 4.0, 6.0))
+	mock.ExpectQuery("AS members").
+		WillReturnRows(sqlmock.NewRows([]string{"members", "evaluated"}).AddRow(2, 3))
+	for i := 0; i < 3; i++ { // decision type, urgency and customer tier breakdowns
+		mock.ExpectQuery("SELECT COALESCE\\(").
+			WillReturnRows(sqlmock.NewRows([]string{"key", "count"}).AddRow("refund_request", 2))
+	}
+	mock.ExpectQuery("FROM team_members tm").
+		WillReturnRows(sqlmock.NewRows([]string{"member_id", "name", "role", "evaluations_count", "decisions_evaluated", "avg_response_time"}).
+			AddRow(uuid.New(), "Sam Lee", "csm", 2, 2, 6.0))
+	mock.ExpectQuery("SELECT name, email, notification_preferences FROM team_members").
+		WithArgs(teamID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "email", "notification_preferences"}).
+			AddRow("Sam Lee", "sam@example.com", []byte(`{"email":true}`)).
+			AddRow("Alex Kim", "alex@example.com", []byte(`{"email":false}`)))
+	mock.ExpectExec("UPDATE analytics_reports SET period_end").
+		WithArgs(reportID, time.Date(2025, 10, 27, 0, 0, 0, 0, time.UTC), sqlmock.AnyArg(), sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestSendDueEmailsLastWeeksReport(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db := &database.DB{DB: sqlx.NewDb(mockDB, "sqlmock")}
+	sender := &recordingSender{}
+	scheduler := NewReportScheduler(db, sender)
+
+	teamID, reportID := uuid.New(), uuid.New()
+	now := time.Date(2025, 10, 30, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("FROM teams t").
+		WithArgs(time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC), time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "report_frequency"}).AddRow(teamID, "weekly"))
+	expectReport(mock, teamID, reportID)
+	mock.ExpectExec("UPDATE analytics_reports SET sent_at").
+		WithArgs(reportID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	sent, err := scheduler.SendDue(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	require.Len(t, sender.sent, 1)
+	msg := sender.sent[0]
+	assert.Equal(t, []string{`"Sam Lee" <sam@example.com>`}, msg.To)
+	assert.Equal(t, "Support weekly analytics report: 2025-10-20 to 2025-10-26", msg.Subject)
+	assert.Contains(t, msg.Text, "| Sam Lee | csm |")
+	assert.Contains(t, msg.HTML, "<td>Sam Lee</td>")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSendDueLeavesFailedReportUnsent(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db := &database.DB{DB: sqlx.NewDb(mockDB, "sqlmock")}
+	sender := &recordingSender{err: errors.New("connection refused")}
+	scheduler := NewReportScheduler(db, sender)
+
+	teamID, reportID := uuid.New(), uuid.New()
+	mock.ExpectQuery("FROM teams t").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "report_frequency"}).AddRow(teamID, "weekly"))
+	expectReport(mock, teamID, reportID)
+	// No sent_at update, and the claim is given back so the next run retries
+	mock.ExpectExec("UPDATE analytics_reports SET claimed_at = NULL").
+		WithArgs(reportID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	sent, err := scheduler.SendDue(context.Background(), time.Date(2025, 10, 30, 9, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Len(t, sender.sent, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSendDueSkipsReportClaimedElsewhere(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db := &database.DB{DB: sqlx.NewDb(mockDB, "sqlmock")}
+	sender := &recordingSender{}
+	scheduler := NewReportScheduler(db, sender)
+
+	teamID := uuid.New()
+	mock.ExpectQuery("FROM teams t").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "report_frequency"}).AddRow(teamID, "weekly"))
+	// Another instance holds the claim, so the upsert's WHERE leaves no row to return
+	expectClaim(mock, teamID).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	sent, err := scheduler.SendDue(context.Background(), time.Date(2025, 10, 30, 9, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Empty(t, sender.sent)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
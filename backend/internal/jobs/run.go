@@ -0,0 +1,26 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunEvery calls fn on the given interval in the background until ctx is cancelled, logging
+// any error it returns under name. Runs never overlap; a slow run delays the next tick.
+func RunEvery(ctx context.Context, interval time.Duration, name string, fn func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := fn(ctx); err != nil {
+					log.Printf("%s failed: %v", name, err)
+				}
+			}
+		}
+	}()
+}
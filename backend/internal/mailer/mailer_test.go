@@ -0,0 +1,123 @@
+package mailer
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpSink is a minimal local SMTP server that keeps what it receives
+type smtpSink struct {
+	listener   net.Listener
+	recipients []string
+	data       chan []byte
+}
+
+func startSMTPSink(t *testing.T) *smtpSink {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	sink := &smtpSink{listener: listener, data: make(chan []byte, 1)}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		_ = tp.PrintfLine("220 sink ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch command {
+			case "EHLO", "HELO":
+				_ = tp.PrintfLine("250-sink")
+				_ = tp.PrintfLine("250 8BITMIME")
+			case "RCPT":
+				sink.recipients = append(sink.recipients, line)
+				_ = tp.PrintfLine("250 OK")
+			case "DATA":
+				_ = tp.PrintfLine("354 Go ahead")
+				body, err := tp.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				sink.data <- body
+				_ = tp.PrintfLine("250 Queued")
+			case "QUIT":
+				_ = tp.PrintfLine("221 Bye")
+				return
+			default:
+				_ = tp.PrintfLine("250 OK")
+			}
+		}
+	}()
+	return sink
+}
+
+func TestSMTPSenderDeliversToSink(t *testing.T) {
+	sink := startSMTPSink(t)
+	addr := sink.listener.Addr().(*net.TCPAddr)
+
+	sender := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: addr.Port, From: "Choseby <reports@choseby.test>"})
+	err := sender.Send(context.Background(), Message{
+		To:      []string{"Sam Lee <sam@example.com>"},
+		Subject: "Weekly report – Support",
+		Text:    "# Support analytics report\n",
+		HTML:    "<h1>Support analytics report</h1>",
+	})
+	require.NoError(t, err)
+
+	var raw []byte
+	select {
+	case raw = <-sink.data:
+	case <-time.After(2 * time.Second):
+		t.Fatal("sink received no message")
+	}
+	assert.Equal(t, []string{"RCPT TO:<sam@example.com>"}, sink.recipients)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Weekly report – Support", subject)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	var types []string
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		types = append(types, part.Header.Get("Content-Type"))
+	}
+	assert.Equal(t, []string{"text/plain; charset=utf-8", "text/html; charset=utf-8"}, types)
+}
+
+func TestMessageBytesRejectsHeaderInjection(t *testing.T) {
+	_, err := Message{To: []string{"sam@example.com\r\nBcc: eve@example.com"}, Text: "hi"}.Bytes("reports@choseby.test", time.Now())
+	assert.Error(t, err)
+
+	_, err = Message{Text: "hi"}.Bytes("reports@choseby.test", time.Now())
+	assert.ErrorIs(t, err, ErrNoRecipients)
+}
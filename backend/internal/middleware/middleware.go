@@ -1,16 +1,63 @@
 package middleware
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 	"time"
 
+	"choseby-backend/internal/audit"
 	"choseby-backend/internal/auth"
+	"choseby-backend/internal/database"
+	"choseby-backend/internal/models"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+// Logger is gin's request logger, except that the access token WebSocketAuth reads from ?token= is redacted
+func Logger() gin.HandlerFunc {
+	return gin.LoggerWithConfig(gin.LoggerConfig{Formatter: func(param gin.LogFormatterParams) string {
+		var statusColor, methodColor, resetColor string
+		if param.IsOutputColor() {
+			statusColor, methodColor, resetColor = param.StatusCodeColor(), param.MethodColor(), param.ResetColor()
+		}
+		if param.Latency > time.Minute {
+			param.Latency = param.Latency.Truncate(time.Second)
+		}
+		return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			statusColor, param.StatusCode, resetColor,
+			param.Latency,
+			param.ClientIP,
+			methodColor, param.Method, resetColor,
+			redactToken(param.Path),
+			param.ErrorMessage,
+		)
+	}})
+}
+
+// redactToken blanks the token query parameter of a request path
+func redactToken(path string) string {
+	base, rawQuery, found := strings.Cut(path, "?")
+	if !found {
+		return path
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		// Unparseable queries are dropped rather than risk logging a token
+		return base
+	}
+	if !query.Has("token") {
+		return path
+	}
+	query.Set("token", "REDACTED")
+	return base + "?" + query.Encode()
+}
+
 // SecurityHeaders adds security headers to all responses
 func SecurityHeaders() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -50,6 +97,39 @@ func RequestID() gin.HandlerFunc {
 	}
 }
 
+// Audit records every mutating request in the audit trail. Handlers record specific actions through
+// audit.Record; requests they did not record (including failed ones) get a generic http.request entry.
+func Audit(logger *audit.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		audit.Attach(c, logger)
+		c.Next()
+
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			return
+		}
+		if c.FullPath() == "" || audit.Recorded(c) {
+			return
+		}
+
+		entry := audit.Entry{
+			Action: audit.ActionRequest,
+			Details: models.AuditDetails{
+				"method": c.Request.Method,
+				"path":   c.FullPath(),
+				"status": c.Writer.Status(),
+			},
+		}
+		if strings.HasPrefix(c.FullPath(), "/api/v1/decisions/:id") {
+			if decisionID, err := uuid.Parse(c.Param("id")); err == nil {
+				entry.DecisionID = &decisionID
+			}
+		}
+		audit.Record(c, entry)
+	}
+}
+
 // RateLimit implements token bucket rate limiting per IP
 func RateLimit(limit, window int) gin.HandlerFunc {
 	type bucket struct {
@@ -101,8 +181,8 @@ func min(a, b int) int {
 	return b
 }
 
-// AuthRequired validates JWT tokens
-func AuthRequired(authService *auth.Service) gin.HandlerFunc {
+// AuthRequired validates JWT tokens and rejects tokens revoked by logout
+func AuthRequired(authService *auth.Service, revocations *auth.RevocationStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -125,6 +205,20 @@ func AuthRequired(authService *auth.Service) gin.HandlerFunc {
 			return
 		}
 
+		if revocations != nil {
+			revoked, err := revocations.IsRevoked(c.Request.Context(), claims)
+			if err != nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Unable to verify token status"})
+				c.Abort()
+				return
+			}
+			if revoked {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+				c.Abort()
+				return
+			}
+		}
+
 		c.Set("claims", claims)
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
@@ -133,17 +227,48 @@ func AuthRequired(authService *auth.Service) gin.HandlerFunc {
 	}
 }
 
-// TeamMember checks if user is a member of the team (simplified for customer response platform)
-func TeamMember() gin.HandlerFunc {
+// TeamMember verifies the user is still an active team member and loads their team and current role.
+// The role from the database replaces the token's role so permission changes apply immediately.
+func TeamMember(db *database.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// In the customer response platform, authenticated users are team members
-		// More sophisticated team verification would be implemented here
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			c.Abort()
+			return
+		}
+
+		var member struct {
+			TeamID uuid.UUID `db:"team_id"`
+			Role   string    `db:"role"`
+		}
+		err := db.GetContext(c.Request.Context(), &member, `
+			SELECT team_id, role FROM team_members
+			WHERE id = $1 AND is_active = true
+		`, userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Active team membership required"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify team membership"})
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set("team_id", member.TeamID)
+		c.Set("user_role", member.Role)
 		c.Next()
 	}
 }
 
 // TeamAdmin checks if user is an admin of the team
 func TeamAdmin() gin.HandlerFunc {
+	return Permission(auth.PermTeamManage)
+}
+
+// Permission checks the user's role grants the required permission; a 403 names the missing permission
+func Permission(required auth.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("user_role")
 		if !exists {
@@ -152,28 +277,22 @@ func TeamAdmin() gin.HandlerFunc {
 			return
 		}
 
-		role := userRole.(string)
-		if role == "customer_success_manager" || role == "operations_manager" {
-			c.Next()
+		if !auth.HasPermission(userRole.(string), required) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":              "Permission denied: " + string(required),
+				"missing_permission": required,
+			})
+			c.Abort()
 			return
 		}
 
-		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
-		c.Abort()
-	}
-}
-
-// Permission checks if user has specific permission (simplified for customer response platform)
-func Permission(required string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// In the customer response platform, we use role-based access
-		// More sophisticated permission checking would be implemented here
 		c.Next()
 	}
 }
 
-// WebSocketAuth validates WebSocket connections
-func WebSocketAuth(authService *auth.Service) gin.HandlerFunc {
+// WebSocketAuth validates WebSocket connections. Browsers cannot set headers on a WebSocket
+// handshake, so the access token is passed as ?token= and checked like AuthRequired does.
+func WebSocketAuth(authService *auth.Service, revocations *auth.RevocationStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := c.Query("token")
 		if token == "" {
@@ -189,7 +308,19 @@ func WebSocketAuth(authService *auth.Service) gin.HandlerFunc {
 			return
 		}
 
+		if revocations != nil {
+			revoked, err := revocations.IsRevoked(c.Request.Context(), claims)
+			if err != nil || revoked {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Set("claims", claims)
 		c.Set("user_id", claims.UserID)
+		c.Set("user_email", claims.Email)
+		c.Set("user_role", claims.Role)
 		c.Next()
 	}
 }
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactToken(t *testing.T) {
+	assert.Equal(t, "/api/v1/ws?token=REDACTED", redactToken("/api/v1/ws?token=eyJhbGciOiJIUzI1NiJ9.e30.sig"))
+	assert.Equal(t, "/api/v1/ws?team=1&token=REDACTED", redactToken("/api/v1/ws?token=secret&team=1"))
+	assert.Equal(t, "/api/v1/decisions?limit=10", redactToken("/api/v1/decisions?limit=10"))
+	assert.Equal(t, "/api/v1/ws", redactToken("/api/v1/ws"))
+	assert.Equal(t, "/api/v1/ws", redactToken("/api/v1/ws?token=secret;%zz"))
+}
+
+func TestLoggerKeepsTokenOutOfAccessLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var out bytes.Buffer
+	previous := gin.DefaultWriter
+	gin.DefaultWriter = &out
+	defer func() { gin.DefaultWriter = previous }()
+
+	router := gin.New()
+	router.Use(Logger())
+	router.GET("/api/v1/ws", func(c *gin.Context) { c.Status(http.StatusUnauthorized) })
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/ws?token=live-access-token", nil))
+
+	assert.Contains(t, out.String(), `"/api/v1/ws?token=REDACTED"`)
+	assert.NotContains(t, out.String(), "live-access-token")
+}
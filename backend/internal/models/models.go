@@ -7,18 +7,26 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // Team represents a customer response team
 type Team struct {
-	ID               uuid.UUID `json:"id" db:"id"`
-	Name             string    `json:"name" db:"name"`
-	CompanyName      string    `json:"company_name" db:"company_name"`
-	Industry         *string   `json:"industry,omitempty" db:"industry"`
-	TeamSize         int       `json:"team_size" db:"team_size"`
-	SubscriptionTier string    `json:"subscription_tier" db:"subscription_tier"`
-	CreatedAt        time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+	ID               uuid.UUID  `json:"id" db:"id"`
+	Name             string     `json:"name" db:"name"`
+	CompanyName      string     `json:"company_name" db:"company_name"`
+	Industry         *string    `json:"industry,omitempty" db:"industry"`
+	TeamSize         int        `json:"team_size" db:"team_size"`
+	SubscriptionTier string     `json:"subscription_tier" db:"subscription_tier"`
+	OwnerID          *uuid.UUID `json:"owner_id,omitempty" db:"owner_id"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Evaluation distributions and comments are withheld until this many members have evaluated
+	MinAnonymousEvaluators int `json:"min_anonymous_evaluators" db:"min_anonymous_evaluators"`
+
+	// Analytics report emailed to the team: none, weekly or monthly
+	ReportFrequency string `json:"report_frequency" db:"report_frequency"`
 }
 
 // TeamMember represents a customer response team member
@@ -29,14 +37,39 @@ type TeamMember struct {
 	Name                    string            `json:"name" db:"name"`
 	PasswordHash            string            `json:"-" db:"password_hash"`
 	Role                    string            `json:"role" db:"role"`
-	ExpertiseAreas          []string          `json:"expertise_areas,omitempty" db:"expertise_areas"`
+	ExpertiseAreas          pq.StringArray    `json:"expertise_areas,omitempty" db:"expertise_areas"`
 	EscalationAuthority     int               `json:"escalation_authority" db:"escalation_authority"`
 	NotificationPreferences NotificationPrefs `json:"notification_preferences" db:"notification_preferences"`
 	IsActive                bool              `json:"is_active" db:"is_active"`
+	TokenGeneration         int               `json:"-" db:"token_generation"`
 	CreatedAt               time.Time         `json:"created_at" db:"created_at"`
 	UpdatedAt               time.Time         `json:"updated_at" db:"updated_at"`
 }
 
+// TeamInvitation represents a pending or resolved invitation to join a team
+type TeamInvitation struct {
+	ID               uuid.UUID  `json:"id" db:"id"`
+	TeamID           uuid.UUID  `json:"team_id" db:"team_id"`
+	Email            string     `json:"email" db:"email"`
+	Name             string     `json:"name" db:"name"`
+	Role             string     `json:"role" db:"role"`
+	TokenHash        string     `json:"-" db:"token_hash"`
+	InvitedBy        uuid.UUID  `json:"invited_by" db:"invited_by"`
+	Status           string     `json:"status" db:"status"` // pending, accepted, revoked, expired
+	ExpiresAt        time.Time  `json:"expires_at" db:"expires_at"`
+	AcceptedAt       *time.Time `json:"accepted_at,omitempty" db:"accepted_at"`
+	AcceptedMemberID *uuid.UUID `json:"accepted_member_id,omitempty" db:"accepted_member_id"`
+	ResentCount      int        `json:"resent_count" db:"resent_count"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// AcceptInvitationRequest represents the invitee's account details when accepting an invitation
+type AcceptInvitationRequest struct {
+	Name     string `json:"name"`
+	Password string `json:"password" binding:"required"`
+}
+
 // NotificationPrefs represents notification preferences
 type NotificationPrefs struct {
 	Email bool `json:"email"`
@@ -98,14 +131,40 @@ type CustomerDecision struct {
 	CurrentPhase           int        `json:"current_phase" db:"current_phase"`
 	ExpectedResolutionDate *time.Time `json:"expected_resolution_date,omitempty" db:"expected_resolution_date"`
 	ActualResolutionDate   *time.Time `json:"actual_resolution_date,omitempty" db:"actual_resolution_date"`
+	SelectedOptionID       *uuid.UUID `json:"selected_option_id,omitempty" db:"selected_option_id"`
+	EvaluationDeadline     *time.Time `json:"evaluation_deadline,omitempty" db:"evaluation_deadline"`
+	EvaluationClosedAt     *time.Time `json:"evaluation_closed_at,omitempty" db:"evaluation_closed_at"`
+	AggregationMethod      string     `json:"aggregation_method" db:"aggregation_method"`
+	ConsensusMethod        string     `json:"consensus_method" db:"consensus_method"`
+
+	// Delphi mode runs evaluation in rounds until consensus reaches the threshold or the rounds run out
+	EvaluationMode           string  `json:"evaluation_mode" db:"evaluation_mode"`
+	DelphiRound              int     `json:"delphi_round" db:"delphi_round"`
+	DelphiMaxRounds          int     `json:"delphi_max_rounds" db:"delphi_max_rounds"`
+	DelphiConsensusThreshold float64 `json:"delphi_consensus_threshold" db:"delphi_consensus_threshold"`
+
+	StakeholderWeighting *StakeholderWeighting `json:"stakeholder_weighting,omitempty" db:"stakeholder_weighting"`
 
 	// AI Analysis
 	AIClassification  *AIClassification  `json:"ai_classification,omitempty" db:"ai_classification"`
 	AIRecommendations *AIRecommendations `json:"ai_recommendations,omitempty" db:"ai_recommendations"`
 	AIConfidenceScore *float64           `json:"ai_confidence_score,omitempty" db:"ai_confidence_score"`
 
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	DeletedBy *uuid.UUID `json:"deleted_by,omitempty" db:"deleted_by"`
+}
+
+// DecisionTransition records one move through the decision lifecycle
+type DecisionTransition struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	DecisionID uuid.UUID  `json:"decision_id" db:"decision_id"`
+	FromState  string     `json:"from_state" db:"from_state"`
+	ToState    string     `json:"to_state" db:"to_state"`
+	ActorID    *uuid.UUID `json:"actor_id,omitempty" db:"actor_id"` // nil for automatic transitions
+	Reason     *string    `json:"reason,omitempty" db:"reason"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
 }
 
 // AIClassification represents AI analysis of customer issue
@@ -114,6 +173,7 @@ type AIClassification struct {
 	UrgencyLevel    int      `json:"urgency_level"`
 	ConfidenceScore float64  `json:"confidence_score"`
 	RiskFactors     []string `json:"risk_factors"`
+	Provider        string   `json:"provider,omitempty"`
 }
 
 // Value implements driver.Valuer interface
@@ -172,6 +232,45 @@ func (a *AIRecommendations) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, a)
 }
 
+// StakeholderWeighting weights each evaluator's scores by role and, optionally, escalation authority
+type StakeholderWeighting struct {
+	Source                 string             `json:"source"` // "ai" (seeded from RecommendedStakeholders) or "manual"
+	RoleWeights            map[string]float64 `json:"role_weights"`
+	DefaultWeight          float64            `json:"default_weight"` // for roles not in RoleWeights
+	UseEscalationAuthority bool               `json:"use_escalation_authority"`
+}
+
+// Value implements driver.Valuer interface for database storage
+func (w StakeholderWeighting) Value() (driver.Value, error) {
+	return json.Marshal(w)
+}
+
+// Scan implements sql.Scanner interface
+func (w *StakeholderWeighting) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into StakeholderWeighting", value)
+	}
+
+	return json.Unmarshal(bytes, w)
+}
+
+// WeightFor returns the weight of an evaluator with the given role and escalation authority
+func (w *StakeholderWeighting) WeightFor(role string, escalationAuthority int) float64 {
+	weight, ok := w.RoleWeights[role]
+	if !ok {
+		weight = w.DefaultWeight
+	}
+	if w.UseEscalationAuthority {
+		weight *= float64(max(escalationAuthority, 1))
+	}
+	return weight
+}
+
 // DecisionCriterion represents evaluation criteria
 type DecisionCriterion struct {
 	ID          uuid.UUID `json:"id" db:"id"`
@@ -199,15 +298,16 @@ type ResponseOption struct {
 
 // Evaluation represents team member evaluation
 type Evaluation struct {
-	ID               uuid.UUID `json:"id" db:"id"`
-	DecisionID       uuid.UUID `json:"decision_id" db:"decision_id"`
-	EvaluatorID      uuid.UUID `json:"evaluator_id" db:"evaluator_id"`
-	OptionID         uuid.UUID `json:"option_id" db:"option_id"`
-	CriteriaID       uuid.UUID `json:"criteria_id" db:"criteria_id"`
-	Score            int       `json:"score" db:"score"`
-	Confidence       int       `json:"confidence" db:"confidence"`
-	AnonymousComment *string   `json:"anonymous_comment,omitempty" db:"anonymous_comment"`
-	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	ID               uuid.UUID  `json:"id" db:"id"`
+	DecisionID       uuid.UUID  `json:"decision_id" db:"decision_id"`
+	EvaluatorID      uuid.UUID  `json:"evaluator_id" db:"evaluator_id"`
+	OptionID         uuid.UUID  `json:"option_id" db:"option_id"`
+	CriteriaID       uuid.UUID  `json:"criteria_id" db:"criteria_id"`
+	Score            int        `json:"score" db:"score"`
+	Confidence       int        `json:"confidence" db:"confidence"`
+	AnonymousComment *string    `json:"anonymous_comment,omitempty" db:"anonymous_comment"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty" db:"updated_at"`
 }
 
 // DecisionOutcome represents the result and customer satisfaction
@@ -230,23 +330,58 @@ type DecisionOutcome struct {
 
 // AuthToken represents authentication tokens
 type AuthToken struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	UserID    uuid.UUID `json:"user_id" db:"user_id"`
-	TokenHash string    `json:"-" db:"token_hash"`
-	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	TokenHash  string     `json:"-" db:"token_hash"`
+	FamilyID   uuid.UUID  `json:"family_id" db:"family_id"`
+	ReplacedBy *uuid.UUID `json:"replaced_by,omitempty" db:"replaced_by"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
 }
 
-// AuditLog represents system audit trail
+// AuditLog represents one entry of the append-only audit trail.
+// Entries of a team form a hash chain: Hash covers the entry and PrevHash, the previous entry's hash.
 type AuditLog struct {
-	ID         uuid.UUID   `json:"id" db:"id"`
-	DecisionID *uuid.UUID  `json:"decision_id,omitempty" db:"decision_id"`
-	UserID     *uuid.UUID  `json:"user_id,omitempty" db:"user_id"`
-	Action     string      `json:"action" db:"action"`
-	Details    interface{} `json:"details,omitempty" db:"details"`
-	IPAddress  *string     `json:"ip_address,omitempty" db:"ip_address"`
-	UserAgent  *string     `json:"user_agent,omitempty" db:"user_agent"`
-	CreatedAt  time.Time   `json:"created_at" db:"created_at"`
+	ID         uuid.UUID    `json:"id" db:"id"`
+	TeamID     *uuid.UUID   `json:"team_id,omitempty" db:"team_id"`
+	Sequence   int64        `json:"sequence" db:"sequence"`
+	DecisionID *uuid.UUID   `json:"decision_id,omitempty" db:"decision_id"`
+	UserID     *uuid.UUID   `json:"user_id,omitempty" db:"user_id"`
+	Action     string       `json:"action" db:"action"`
+	Details    AuditDetails `json:"details,omitempty" db:"details"`
+	IPAddress  *string      `json:"ip_address,omitempty" db:"ip_address"`
+	UserAgent  *string      `json:"user_agent,omitempty" db:"user_agent"`
+	RequestID  *string      `json:"request_id,omitempty" db:"request_id"`
+	PrevHash   string       `json:"prev_hash" db:"prev_hash"`
+	Hash       string       `json:"hash" db:"hash"`
+	CreatedAt  time.Time    `json:"created_at" db:"created_at"`
+}
+
+// AuditDetails holds action-specific audit data stored as JSONB
+type AuditDetails map[string]interface{}
+
+// Value implements driver.Valuer interface
+func (d AuditDetails) Value() (driver.Value, error) {
+	if d == nil {
+		return nil, nil
+	}
+	return json.Marshal(d)
+}
+
+// Scan implements sql.Scanner interface
+func (d *AuditDetails) Scan(value interface{}) error {
+	if value == nil {
+		*d = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into AuditDetails", value)
+	}
+
+	return json.Unmarshal(bytes, d)
 }
 
 // Request/Response DTOs for API
@@ -269,10 +404,25 @@ type LoginRequest struct {
 
 // AuthResponse represents authentication response
 type AuthResponse struct {
-	Token     string     `json:"token"`
-	User      TeamMember `json:"user"`
-	Team      Team       `json:"team"`
-	ExpiresAt time.Time  `json:"expires_at"`
+	Token            string     `json:"token"`
+	RefreshToken     string     `json:"refresh_token,omitempty"`
+	User             TeamMember `json:"user"`
+	Team             Team       `json:"team"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	RefreshExpiresAt *time.Time `json:"refresh_expires_at,omitempty"`
+}
+
+// RefreshRequest represents a refresh token exchange request
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenResponse represents a rotated access/refresh token pair
+type TokenResponse struct {
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
 }
 
 // CreateDecisionRequest represents decision creation request
@@ -300,6 +450,42 @@ type CreateDecisionRequest struct {
 	ExpectedResolutionDate *time.Time `json:"expected_resolution_date,omitempty"`
 }
 
+// UpdateDecisionRequest represents a partial decision update; omitted fields are left unchanged.
+// UpdatedAt (or an If-Match ETag header) must match the stored version.
+type UpdateDecisionRequest struct {
+	CustomerName               *string  `json:"customer_name,omitempty"`
+	CustomerEmail              *string  `json:"customer_email,omitempty"`
+	CustomerTier               *string  `json:"customer_tier,omitempty"`
+	CustomerValue              *float64 `json:"customer_value,omitempty"`
+	RelationshipDurationMonths *int     `json:"relationship_duration_months,omitempty"`
+
+	CustomerTierDetailed *string    `json:"customer_tier_detailed,omitempty"`
+	UrgencyLevelDetailed *string    `json:"urgency_level_detailed,omitempty"`
+	CustomerImpactScope  *string    `json:"customer_impact_scope,omitempty"`
+	RelationshipHistory  *string    `json:"relationship_history,omitempty"`
+	PreviousIssuesCount  *int       `json:"previous_issues_count,omitempty"`
+	LastInteractionDate  *time.Time `json:"last_interaction_date,omitempty"`
+	NPSScore             *int       `json:"nps_score,omitempty"`
+
+	Title                  *string    `json:"title,omitempty"`
+	Description            *string    `json:"description,omitempty"`
+	DecisionType           *string    `json:"decision_type,omitempty"`
+	UrgencyLevel           *int       `json:"urgency_level,omitempty"`
+	FinancialImpact        *float64   `json:"financial_impact,omitempty"`
+	ExpectedResolutionDate *time.Time `json:"expected_resolution_date,omitempty"`
+
+	// How results are ranked and consensus measured (see the evaluation package)
+	AggregationMethod *string `json:"aggregation_method,omitempty"`
+	ConsensusMethod   *string `json:"consensus_method,omitempty"`
+
+	// Single or Delphi evaluation; fixed once the first Delphi round has closed
+	EvaluationMode           *string  `json:"evaluation_mode,omitempty"`
+	DelphiMaxRounds          *int     `json:"delphi_max_rounds,omitempty"`
+	DelphiConsensusThreshold *float64 `json:"delphi_consensus_threshold,omitempty"`
+
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
 // EvaluationRequest represents evaluation submission
 type EvaluationRequest struct {
 	Evaluations []EvaluationScore `json:"evaluations" validate:"required,dive"`
@@ -321,6 +507,22 @@ type EvaluationResults struct {
 	PendingFrom       []string      `json:"pending_from"`
 	RecommendedOption *uuid.UUID    `json:"recommended_option,omitempty"`
 	TeamConsensus     float64       `json:"team_consensus"`
+	AggregationMethod string        `json:"aggregation_method"` // how WeightedScore was computed
+	ConsensusMethod   string        `json:"consensus_method"`   // how TeamConsensus was computed
+
+	// Set when the decision weights evaluators by stakeholder role
+	StakeholderWeighting *RankingComparison `json:"stakeholder_weighting,omitempty"`
+
+	// Set once evaluation has closed and the results are frozen
+	Frozen      bool       `json:"frozen"`
+	ClosedAt    *time.Time `json:"closed_at,omitempty"`
+	CloseReason string     `json:"close_reason,omitempty"`
+	Deadline    *time.Time `json:"deadline,omitempty"`
+
+	// Set when the results are shown to the team; never stored with frozen results
+	Comments  []AnonymousComment `json:"comments,omitempty"`
+	Anonymity *AnonymityNotice   `json:"anonymity,omitempty"`
+	Delphi    *DelphiProgress    `json:"delphi,omitempty"`
 }
 
 type OptionScore struct {
@@ -331,58 +533,119 @@ type OptionScore struct {
 	Evaluators    int       `json:"evaluators"`
 	Consensus     float64   `json:"consensus"`
 	ConflictLevel string    `json:"conflict_level"`
+
+	// Evaluators, consensus and conflict level are zeroed while too few members have scored the option
+	DistributionWithheld bool `json:"distribution_withheld,omitempty"`
+}
+
+// AnonymousComment is an evaluator's comment without anything that says who wrote it or when
+type AnonymousComment struct {
+	OptionID   uuid.UUID `json:"option_id" db:"option_id"`
+	CriteriaID uuid.UUID `json:"criteria_id" db:"criteria_id"`
+	Comment    string    `json:"comment" db:"comment"`
+}
+
+// DelphiRound is a closed round of a Delphi evaluation
+type DelphiRound struct {
+	DecisionID      uuid.UUID `json:"-" db:"decision_id"`
+	RoundNumber     int       `json:"round" db:"round_number"`
+	Results         []byte    `json:"-" db:"results"`
+	Comments        []byte    `json:"-" db:"comments"`
+	TeamConsensus   float64   `json:"team_consensus" db:"team_consensus"`
+	ConsensusChange *float64  `json:"consensus_change,omitempty" db:"-"` // since the previous round
+	Evaluators      int       `json:"evaluators" db:"evaluators"`        // members who scored in this round
+	CloseReason     string    `json:"close_reason" db:"close_reason"`
+	StopReason      *string   `json:"stop_reason,omitempty" db:"stop_reason"` // set on the round that ended the evaluation
+	ClosedAt        time.Time `json:"closed_at" db:"closed_at"`
+}
+
+// DelphiProgress is where a Delphi evaluation stands and how consensus has moved from round to round
+type DelphiProgress struct {
+	Round              int           `json:"round"` // the round open now, or the last one once stopped
+	MaxRounds          int           `json:"max_rounds"`
+	ConsensusThreshold float64       `json:"consensus_threshold"`
+	FeedbackRound      int           `json:"feedback_round,omitempty"` // the closed round these results come from
+	Rounds             []DelphiRound `json:"rounds"`
+	Trend              string        `json:"trend,omitempty"` // converging, diverging or stable
+	StopReason         string        `json:"stop_reason,omitempty"`
+}
+
+// AnonymityNotice tells the team whether detail was held back to keep evaluators anonymous
+type AnonymityNotice struct {
+	MinEvaluators int  `json:"min_evaluators"`
+	Evaluators    int  `json:"evaluators"`
+	Withheld      bool `json:"withheld"`
+}
+
+// RankingComparison shows how stakeholder weighting changed the ranking of the options
+type RankingComparison struct {
+	Source                      string         `json:"source"`
+	Weighted                    []RankedOption `json:"weighted"`
+	Unweighted                  []RankedOption `json:"unweighted"`
+	UnweightedRecommendedOption *uuid.UUID     `json:"unweighted_recommended_option,omitempty"`
+	RecommendationChanged       bool           `json:"recommendation_changed"`
+}
+
+// RankedOption is an option's place in one ranking
+type RankedOption struct {
+	Rank        int       `json:"rank"`
+	OptionID    uuid.UUID `json:"option_id"`
+	OptionTitle string    `json:"option_title"`
+	Score       float64   `json:"score"`
+	RankChange  int       `json:"rank_change,omitempty"` // places gained by weighting (weighted ranking only)
 }
 
 // CustomerResponseType represents lookup table for response types
 type CustomerResponseType struct {
-	ID                         uuid.UUID `json:"id" db:"id"`
-	TypeCode                   string    `json:"type_code" db:"type_code"`
-	TypeName                   string    `json:"type_name" db:"type_name"`
-	Description                *string   `json:"description,omitempty" db:"description"`
-	TypicalResolutionTimeHours *int      `json:"typical_resolution_time_hours,omitempty" db:"typical_resolution_time_hours"`
-	RequiresEscalation         bool      `json:"requires_escalation" db:"requires_escalation"`
-	DefaultStakeholders        []string  `json:"default_stakeholders,omitempty" db:"default_stakeholders"`
-	AIClassificationKeywords   []string  `json:"ai_classification_keywords,omitempty" db:"ai_classification_keywords"`
-	CreatedAt                  time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt                  time.Time `json:"updated_at" db:"updated_at"`
+	ID                         uuid.UUID      `json:"id" db:"id"`
+	TypeCode                   string         `json:"type_code" db:"type_code"`
+	TypeName                   string         `json:"type_name" db:"type_name"`
+	Description                *string        `json:"description,omitempty" db:"description"`
+	TypicalResolutionTimeHours *int           `json:"typical_resolution_time_hours,omitempty" db:"typical_resolution_time_hours"`
+	RequiresEscalation         bool           `json:"requires_escalation" db:"requires_escalation"`
+	DefaultStakeholders        pq.StringArray `json:"default_stakeholders,omitempty" db:"default_stakeholders"`
+	AIClassificationKeywords   pq.StringArray `json:"ai_classification_keywords,omitempty" db:"ai_classification_keywords"`
+	CreatedAt                  time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt                  time.Time      `json:"updated_at" db:"updated_at"`
 }
 
 // ResponseDraft represents AI-generated customer response with versioning
 type ResponseDraft struct {
-	ID                          uuid.UUID  `json:"id" db:"id"`
-	DecisionID                  uuid.UUID  `json:"decision_id" db:"decision_id"`
-	DraftContent                string     `json:"draft_content" db:"draft_content"`
-	Tone                        string     `json:"tone" db:"tone"`
-	KeyPoints                   []string   `json:"key_points" db:"key_points"`
-	EstimatedSatisfactionImpact *string    `json:"estimated_satisfaction_impact,omitempty" db:"estimated_satisfaction_impact"`
-	FollowUpRecommendations     []string   `json:"follow_up_recommendations" db:"follow_up_recommendations"`
-	Version                     int        `json:"version" db:"version"`
-	CreatedBy                   uuid.UUID  `json:"created_by" db:"created_by"`
-	CreatedAt                   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt                   time.Time  `json:"updated_at" db:"updated_at"`
-	GenerationMetadata          *string    `json:"generation_metadata,omitempty" db:"generation_metadata"`
-	BasedOnOptionID             *uuid.UUID `json:"based_on_option_id,omitempty" db:"based_on_option_id"`
-	TeamConsensusScore          *float64   `json:"team_consensus_score,omitempty" db:"team_consensus_score"`
+	ID                          uuid.UUID      `json:"id" db:"id"`
+	DecisionID                  uuid.UUID      `json:"decision_id" db:"decision_id"`
+	DraftContent                string         `json:"draft_content" db:"draft_content"`
+	Tone                        string         `json:"tone" db:"tone"`
+	KeyPoints                   pq.StringArray `json:"key_points" db:"key_points"`
+	EstimatedSatisfactionImpact *string        `json:"estimated_satisfaction_impact,omitempty" db:"estimated_satisfaction_impact"`
+	FollowUpRecommendations     pq.StringArray `json:"follow_up_recommendations" db:"follow_up_recommendations"`
+	Version                     int            `json:"version" db:"version"`
+	CreatedBy                   uuid.UUID      `json:"created_by" db:"created_by"`
+	CreatedAt                   time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt                   time.Time      `json:"updated_at" db:"updated_at"`
+	GenerationMetadata          *string        `json:"generation_metadata,omitempty" db:"generation_metadata"`
+	BasedOnOptionID             *uuid.UUID     `json:"based_on_option_id,omitempty" db:"based_on_option_id"`
+	TeamConsensusScore          *float64       `json:"team_consensus_score,omitempty" db:"team_consensus_score"`
 }
 
 // GenerateResponseDraftRequest represents request to generate customer response draft
+// The form tags read the same fields from the query string of a streaming GET request.
 type GenerateResponseDraftRequest struct {
 	DecisionOutcome          DecisionOutcomeInput          `json:"decision_outcome" validate:"required"`
 	CommunicationPreferences CommunicationPreferencesInput `json:"communication_preferences" validate:"required"`
-	RegenerateFromVersion    *int                          `json:"regenerate_from_version,omitempty"`
+	RegenerateFromVersion    *int                          `json:"regenerate_from_version,omitempty" form:"regenerate_from_version"`
 }
 
 // DecisionOutcomeInput represents the team's decision
 type DecisionOutcomeInput struct {
-	SelectedOptionID string `json:"selected_option_id" validate:"required"`
-	Reasoning        string `json:"reasoning" validate:"required"`
+	SelectedOptionID string `json:"selected_option_id" form:"selected_option_id" binding:"required" validate:"required"`
+	Reasoning        string `json:"reasoning" form:"reasoning" binding:"required" validate:"required"`
 }
 
 // CommunicationPreferencesInput specifies tone and channel
 type CommunicationPreferencesInput struct {
-	Tone    string `json:"tone" validate:"required,oneof=professional_empathetic formal_corporate friendly_apologetic concise_factual"`
-	Channel string `json:"channel" validate:"required,oneof=email phone chat meeting"`
-	Urgency string `json:"urgency" validate:"required,oneof=same_day next_day weekly"`
+	Tone    string `json:"tone" form:"tone,default=professional_empathetic" binding:"required,oneof=professional_empathetic formal_corporate friendly_apologetic concise_factual" validate:"required,oneof=professional_empathetic formal_corporate friendly_apologetic concise_factual"`
+	Channel string `json:"channel" form:"channel,default=email" binding:"required,oneof=email phone chat meeting" validate:"required,oneof=email phone chat meeting"`
+	Urgency string `json:"urgency" form:"urgency,default=same_day" binding:"required,oneof=same_day next_day weekly" validate:"required,oneof=same_day next_day weekly"`
 }
 
 // OutcomeTracking represents comprehensive outcome tracking
@@ -0,0 +1,314 @@
+// Package realtime pushes team events to connected WebSocket clients.
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// Event types pushed to clients
+const (
+	EventConnected             = "connected"
+	EventEvaluationSubmitted   = "evaluation.submitted"
+	EventParticipationChanged  = "evaluation.participation_changed"
+	EventEvaluationClosed      = "evaluation.closed"
+	EventDelphiRoundClosed     = "evaluation.delphi_round_closed"
+	EventDraftGenerated        = "draft.generated"
+	EventOutcomeRecorded       = "outcome.recorded"
+	EventDecisionStatusChanged = "decision.status_changed"
+)
+
+// Hub defaults used when the config leaves a value unset
+const (
+	defaultHeartbeatInterval     = 30 * time.Second
+	defaultMaxConnectionsPerUser = 5
+	defaultSendBuffer            = 64
+	defaultWriteTimeout          = 10 * time.Second
+	maxInboundMessageSize        = 512
+)
+
+var (
+	// ErrTooManyConnections is returned when the hub is at its connection limit
+	ErrTooManyConnections = errors.New("too many websocket connections")
+	// ErrTooManyUserConnections is returned when a user already has the maximum number of connections open
+	ErrTooManyUserConnections = errors.New("too many websocket connections for this user")
+)
+
+// Event is a message pushed to every connection of a team
+type Event struct {
+	Type       string      `json:"type"`
+	DecisionID *uuid.UUID  `json:"decision_id,omitempty"`
+	Data       interface{} `json:"data,omitempty"`
+	Timestamp  time.Time   `json:"timestamp"`
+}
+
+// Config controls connection limits, heartbeats and backpressure
+type Config struct {
+	MaxConnections        int           // across all teams; 0 means unlimited
+	MaxConnectionsPerUser int           // browser tabs per user
+	HeartbeatInterval     time.Duration // ping interval; a client missing two pings is disconnected
+	SendBuffer            int           // queued messages per client before it is dropped as a slow consumer
+	WriteTimeout          time.Duration
+}
+
+// Stats is a snapshot of hub activity
+type Stats struct {
+	Connections int   `json:"connections"`
+	Teams       int   `json:"teams"`
+	Dropped     int64 `json:"dropped_slow_consumers"`
+}
+
+// Conn is the part of *websocket.Conn the hub uses
+type Conn interface {
+	WriteMessage(messageType int, data []byte) error
+	WriteControl(messageType int, data []byte, deadline time.Time) error
+	ReadMessage() (int, []byte, error)
+	SetReadLimit(limit int64)
+	SetReadDeadline(t time.Time) error
+	SetWriteDeadline(t time.Time) error
+	SetPongHandler(h func(appData string) error)
+	Close() error
+}
+
+// Hub is a per-team publish/subscribe fan-out for WebSocket clients
+type Hub struct {
+	cfg Config
+
+	mu      sync.RWMutex
+	teams   map[uuid.UUID]map[*Client]struct{}
+	perUser map[uuid.UUID]int
+	total   int
+
+	dropped atomic.Int64
+}
+
+// Client is one WebSocket connection subscribed to its team's events
+type Client struct {
+	hub    *Hub
+	teamID uuid.UUID
+	userID uuid.UUID
+	conn   Conn
+	send   chan []byte
+
+	done        chan struct{}
+	closeOnce   sync.Once
+	closeCode   int
+	closeReason string
+}
+
+// NewHub creates a hub, filling unset config values with defaults
+func NewHub(cfg Config) *Hub {
+	if cfg.HeartbeatInterval <= 0 {
+		cfg.HeartbeatInterval = defaultHeartbeatInterval
+	}
+	if cfg.MaxConnectionsPerUser <= 0 {
+		cfg.MaxConnectionsPerUser = defaultMaxConnectionsPerUser
+	}
+	if cfg.SendBuffer <= 0 {
+		cfg.SendBuffer = defaultSendBuffer
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = defaultWriteTimeout
+	}
+
+	return &Hub{
+		cfg:     cfg,
+		teams:   make(map[uuid.UUID]map[*Client]struct{}),
+		perUser: make(map[uuid.UUID]int),
+	}
+}
+
+// CanAccept reports whether a new connection for the user would be within the limits
+func (h *Hub) CanAccept(userID uuid.UUID) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.checkLimits(userID)
+}
+
+func (h *Hub) checkLimits(userID uuid.UUID) error {
+	if h.cfg.MaxConnections > 0 && h.total >= h.cfg.MaxConnections {
+		return ErrTooManyConnections
+	}
+	if h.perUser[userID] >= h.cfg.MaxConnectionsPerUser {
+		return ErrTooManyUserConnections
+	}
+	return nil
+}
+
+// Register subscribes a connection to its team's events
+func (h *Hub) Register(teamID, userID uuid.UUID, conn Conn) (*Client, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.checkLimits(userID); err != nil {
+		return nil, err
+	}
+
+	client := &Client{
+		hub:    h,
+		teamID: teamID,
+		userID: userID,
+		conn:   conn,
+		send:   make(chan []byte, h.cfg.SendBuffer),
+		done:   make(chan struct{}),
+	}
+
+	if h.teams[teamID] == nil {
+		h.teams[teamID] = make(map[*Client]struct{})
+	}
+	h.teams[teamID][client] = struct{}{}
+	h.perUser[userID]++
+	h.total++
+
+	return client, nil
+}
+
+func (h *Hub) unregister(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	clients := h.teams[client.teamID]
+	if _, ok := clients[client]; !ok {
+		return
+	}
+	delete(clients, client)
+	if len(clients) == 0 {
+		delete(h.teams, client.teamID)
+	}
+	h.perUser[client.userID]--
+	if h.perUser[client.userID] <= 0 {
+		delete(h.perUser, client.userID)
+	}
+	h.total--
+}
+
+// Publish sends an event to every connection of the team without blocking.
+// A client whose send buffer is full is disconnected rather than slowing everyone else down.
+// A nil hub ignores events, so handlers can publish unconditionally.
+func (h *Hub) Publish(teamID uuid.UUID, event Event) {
+	if h == nil {
+		return
+	}
+	message, ok := encodeEvent(event)
+	if !ok {
+		return
+	}
+
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.teams[teamID]))
+	for client := range h.teams[teamID] {
+		clients = append(clients, client)
+	}
+	h.mu.RUnlock()
+
+	for _, client := range clients {
+		client.enqueue(message)
+	}
+}
+
+// Send queues an event for this client only
+func (c *Client) Send(event Event) {
+	if message, ok := encodeEvent(event); ok {
+		c.enqueue(message)
+	}
+}
+
+func (c *Client) enqueue(message []byte) {
+	select {
+	case c.send <- message:
+	default:
+		c.hub.dropped.Add(1)
+		c.close(websocket.CloseTryAgainLater, "slow consumer")
+	}
+}
+
+func encodeEvent(event Event) ([]byte, bool) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	message, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to encode realtime event %s: %v", event.Type, err)
+		return nil, false
+	}
+	return message, true
+}
+
+// Stats returns the current connection counts
+func (h *Hub) Stats() Stats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return Stats{Connections: h.total, Teams: len(h.teams), Dropped: h.dropped.Load()}
+}
+
+// Serve pumps messages to the client until it disconnects, misses heartbeats or is dropped.
+// It blocks, so the HTTP handler that upgraded the connection should call it last.
+func (c *Client) Serve() {
+	go c.writePump()
+	c.readPump()
+}
+
+// readPump discards client messages; reading is still required to process pongs and detect disconnects
+func (c *Client) readPump() {
+	defer c.close(websocket.CloseNormalClosure, "")
+
+	// A client is considered gone once it misses two heartbeats
+	timeout := 2 * c.hub.cfg.HeartbeatInterval
+	c.conn.SetReadLimit(maxInboundMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(timeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(timeout))
+	})
+
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func (c *Client) writePump() {
+	ticker := time.NewTicker(c.hub.cfg.HeartbeatInterval)
+	defer func() {
+		ticker.Stop()
+		_ = c.conn.Close()
+	}()
+
+	for {
+		select {
+		case <-c.done:
+			_ = c.conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(c.closeCode, c.closeReason),
+				time.Now().Add(c.hub.cfg.WriteTimeout))
+			return
+		case message := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(c.hub.cfg.WriteTimeout))
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.hub.cfg.WriteTimeout)); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		}
+	}
+}
+
+// close unsubscribes the client and tells the write pump to send a close frame and hang up
+func (c *Client) close(code int, reason string) {
+	c.closeOnce.Do(func() {
+		c.closeCode = code
+		c.closeReason = reason
+		c.hub.unregister(c)
+		close(c.done)
+	})
+}
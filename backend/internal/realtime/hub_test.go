@@ -0,0 +1,142 @@
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingConn never completes a write, like a client that stopped reading
+type blockingConn struct {
+	release chan struct{}
+}
+
+func (c *blockingConn) WriteMessage(int, []byte) error {
+	<-c.release
+	return errors.New("closed")
+}
+func (c *blockingConn) WriteControl(int, []byte, time.Time) error { return nil }
+func (c *blockingConn) ReadMessage() (int, []byte, error) {
+	<-c.release
+	return 0, nil, errors.New("closed")
+}
+func (c *blockingConn) SetReadLimit(int64)                        {}
+func (c *blockingConn) SetReadDeadline(time.Time) error           { return nil }
+func (c *blockingConn) SetWriteDeadline(time.Time) error          { return nil }
+func (c *blockingConn) SetPongHandler(func(appData string) error) {}
+func (c *blockingConn) Close() error                              { return nil }
+
+func dialHub(t *testing.T, hub *Hub, teamID uuid.UUID) *websocket.Conn {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		client, err := hub.Register(teamID, uuid.New(), conn)
+		require.NoError(t, err)
+		client.Serve()
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	require.Eventually(t, func() bool { return hub.Stats().Connections > 0 }, time.Second, 5*time.Millisecond)
+	return conn
+}
+
+func TestPublishReachesOnlyTheTeam(t *testing.T) {
+	hub := NewHub(Config{HeartbeatInterval: time.Minute})
+	teamID := uuid.New()
+	conn := dialHub(t, hub, teamID)
+
+	decisionID := uuid.New()
+	hub.Publish(uuid.New(), Event{Type: EventOutcomeRecorded})
+	hub.Publish(teamID, Event{Type: EventEvaluationSubmitted, DecisionID: &decisionID})
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	_, message, err := conn.ReadMessage()
+	require.NoError(t, err)
+
+	var event Event
+	require.NoError(t, json.Unmarshal(message, &event))
+	assert.Equal(t, EventEvaluationSubmitted, event.Type, "other teams' events are not delivered")
+	assert.Equal(t, decisionID, *event.DecisionID)
+}
+
+func TestHeartbeatPingsClient(t *testing.T) {
+	hub := NewHub(Config{HeartbeatInterval: 20 * time.Millisecond})
+	conn := dialHub(t, hub, uuid.New())
+
+	pinged := make(chan struct{}, 1)
+	conn.SetPingHandler(func(string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-pinged:
+	case <-time.After(time.Second):
+		t.Fatal("no heartbeat ping received")
+	}
+}
+
+func TestSlowConsumerIsDropped(t *testing.T) {
+	hub := NewHub(Config{HeartbeatInterval: time.Minute, SendBuffer: 2})
+	teamID := uuid.New()
+	conn := &blockingConn{release: make(chan struct{})}
+	defer close(conn.release)
+
+	client, err := hub.Register(teamID, uuid.New(), conn)
+	require.NoError(t, err)
+	go client.Serve()
+
+	// One message is stuck in the write, two fill the buffer, the next overflows it
+	for i := 0; i < 5; i++ {
+		hub.Publish(teamID, Event{Type: EventDecisionStatusChanged})
+	}
+
+	assert.Eventually(t, func() bool { return hub.Stats().Connections == 0 }, time.Second, 5*time.Millisecond)
+	assert.Positive(t, hub.Stats().Dropped)
+}
+
+func TestConnectionLimits(t *testing.T) {
+	hub := NewHub(Config{MaxConnections: 3, MaxConnectionsPerUser: 2})
+	userID := uuid.New()
+	teamID := uuid.New()
+
+	for i := 0; i < 2; i++ {
+		_, err := hub.Register(teamID, userID, &blockingConn{})
+		require.NoError(t, err)
+	}
+	_, err := hub.Register(teamID, userID, &blockingConn{})
+	assert.ErrorIs(t, err, ErrTooManyUserConnections)
+
+	_, err = hub.Register(teamID, uuid.New(), &blockingConn{})
+	require.NoError(t, err)
+	assert.ErrorIs(t, hub.CanAccept(uuid.New()), ErrTooManyConnections)
+}
+
+func TestNilHubIgnoresEvents(t *testing.T) {
+	var hub *Hub
+	assert.NotPanics(t, func() { hub.Publish(uuid.New(), Event{Type: EventDraftGenerated}) })
+}
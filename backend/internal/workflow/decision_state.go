@@ -0,0 +1,178 @@
+// Package workflow defines the lifecycle of a customer decision and the rules for moving through it.
+package workflow
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// DecisionState is a step in the customer decision lifecycle
+type DecisionState string
+
+// Decision lifecycle states, in workflow order
+const (
+	StateCreated    DecisionState = "created"
+	StateCriteria   DecisionState = "criteria"
+	StateOptions    DecisionState = "options"
+	StateEvaluation DecisionState = "evaluation"
+	StateDrafting   DecisionState = "drafting"
+	StateResolved   DecisionState = "resolved"
+	StateCancelled  DecisionState = "cancelled"
+)
+
+// Decision status values stored in customer_decisions.status
+const (
+	StatusCreated    = "created"
+	StatusTeamInput  = "team_input"
+	StatusEvaluating = "evaluating"
+	StatusResolved   = "resolved"
+	StatusCancelled  = "cancelled"
+)
+
+var (
+	// ErrUnknownState is returned for state names outside the lifecycle
+	ErrUnknownState = errors.New("unknown decision state")
+	// ErrTransitionNotAllowed is returned when the lifecycle has no edge between two states
+	ErrTransitionNotAllowed = errors.New("transition not allowed")
+)
+
+// GuardError explains why an otherwise allowed transition cannot happen yet
+type GuardError struct {
+	To     DecisionState
+	Reason string
+}
+
+func (e *GuardError) Error() string {
+	return fmt.Sprintf("cannot move to %s: %s", e.To, e.Reason)
+}
+
+// stateDetails maps each state onto the stored status and current_phase (1-6)
+func stateDetails() map[DecisionState]struct {
+	status string
+	phase  int
+} {
+	return map[DecisionState]struct {
+		status string
+		phase  int
+	}{
+		StateCreated:    {StatusCreated, 1},
+		StateCriteria:   {StatusTeamInput, 2},
+		StateOptions:    {StatusTeamInput, 3},
+		StateEvaluation: {StatusEvaluating, 4},
+		StateDrafting:   {StatusEvaluating, 5},
+		StateResolved:   {StatusResolved, 6},
+		StateCancelled:  {StatusCancelled, 0}, // phase is left where the decision stopped
+	}
+}
+
+// Transitions returns the allowed next states for every state
+func Transitions() map[DecisionState][]DecisionState {
+	return map[DecisionState][]DecisionState{
+		StateCreated:    {StateCriteria, StateCancelled},
+		StateCriteria:   {StateOptions, StateCancelled},
+		StateOptions:    {StateEvaluation, StateCriteria, StateCancelled},
+		StateEvaluation: {StateDrafting, StateOptions, StateCancelled},
+		StateDrafting:   {StateResolved, StateEvaluation, StateCancelled},
+		StateResolved:   {},
+		StateCancelled:  {},
+	}
+}
+
+// ParseState validates a state name
+func ParseState(name string) (DecisionState, error) {
+	state := DecisionState(name)
+	if _, ok := stateDetails()[state]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownState, name)
+	}
+	return state, nil
+}
+
+// StateOf derives the lifecycle state from a decision's stored status and phase
+func StateOf(status string, phase int) DecisionState {
+	switch status {
+	case StatusCancelled:
+		return StateCancelled
+	case StatusResolved:
+		return StateResolved
+	}
+
+	switch phase {
+	case 2:
+		return StateCriteria
+	case 3:
+		return StateOptions
+	case 4:
+		return StateEvaluation
+	case 5, 6:
+		return StateDrafting
+	default:
+		return StateCreated
+	}
+}
+
+// StatusAndPhase returns what to store for a state; a zero phase means keep the current phase
+func StatusAndPhase(state DecisionState) (string, int) {
+	details := stateDetails()[state]
+	return details.status, details.phase
+}
+
+// AllowedTransitions lists the states reachable from the given state
+func AllowedTransitions(from DecisionState) []DecisionState {
+	return Transitions()[from]
+}
+
+// CanTransition reports whether the lifecycle has an edge from one state to another
+func CanTransition(from, to DecisionState) error {
+	for _, next := range Transitions()[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s -> %s", ErrTransitionNotAllowed, from, to)
+}
+
+// GuardFacts is the decision data the transition guards look at
+type GuardFacts struct {
+	CriteriaCount    int        `db:"criteria_count"`
+	OptionCount      int        `db:"option_count"`
+	EvaluationCount  int        `db:"evaluation_count"`
+	DraftCount       int        `db:"draft_count"`
+	SelectedOptionID *uuid.UUID `db:"-"`
+}
+
+// CheckGuards verifies the decision is ready to enter the target state
+func CheckGuards(from, to DecisionState, facts GuardFacts) error {
+	switch to {
+	case StateOptions:
+		if facts.CriteriaCount == 0 {
+			return &GuardError{To: to, Reason: "at least one criterion is required"}
+		}
+	case StateEvaluation:
+		if from == StateOptions && facts.OptionCount < 2 {
+			return &GuardError{To: to, Reason: "at least two response options are required"}
+		}
+		if from == StateDrafting && facts.DraftCount > 0 {
+			return &GuardError{To: to, Reason: "response drafts already exist for the selected option"}
+		}
+	case StateDrafting:
+		if facts.EvaluationCount == 0 {
+			return &GuardError{To: to, Reason: "no evaluations have been submitted"}
+		}
+		if facts.SelectedOptionID == nil {
+			return &GuardError{To: to, Reason: "an option must be selected"}
+		}
+	case StateResolved:
+		if facts.SelectedOptionID == nil {
+			return &GuardError{To: to, Reason: "an option must be selected"}
+		}
+	}
+
+	// Moving back from evaluation would invalidate scores against the option set
+	if from == StateEvaluation && to == StateOptions && facts.EvaluationCount > 0 {
+		return &GuardError{To: to, Reason: "evaluations have already been submitted"}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package workflow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateRoundTripsThroughStatusAndPhase(t *testing.T) {
+	for _, state := range []DecisionState{StateCreated, StateCriteria, StateOptions, StateEvaluation, StateDrafting, StateResolved} {
+		status, phase := StatusAndPhase(state)
+		assert.Equal(t, state, StateOf(status, phase), state)
+	}
+
+	status, _ := StatusAndPhase(StateCancelled)
+	assert.Equal(t, StateCancelled, StateOf(status, 4))
+}
+
+func TestCanTransition(t *testing.T) {
+	assert.NoError(t, CanTransition(StateCreated, StateCriteria))
+	assert.NoError(t, CanTransition(StateEvaluation, StateCancelled))
+
+	err := CanTransition(StateCreated, StateResolved)
+	assert.True(t, errors.Is(err, ErrTransitionNotAllowed))
+	assert.Error(t, CanTransition(StateResolved, StateDrafting), "resolved is terminal")
+	assert.Error(t, CanTransition(StateCancelled, StateCreated), "cancelled is terminal")
+}
+
+func TestCheckGuards(t *testing.T) {
+	optionID := uuid.New()
+
+	assert.Error(t, CheckGuards(StateCriteria, StateOptions, GuardFacts{}))
+	assert.NoError(t, CheckGuards(StateCriteria, StateOptions, GuardFacts{CriteriaCount: 1}))
+
+	assert.Error(t, CheckGuards(StateOptions, StateEvaluation, GuardFacts{OptionCount: 1}))
+	assert.NoError(t, CheckGuards(StateOptions, StateEvaluation, GuardFacts{OptionCount: 3}))
+
+	var guardErr *GuardError
+	err := CheckGuards(StateEvaluation, StateDrafting, GuardFacts{SelectedOptionID: &optionID})
+	assert.True(t, errors.As(err, &guardErr), "no drafts before evaluations exist")
+	assert.Error(t, CheckGuards(StateEvaluation, StateDrafting, GuardFacts{EvaluationCount: 4}))
+	assert.NoError(t, CheckGuards(StateEvaluation, StateDrafting, GuardFacts{EvaluationCount: 4, SelectedOptionID: &optionID}))
+
+	assert.Error(t, CheckGuards(StateDrafting, StateResolved, GuardFacts{}), "no outcome before an option is selected")
+	assert.Error(t, CheckGuards(StateEvaluation, StateOptions, GuardFacts{EvaluationCount: 1}))
+}
+
+func TestParseState(t *testing.T) {
+	state, err := ParseState("drafting")
+	assert.NoError(t, err)
+	assert.Equal(t, StateDrafting, state)
+
+	_, err = ParseState("archived")
+	assert.True(t, errors.Is(err, ErrUnknownState))
+}
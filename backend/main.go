@@ -53,12 +53,15 @@ func main() {
 	} else {
 		log.Printf("Database: Running without database connection")
 	}
-	log.Printf("AI Integration: DeepSeek API %s", func() string {
-		if cfg.DeepSeekAPIKey != "" {
-			return "enabled"
+	log.Printf("AI Integration: %s provider %s", cfg.AIProvider, func() string {
+		if cfg.AIProvider == "keyword" {
+			return "enabled (offline, no API key required)"
 		}
-		return "disabled"
+		return "enabled"
 	}())
+	if len(cfg.AIFallbackProviders) > 0 {
+		log.Printf("AI Integration: fallback providers %v", cfg.AIFallbackProviders)
+	}
 
 	// Start server
 	if err := router.Run(":" + port); err != nil {